@@ -0,0 +1,577 @@
+package dhcpv6
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// Anonymizer consistently pseudonymises identifying data in DHCPv6 messages,
+// so captures can be shared without revealing who was in them. Every value is
+// replaced by a keyed hash of it, so the same value maps to the same
+// pseudonym for the same key, while the original can't be recovered without
+// the key. An Anonymizer is not safe for concurrent use
+type Anonymizer struct {
+	key []byte
+	// pseudonymised addresses, since every address takes a hash per bit
+	addrs map[netip.Addr]netip.Addr
+}
+
+// labels keeping the hashes of different kinds of data apart
+const (
+	anonLabelAddr          = "addr"
+	anonLabelAddr4         = "addr4"
+	anonLabelHardwareAddr  = "hwaddr"
+	anonLabelDUID          = "duid"
+	anonLabelRemoteID      = "remote-id"
+	anonLabelInterfaceID   = "interface-id"
+	anonLabelDomainName    = "domain-name"
+	anonLabelClientID      = "client-id"
+	anonRelayHeaderLen     = 34
+	anonLinkLocalKeepBits  = 64
+	anonLinkLocal4KeepBits = 16
+	anonDomainLabelHashLen = 4
+)
+
+// the IPv4 limited broadcast address, which identifies nobody
+var anonIPv4Broadcast = netip.AddrFrom4([4]byte{255, 255, 255, 255})
+
+// NewAnonymizer returns an Anonymizer pseudonymising with given key. Captures
+// anonymized with the same key can be correlated, so use a fresh random key
+// unless that is desired
+func NewAnonymizer(key []byte) *Anonymizer {
+	return &Anonymizer{
+		key:   append([]byte(nil), key...),
+		addrs: make(map[netip.Addr]netip.Addr),
+	}
+}
+
+// Message returns a copy of given Message with DUIDs, link-layer addresses,
+// IPv6 addresses and prefixes, Remote-IDs and domain names in any of its
+// options, including nested ones, relay messages in Leasequery relay data and
+// DHCPv4 messages, replaced by their pseudonyms. Options of types this package
+// doesn't decode are left out, since they might carry identifying data, and so
+// are the DHCPv4 options not known to be harmless. Options that carry
+// identifying data but can't be decoded return an error
+func (a *Anonymizer) Message(m *Message) (*Message, error) {
+	c := m.Clone()
+	var err error
+	if c.Options, err = a.options(c.Options); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Bytes decodes given marshalled message, anonymizes it like Message does and
+// returns it marshalled again. Relay-forward and Relay-reply messages are
+// followed down the relay chain, pseudonymising the link and peer addresses
+// and Interface-ID of every relay agent. Options this package can't decode are
+// left out, since they might carry identifying data
+func (a *Anonymizer) Bytes(data []byte) ([]byte, error) {
+	if len(data) > 0 {
+		switch MessageType(data[0]) {
+		case MessageTypeRelayForward, MessageTypeRelayReply:
+			return a.relayMessage(data)
+		}
+	}
+
+	m, err := DecodeMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	if m, err = a.Message(m); err != nil {
+		return nil, err
+	}
+
+	return m.Marshal()
+}
+
+// Addr returns the pseudonym of given address. The mapping preserves prefixes:
+// addresses sharing their first n bits have pseudonyms sharing their first n
+// bits too. IPv4 addresses map to IPv4 addresses, independent of the mapping
+// of IPv6 addresses. Multicast, loopback, unspecified and IPv4-mapped
+// addresses and the IPv4 limited broadcast address are returned as is and
+// link-local addresses keep their prefix
+func (a *Anonymizer) Addr(addr netip.Addr) netip.Addr {
+	if !addr.IsValid() || addr.Is4In6() || addr.IsMulticast() || addr.IsLoopback() || addr.IsUnspecified() || addr == anonIPv4Broadcast {
+		return addr
+	}
+	if p, ok := a.addrs[addr]; ok {
+		return p
+	}
+
+	label, keep := anonLabelAddr, 0
+	if addr.Is4() {
+		label = anonLabelAddr4
+	}
+	if addr.IsLinkLocalUnicast() {
+		keep = anonLinkLocalKeepBits
+		if addr.Is4() {
+			keep = anonLinkLocal4KeepBits
+		}
+	}
+
+	// flip every bit depending on a hash of the bits before it, like
+	// Crypto-PAn does
+	in := addr.AsSlice()
+	out := addr.AsSlice()
+	for i := keep; i < addr.BitLen(); i++ {
+		var prefix [17]byte
+		copy(prefix[:], in[:i/8])
+		if i%8 != 0 {
+			prefix[i/8] = in[i/8] & (0xff << (8 - i%8))
+		}
+		prefix[16] = uint8(i)
+		if a.hash(label, prefix[:], 1)[0]&0x80 != 0 {
+			out[i/8] ^= 0x80 >> (i % 8)
+		}
+	}
+
+	p, _ := netip.AddrFromSlice(out)
+	p = p.WithZone(addr.Zone())
+	a.addrs[addr] = p
+
+	return p
+}
+
+// HardwareAddr returns the pseudonym of given link-layer address, which has
+// the same length. Pseudonyms of 6 byte and 8 byte addresses are locally
+// administered unicast addresses
+func (a *Anonymizer) HardwareAddr(addr net.HardwareAddr) net.HardwareAddr {
+	if len(addr) == 0 {
+		return addr
+	}
+
+	p := net.HardwareAddr(a.hash(anonLabelHardwareAddr, addr, len(addr)))
+	if len(p) == 6 || len(p) == 8 {
+		p[0] = p[0]&^0x01 | 0x02
+	}
+
+	return p
+}
+
+// DUID returns a copy of given DUID with its identifying parts replaced by
+// their pseudonyms, keeping its type, hardware type, time and enterprise
+// number
+func (a *Anonymizer) DUID(d DUID) DUID {
+	if d == nil {
+		return nil
+	}
+
+	c := CloneDUID(d)
+	switch o := c.(type) {
+	case *DUIDLLT:
+		o.LinkLayerAddress = a.HardwareAddr(o.LinkLayerAddress)
+	case *DUIDLL:
+		o.LinkLayerAddress = a.HardwareAddr(o.LinkLayerAddress)
+	case *DUIDEN:
+		o.ID = a.hash(anonLabelDUID, o.ID, len(o.ID))
+	case *DUIDUUID:
+		copy(o.UUID[:], a.hash(anonLabelDUID, o.UUID[:], len(o.UUID)))
+		// keep it a valid random UUID
+		o.UUID[6] = o.UUID[6]&0x0f | 0x40
+		o.UUID[8] = o.UUID[8]&0x3f | 0x80
+	case *DUIDOpaque:
+		// keep the DUID type
+		if len(o.Data) > 2 {
+			copy(o.Data[2:], a.hash(anonLabelDUID, o.Data, len(o.Data)-2))
+		}
+	}
+
+	return c
+}
+
+// DomainName returns the pseudonym of given domain name. Every label but the
+// top-level domain is replaced by a hash of it and the labels following it,
+// so names in the same domain keep sharing that domain. Single label host
+// names are replaced entirely
+func (a *Anonymizer) DomainName(name string) string {
+	trimmed := strings.TrimSuffix(name, ".")
+	if trimmed == "" {
+		return name
+	}
+
+	labels := strings.Split(trimmed, ".")
+	last := len(labels) - 2
+	if len(labels) == 1 {
+		last = 0
+	}
+	for i := last; i >= 0; i-- {
+		suffix := strings.ToLower(strings.Join(labels[i:], "."))
+		labels[i] = "x" + hex.EncodeToString(a.hash(anonLabelDomainName, []byte(suffix), anonDomainLabelHashLen))
+	}
+
+	p := strings.Join(labels, ".")
+	if len(trimmed) < len(name) {
+		p += "."
+	}
+
+	return p
+}
+
+// options containing options that can be replaced
+type nestedOptions interface {
+	Options() Options
+	setOptions(Options)
+}
+
+// helper function to anonymize given options and their nested options in
+// place, returning them without the options of unknown types
+func (a *Anonymizer) options(opts Options) (Options, error) {
+	var kept Options
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case *OptionUnknown:
+			continue
+		case *OptionClientID:
+			o.DUID = a.DUID(o.DUID)
+		case *OptionServerID:
+			o.DUID = a.DUID(o.DUID)
+		case *OptionRelayID:
+			o.DUID = a.DUID(o.DUID)
+		case *OptionIAAddress:
+			o.Address = a.ip(o.Address)
+		case *OptionIAPrefix:
+			if err := a.iaPrefix(o); err != nil {
+				return nil, err
+			}
+		case *OptionRemoteID:
+			o.RemoteID = a.hash(anonLabelRemoteID, o.RemoteID, len(o.RemoteID))
+		case *OptionLQQuery:
+			o.LinkAddress = a.ip(o.LinkAddress)
+		case *OptionLQRelayData:
+			o.PeerAddress = a.ip(o.PeerAddress)
+			if len(o.RelayMessage) > 0 {
+				b, err := a.Bytes(o.RelayMessage)
+				if err != nil {
+					return nil, err
+				}
+				o.RelayMessage = b
+			}
+		case *OptionLQClientLink:
+			for i, addr := range o.LinkAddresses {
+				o.LinkAddresses[i] = a.ip(addr)
+			}
+		case *OptionDNSServer:
+			for i, addr := range o.Servers {
+				o.Servers[i] = a.ip(addr)
+			}
+		case *OptionDNSSearchList:
+			for i, name := range o.DomainNames {
+				o.DomainNames[i] = a.DomainName(name)
+			}
+		case *OptionClientFQDN:
+			o.DomainName = a.DomainName(o.DomainName)
+		case *OptionBootFileURL:
+			o.URL = a.url(o.URL)
+		case *OptionDHCPv4Message:
+			b, err := a.dhcpv4Message(o.Message)
+			if err != nil {
+				return nil, err
+			}
+			o.Message = b
+		case *OptionDHCP4oDHCP6Server:
+			for i, addr := range o.Servers {
+				o.Servers[i] = a.ip(addr)
+			}
+		case *OptionNextHop:
+			o.Address = a.ip(o.Address)
+		case *OptionRoutePrefix:
+			o.Prefix = a.prefix(o.Prefix, o.PrefixLength)
+		}
+
+		if c, ok := opt.(nestedOptions); ok {
+			nested, err := a.options(c.Options())
+			if err != nil {
+				return nil, err
+			}
+			c.setOptions(nested)
+		}
+		kept = append(kept, opt)
+	}
+
+	return kept, nil
+}
+
+// helper function to anonymize an IA Prefix option in place, keeping the
+// prefix excluded by a Prefix Exclude option within the delegated prefix
+func (a *Anonymizer) iaPrefix(o *OptionIAPrefix) error {
+	excluded, err := o.ExcludedPrefix()
+	if err != nil {
+		return err
+	}
+
+	o.Prefix = a.prefix(o.Prefix, o.PrefixLength)
+	if !excluded.IsValid() {
+		return nil
+	}
+
+	addr := a.Addr(excluded.Addr())
+	prefix, err := addr.Prefix(excluded.Bits())
+	if err != nil {
+		return err
+	}
+
+	return o.SetExcludedPrefix(prefix)
+}
+
+// helper function to anonymize a prefix, leaving the bits beyond its length 0
+func (a *Anonymizer) prefix(ip net.IP, length uint8) net.IP {
+	addr, err := ipv6Addr(ip)
+	if err != nil || length > 128 {
+		return ip
+	}
+
+	p, err := a.Addr(addr).Prefix(int(length))
+	if err != nil {
+		return ip
+	}
+	b := p.Addr().As16()
+
+	return net.IP(b[:])
+}
+
+// helper function to anonymize a marshalled DHCPv4 message. Its addresses,
+// client hardware address and options carrying addresses, host names or client
+// identifiers are pseudonymised. Other options are left out unless they are
+// known to carry nothing identifying, and so are the boot file name and the
+// server host name when they are overloaded with options
+func (a *Anonymizer) dhcpv4Message(data []byte) ([]byte, error) {
+	m, err := DecodeDHCPv4Message(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ClientAddr = a.ip4(m.ClientAddr)
+	m.YourAddr = a.ip4(m.YourAddr)
+	m.ServerAddr = a.ip4(m.ServerAddr)
+	m.GatewayAddr = a.ip4(m.GatewayAddr)
+	m.ClientHardwareAddr = a.HardwareAddr(m.ClientHardwareAddr)
+	m.ServerName = a.DomainName(m.ServerName)
+	// a boot file name might be derived from anything identifying the client
+	m.File = ""
+
+	opts := m.Options
+	m.Options = nil
+	for _, opt := range opts {
+		var b []byte
+		switch opt.Code {
+		case dhcpv4OptionSubnetMask, dhcpv4OptionLeaseTime, dhcpv4OptionMessageType,
+			dhcpv4OptionParameterList, dhcpv4OptionMaxMessageSize, dhcpv4OptionRenewalTime,
+			dhcpv4OptionRebindingTime, dhcpv4OptionVendorClassID:
+			b = append([]byte(nil), opt.Data...)
+		case dhcpv4OptionRouter, dhcpv4OptionDNSServer, dhcpv4OptionRequestedAddr, dhcpv4OptionServerID:
+			if len(opt.Data)%4 != 0 {
+				continue
+			}
+			for i := 0; i < len(opt.Data); i += 4 {
+				b = append(b, a.ip4(opt.Data[i:i+4])...)
+			}
+		case dhcpv4OptionHostName, dhcpv4OptionDomainName:
+			b = []byte(a.DomainName(string(opt.Data)))
+		case dhcpv4OptionClientID:
+			if b = a.dhcpv4ClientID(opt.Data); b == nil {
+				continue
+			}
+		case dhcpv4OptionClientFQDN:
+			if b = a.dhcpv4ClientFQDN(opt.Data); b == nil {
+				continue
+			}
+		case dhcpv4OptionOverload:
+			// the overloaded fields hold options that can't be told apart
+			m.ServerName = ""
+			continue
+		default:
+			continue
+		}
+		// leave out options whose pseudonyms outgrew them
+		if len(b) > 255 {
+			continue
+		}
+		m.Options = append(m.Options, DHCPv4Option{Code: opt.Code, Data: b})
+	}
+
+	return m.Marshal()
+}
+
+// helper function to anonymize the body of a DHCPv4 Client Identifier option,
+// keeping its type. It returns nil for identifiers that can't be decoded
+func (a *Anonymizer) dhcpv4ClientID(data []byte) []byte {
+	if len(data) < 2 {
+		return nil
+	}
+
+	b := []byte{data[0]}
+	switch data[0] {
+	case dhcpv4ClientIDTypeOpaque:
+		b = append(b, a.hash(anonLabelClientID, data[1:], len(data)-1)...)
+	case dhcpv4ClientIDTypeDUID:
+		// IAID followed by a DUID
+		if len(data) < 7 {
+			return nil
+		}
+		duid, err := DecodeDUID(data[5:])
+		if err != nil {
+			return nil
+		}
+		db, err := a.DUID(duid).Marshal()
+		if err != nil {
+			return nil
+		}
+		b = append(b, data[1:5]...)
+		b = append(b, db...)
+	default:
+		// other types are hardware types followed by a link-layer address
+		b = append(b, a.HardwareAddr(data[1:])...)
+	}
+
+	return b
+}
+
+// helper function to anonymize the body of a DHCPv4 Client FQDN option,
+// keeping its flags and RCODE fields. It returns nil for names that can't be
+// decoded
+func (a *Anonymizer) dhcpv4ClientFQDN(data []byte) []byte {
+	if len(data) < 3 {
+		return nil
+	}
+
+	b := append([]byte(nil), data[0:3]...)
+	if data[0]&dhcpv4FQDNFlagE == 0 {
+		return append(b, a.DomainName(string(data[3:]))...)
+	}
+
+	name, n, err := decodeDomainName(data[3:])
+	if err != nil || n != len(data)-3 {
+		return nil
+	}
+	b, err = appendDomainName(b, a.DomainName(name))
+	if err != nil {
+		return nil
+	}
+
+	return b
+}
+
+// helper function to anonymize an IPv4 net.IP
+func (a *Anonymizer) ip4(ip net.IP) net.IP {
+	addr, ok := netip.AddrFromSlice(ip.To4())
+	if !ok {
+		return ip
+	}
+	b := a.Addr(addr).As4()
+
+	return net.IP(b[:])
+}
+
+// helper function to anonymize a net.IP
+func (a *Anonymizer) ip(ip net.IP) net.IP {
+	addr, err := ipv6Addr(ip)
+	if err != nil {
+		return ip
+	}
+	b := a.Addr(addr).As16()
+
+	return net.IP(b[:])
+}
+
+// helper function to anonymize the host in given URL, which is either an
+// IPv6 address or a domain name. URLs that can't be parsed are left out
+func (a *Anonymizer) url(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	host, port := u.Hostname(), u.Port()
+	if addr, err := netip.ParseAddr(host); err == nil {
+		host = "[" + a.Addr(addr).String() + "]"
+	} else if host != "" {
+		host = a.DomainName(host)
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	return u.String()
+}
+
+// helper function to anonymize a marshalled Relay-forward or Relay-reply
+// message as described at https://tools.ietf.org/html/rfc8415#section-9
+func (a *Anonymizer) relayMessage(data []byte) ([]byte, error) {
+	if len(data) < anonRelayHeaderLen {
+		return nil, errMessageTooShort
+	}
+
+	// message type and hop count, followed by link and peer address
+	b := append([]byte(nil), data[0:2]...)
+	for _, addr := range [][]byte{data[2:18], data[18:34]} {
+		p := a.Addr(netip.AddrFrom16([16]byte(addr))).As16()
+		b = append(b, p[:]...)
+	}
+
+	for opts := data[anonRelayHeaderLen:]; len(opts) > 0; {
+		if len(opts) < 4 {
+			return nil, errOptionTooShort
+		}
+		optionType := binary.BigEndian.Uint16(opts[0:2])
+		optionLen := int(binary.BigEndian.Uint16(opts[2:4]))
+		if len(opts) < 4+optionLen {
+			return nil, errOptionTooShort
+		}
+		body := opts[4 : 4+optionLen]
+		opt := opts[:4+optionLen]
+		opts = opts[4+optionLen:]
+
+		switch optionType {
+		case uint16(OptionTypeRelayMessage):
+			msg, err := a.Bytes(body)
+			if err != nil {
+				return nil, err
+			}
+			b = binary.BigEndian.AppendUint16(b, optionType)
+			b = binary.BigEndian.AppendUint16(b, uint16(len(msg)))
+			b = append(b, msg...)
+		case uint16(OptionTypeInterfaceID):
+			b = append(b, opt[:4]...)
+			b = append(b, a.hash(anonLabelInterfaceID, body, len(body))...)
+		default:
+			decoded, err := DecodeOptions(opt)
+			if err != nil {
+				return nil, err
+			}
+			if decoded, err = a.options(decoded); err != nil {
+				return nil, err
+			}
+			ob, err := decoded.Marshal()
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, ob...)
+		}
+	}
+
+	return b, nil
+}
+
+// helper function returning n bytes of keyed hash of given data, for the kind
+// of data described by label
+func (a *Anonymizer) hash(label string, data []byte, n int) []byte {
+	var out []byte
+	for counter := uint32(0); len(out) < n; counter++ {
+		mac := hmac.New(sha256.New, a.key)
+		mac.Write([]byte(label))
+		mac.Write(binary.BigEndian.AppendUint32(nil, counter))
+		mac.Write(data)
+		out = mac.Sum(out)
+	}
+
+	return out[:n]
+}
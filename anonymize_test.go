@@ -0,0 +1,400 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAnonymizerAddr(t *testing.T) {
+	a := NewAnonymizer([]byte("secret"))
+
+	addr1 := netip.MustParseAddr("2001:db8:1:2::1")
+	addr2 := netip.MustParseAddr("2001:db8:1:3::1")
+	p1, p2 := a.Addr(addr1), a.Addr(addr2)
+	if p1 == addr1 || p2 == addr2 {
+		t.Errorf("expected addresses to be pseudonymised, got %s and %s", p1, p2)
+	}
+	if p := a.Addr(addr1); p != p1 {
+		t.Errorf("expected same pseudonym %s, got %s", p1, p)
+	}
+	if p := NewAnonymizer([]byte("secret")).Addr(addr1); p != p1 {
+		t.Errorf("expected same pseudonym %s with same key, got %s", p1, p)
+	}
+	if p := NewAnonymizer([]byte("other")).Addr(addr1); p == p1 {
+		t.Errorf("expected other pseudonym with other key, got %s", p)
+	}
+
+	// addresses sharing a /63 should have pseudonyms sharing a /63, but not a
+	// /64
+	pp1, _ := p1.Prefix(63)
+	pp2, _ := p2.Prefix(63)
+	if pp1 != pp2 {
+		t.Errorf("expected pseudonyms to share prefix, got %s and %s", pp1, pp2)
+	}
+	pp1, _ = p1.Prefix(64)
+	pp2, _ = p2.Prefix(64)
+	if pp1 == pp2 {
+		t.Errorf("expected pseudonyms to differ in /64, got %s and %s", p1, p2)
+	}
+
+	// link-local addresses keep their prefix
+	ll := netip.MustParseAddr("fe80::a8bb:ccff:fedd:eeff")
+	if p := a.Addr(ll); p == ll || !netip.MustParsePrefix("fe80::/64").Contains(p) {
+		t.Errorf("expected link-local pseudonym, got %s", p)
+	}
+
+	// IPv4 addresses map to IPv4 addresses, preserving prefixes as well
+	v41, v42 := netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("192.0.2.129")
+	p1, p2 = a.Addr(v41), a.Addr(v42)
+	if !p1.Is4() || p1 == v41 || !p2.Is4() || p2 == v42 {
+		t.Errorf("expected IPv4 addresses to be pseudonymised, got %s and %s", p1, p2)
+	}
+	pp1, _ = p1.Prefix(24)
+	pp2, _ = p2.Prefix(24)
+	if pp1 != pp2 || p1 == p2 {
+		t.Errorf("expected pseudonyms to share /24, got %s and %s", p1, p2)
+	}
+	ll = netip.MustParseAddr("169.254.1.2")
+	if p := a.Addr(ll); p == ll || !netip.MustParsePrefix("169.254.0.0/16").Contains(p) {
+		t.Errorf("expected IPv4 link-local pseudonym, got %s", p)
+	}
+
+	// special addresses are left as is
+	for _, addr := range []netip.Addr{AllDHCPRelayAgentsAndServers, netip.IPv6Unspecified(), netip.IPv6Loopback(), netip.MustParseAddr("::ffff:192.0.2.1"), netip.IPv4Unspecified(), netip.MustParseAddr("255.255.255.255")} {
+		if p := a.Addr(addr); p != addr {
+			t.Errorf("expected %s to be left as is, got %s", addr, p)
+		}
+	}
+}
+
+func TestAnonymizerHardwareAddr(t *testing.T) {
+	a := NewAnonymizer([]byte("secret"))
+	fixtmac, _ := net.ParseMAC("00:11:22:33:44:55")
+
+	p := a.HardwareAddr(fixtmac)
+	if len(p) != len(fixtmac) || bytes.Equal(p, fixtmac) {
+		t.Errorf("expected pseudonym of same length, got %s", p)
+	}
+	if p[0]&0x03 != 0x02 {
+		t.Errorf("expected locally administered unicast address, got %s", p)
+	}
+	if !bytes.Equal(a.HardwareAddr(fixtmac), p) {
+		t.Errorf("expected same pseudonym %s", p)
+	}
+}
+
+func TestAnonymizerDUID(t *testing.T) {
+	a := NewAnonymizer([]byte("secret"))
+	fixtmac, _ := net.ParseMAC("00:11:22:33:44:55")
+	fixtuuid, _ := uuid.Parse("7e66eaa2-e6dd-497b-8e21-31944b282b43")
+
+	for _, duid := range []DUID{
+		&DUIDLLT{HardwareType: HardwareTypeEthernet, Time: DUIDTime(500000000).Time(), LinkLayerAddress: fixtmac},
+		&DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac},
+		&DUIDEN{EnterpriseNumber: 32473, ID: []byte{1, 2, 3, 4}},
+		&DUIDUUID{UUID: fixtuuid},
+		&DUIDOpaque{Data: []byte{0, 99, 1, 2, 3}},
+	} {
+		fixtbyte, _ := duid.Marshal()
+		p := a.DUID(duid)
+		if p.Type() != duid.Type() || p.Len() != duid.Len() {
+			t.Errorf("expected pseudonym of type %s and length %d, got %s", duid.Type(), duid.Len(), p)
+		}
+		if EqualDUIDs(p, duid) {
+			t.Errorf("expected %s to be pseudonymised", duid)
+		}
+		if !EqualDUIDs(p, a.DUID(duid)) {
+			t.Errorf("expected same pseudonym for %s", duid)
+		}
+		// the original should be left untouched
+		if b, _ := duid.Marshal(); !bytes.Equal(fixtbyte, b) {
+			t.Errorf("expected original %s to be left as is", duid)
+		}
+		// and the pseudonym should decode to the same
+		b, _ := p.Marshal()
+		if d, err := DecodeDUID(b); err != nil {
+			t.Errorf("unexpected error decoding pseudonym: %s", err)
+		} else if !EqualDUIDs(d, p) {
+			t.Errorf("expected decoded pseudonym to equal %s, got %s", p, d)
+		}
+	}
+
+	// fields other than identifiers are kept
+	en := a.DUID(&DUIDEN{EnterpriseNumber: 32473, ID: []byte{1, 2, 3, 4}}).(*DUIDEN)
+	if en.EnterpriseNumber != 32473 {
+		t.Errorf("expected enterprise number %d, got %d", 32473, en.EnterpriseNumber)
+	}
+	if u := a.DUID(&DUIDUUID{UUID: fixtuuid}).(*DUIDUUID); u.UUID.Version() != 4 {
+		t.Errorf("expected UUID version 4, got %d", u.UUID.Version())
+	}
+}
+
+func TestAnonymizerDomainName(t *testing.T) {
+	a := NewAnonymizer([]byte("secret"))
+
+	p1 := a.DomainName("boot.customer.net.")
+	p2 := a.DomainName("tftp.Customer.net")
+	if labels := strings.Split(p1, "."); len(labels) != 4 || labels[2] != "net" || labels[3] != "" || strings.Contains(p1, "customer") {
+		t.Errorf("unexpected pseudonym %s", p1)
+	}
+	// names in the same domain share its pseudonym
+	if !strings.HasSuffix(p2, p1[strings.Index(p1, "."):len(p1)-1]) {
+		t.Errorf("expected %s and %s to share domain", p1, p2)
+	}
+	if p := a.DomainName("bootserver"); p == "bootserver" || strings.Contains(p, ".") {
+		t.Errorf("unexpected pseudonym %s", p)
+	}
+}
+
+func TestAnonymizerMessage(t *testing.T) {
+	a := NewAnonymizer([]byte("secret"))
+	clientID, serverID := testDUIDs()
+
+	iapd := &OptionIAPD{IAID: 1}
+	iaprefix := &OptionIAPrefix{Prefix: net.ParseIP("2001:db8:1::"), PrefixLength: 48}
+	if err := iaprefix.SetExcludedPrefix(netip.MustParsePrefix("2001:db8:1:1::/64")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	iapd.AddOption(iaprefix)
+	msg := &Message{MessageType: MessageTypeReply, Xid: 123456}
+	msg.AddOption(&OptionClientID{DUID: clientID})
+	msg.AddOption(&OptionServerID{DUID: serverID})
+	msg.AddOption(iapd)
+	msg.AddOption(&OptionRemoteID{EnterpriseNumber: 3561, RemoteID: []byte("eth0/1")})
+	msg.AddOption(&OptionBootFileURL{URL: "tftp://[2001:db8::69]:69/boot.efi"})
+	msg.AddOption(&OptionLQClientLink{LinkAddresses: []net.IP{net.ParseIP("2001:db8:1::1")}})
+	msg.AddOption(&OptionDNSServer{Servers: []net.IP{net.ParseIP("2001:db8::53")}})
+	msg.AddOption(&OptionDNSSearchList{DomainNames: []string{"example.com", "corp.example.com"}})
+	msg.AddOption(&OptionClientFQDN{Flags: ClientFQDNFlagS, DomainName: "host.corp.example.com."})
+	fixtmsg := msg.Clone()
+
+	p, err := a.Message(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !msg.Equal(fixtmsg) {
+		t.Error("expected original message to be left as is")
+	}
+	if p.Xid != msg.Xid || len(p.Options) != len(msg.Options) {
+		t.Errorf("expected same transaction-id and number of options")
+	}
+	for i, opt := range p.Options {
+		if opt.Type() != msg.Options[i].Type() || EqualOptions(opt, msg.Options[i]) {
+			t.Errorf("expected %s to be pseudonymised, got %s", msg.Options[i], opt)
+		}
+	}
+
+	if id, _ := Get[*OptionClientID](p.Options); !EqualDUIDs(id.DUID, a.DUID(clientID)) {
+		t.Errorf("expected client DUID %s, got %s", a.DUID(clientID), id.DUID)
+	}
+	if id, _ := Get[*OptionRemoteID](p.Options); id.EnterpriseNumber != 3561 || len(id.RemoteID) != 6 {
+		t.Errorf("expected remote-ID of same enterprise number and length, got %s", id)
+	}
+	if dns, _ := Get[*OptionDNSServer](p.Options); !dns.Servers[0].Equal(a.ip(net.ParseIP("2001:db8::53"))) {
+		t.Errorf("expected DNS server %s, got %s", a.ip(net.ParseIP("2001:db8::53")), dns.Servers[0])
+	}
+	if fqdn, _ := Get[*OptionClientFQDN](p.Options); fqdn.Flags != ClientFQDNFlagS || fqdn.DomainName != a.DomainName("host.corp.example.com.") {
+		t.Errorf("expected client FQDN %s with same flags, got %s", a.DomainName("host.corp.example.com."), fqdn)
+	}
+	if list, _ := Get[*OptionDNSSearchList](p.Options); len(list.DomainNames) != 2 || list.DomainNames[1] != a.DomainName("corp.example.com") {
+		t.Errorf("expected pseudonymised search list, got %s", list)
+	}
+	if url, _ := Get[*OptionBootFileURL](p.Options); !strings.HasPrefix(url.URL, "tftp://["+a.Addr(netip.MustParseAddr("2001:db8::69")).String()+"]:69/") {
+		t.Errorf("unexpected boot file URL %s", url.URL)
+	}
+
+	// the excluded prefix should still be within the delegated prefix
+	pd, _ := Get[*OptionIAPD](p.Options)
+	prefix := pd.HasOption(OptionTypeIAPrefix).(*OptionIAPrefix)
+	delegated, err := prefix.NetPrefix()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	excluded, err := prefix.ExcludedPrefix()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if delegated.Bits() != 48 || excluded.Bits() != 64 || !delegated.Contains(excluded.Addr()) {
+		t.Errorf("expected excluded prefix within delegated prefix, got %s and %s", excluded, delegated)
+	}
+	if link, _ := Get[*OptionLQClientLink](p.Options); !delegated.Contains(netip.AddrFrom16([16]byte(link.LinkAddresses[0]))) {
+		t.Errorf("expected %s to stay within %s", link.LinkAddresses[0], delegated)
+	}
+
+	// options of unknown types are left out, also when nested
+	iana := &OptionIANA{IAID: 2}
+	iana.AddOption(&OptionUnknown{Code: 200, Data: []byte("secret")})
+	msg = &Message{MessageType: MessageTypeReply, Xid: 123456}
+	msg.AddOption(&OptionUnknown{Code: 200, Data: []byte("secret")})
+	msg.AddOption(iana)
+	if p, err = a.Message(msg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(p.Options) != 1 || len(p.Options[0].(*OptionIANA).Options()) != 0 {
+		t.Errorf("expected options of unknown types to be left out, got %s", p.Options)
+	}
+	if len(msg.Options) != 2 || len(iana.Options()) != 1 {
+		t.Error("expected original message to be left as is")
+	}
+}
+
+func TestAnonymizerDHCPv4Message(t *testing.T) {
+	a := NewAnonymizer([]byte("secret"))
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	fixtfqdn, _ := appendDomainName([]byte{dhcpv4FQDNFlagE | 0x01, 0, 0}, "laptop.example.com.")
+
+	v4 := &DHCPv4Message{
+		Op:                 1,
+		HardwareType:       1,
+		Xid:                42,
+		ClientAddr:         net.ParseIP("192.0.2.10"),
+		YourAddr:           net.ParseIP("192.0.2.11"),
+		ServerAddr:         net.ParseIP("192.0.2.1"),
+		GatewayAddr:        net.ParseIP("198.51.100.1"),
+		ClientHardwareAddr: fixtmac,
+		ServerName:         "server.example.com",
+		File:               "pxelinux.cfg/01-aa-bb-cc-dd-ee-ff",
+		Options: []DHCPv4Option{
+			{Code: dhcpv4OptionMessageType, Data: []byte{1}},
+			{Code: dhcpv4OptionRequestedAddr, Data: []byte{192, 0, 2, 10}},
+			{Code: dhcpv4OptionHostName, Data: []byte("laptop")},
+			{Code: dhcpv4OptionClientID, Data: append([]byte{1}, fixtmac...)},
+			{Code: dhcpv4OptionClientFQDN, Data: fixtfqdn},
+			// relay agent information and vendor specific information
+			{Code: 82, Data: []byte{1, 6, 101, 116, 104, 48, 47, 49}},
+			{Code: 43, Data: []byte("secret")},
+		},
+	}
+	v4b, err := v4.Marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	msg := &Message{MessageType: MessageTypeDHCPv4Query, Xid: 123456}
+	msg.AddOption(&OptionDHCPv4Message{Message: v4b})
+
+	p, err := a.Message(msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	opt, _ := Get[*OptionDHCPv4Message](p.Options)
+	m, err := DecodeDHCPv4Message(opt.Message)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if m.Op != v4.Op || m.Xid != v4.Xid {
+		t.Errorf("expected same op and xid, got %s", m)
+	}
+	for i, addr := range []net.IP{m.ClientAddr, m.YourAddr, m.ServerAddr, m.GatewayAddr} {
+		orig := []net.IP{v4.ClientAddr, v4.YourAddr, v4.ServerAddr, v4.GatewayAddr}[i]
+		if !addr.Equal(a.ip4(orig)) || addr.Equal(orig) {
+			t.Errorf("expected %s to be pseudonymised, got %s", orig, addr)
+		}
+	}
+	if !bytes.Equal(m.ClientHardwareAddr, a.HardwareAddr(fixtmac)) {
+		t.Errorf("expected client hardware address %s, got %s", a.HardwareAddr(fixtmac), m.ClientHardwareAddr)
+	}
+	if m.ServerName != a.DomainName(v4.ServerName) || m.File != "" {
+		t.Errorf("expected pseudonymised server name and no file, got %s and %s", m.ServerName, m.File)
+	}
+
+	fixtopts := []DHCPv4Option{
+		{Code: dhcpv4OptionMessageType, Data: []byte{1}},
+		{Code: dhcpv4OptionRequestedAddr, Data: a.ip4(net.IP{192, 0, 2, 10})},
+		{Code: dhcpv4OptionHostName, Data: []byte(a.DomainName("laptop"))},
+		{Code: dhcpv4OptionClientID, Data: append([]byte{1}, a.HardwareAddr(fixtmac)...)},
+	}
+	fixtfqdn, _ = appendDomainName([]byte{dhcpv4FQDNFlagE | 0x01, 0, 0}, a.DomainName("laptop.example.com."))
+	fixtopts = append(fixtopts, DHCPv4Option{Code: dhcpv4OptionClientFQDN, Data: fixtfqdn})
+	if !reflect.DeepEqual(m.Options, fixtopts) {
+		t.Errorf("expected options %v, got %v", fixtopts, m.Options)
+	}
+
+	// client identifiers holding a DUID keep their IAID
+	clientID, _ := testDUIDs()
+	duidb, _ := clientID.Marshal()
+	p4b := a.dhcpv4ClientID(append([]byte{dhcpv4ClientIDTypeDUID, 0, 0, 0, 1}, duidb...))
+	if len(p4b) < 5 || !bytes.Equal(p4b[:5], []byte{dhcpv4ClientIDTypeDUID, 0, 0, 0, 1}) {
+		t.Errorf("expected client identifier type and IAID to be kept, got %v", p4b)
+	} else if d, err := DecodeDUID(p4b[5:]); err != nil || !EqualDUIDs(d, a.DUID(clientID)) {
+		t.Errorf("expected DUID %s, got %v", a.DUID(clientID), d)
+	}
+}
+
+func TestAnonymizerRelayChain(t *testing.T) {
+	a := NewAnonymizer([]byte("secret"))
+	clientID, _ := testDUIDs()
+
+	solicit := &Message{MessageType: MessageTypeSolicit, Xid: 123456}
+	solicit.AddOption(&OptionClientID{DUID: clientID})
+	solicitb, _ := solicit.Marshal()
+
+	// helper function to build a relay-forward message around msg
+	relay := func(hops uint8, link, peer string, msg []byte, opts ...[]byte) []byte {
+		b := []byte{uint8(MessageTypeRelayForward), hops}
+		b = append(b, net.ParseIP(link).To16()...)
+		b = append(b, net.ParseIP(peer).To16()...)
+		for _, opt := range opts {
+			b = append(b, opt...)
+		}
+		b = binary.BigEndian.AppendUint16(b, uint16(OptionTypeRelayMessage))
+		b = binary.BigEndian.AppendUint16(b, uint16(len(msg)))
+		return append(b, msg...)
+	}
+	interfaceID := []byte{0, 18, 0, 4, 'e', 't', 'h', '0'}
+	inner := relay(0, "2001:db8:1::1", "fe80::a8bb:ccff:fedd:eeff", solicitb, interfaceID)
+	outer := relay(1, "::", "2001:db8:1::1", inner)
+
+	b, err := a.Bytes(outer)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(b) != len(outer) {
+		t.Fatalf("expected relay chain of length %d, got %d", len(outer), len(b))
+	}
+
+	// outer relay agent
+	if b[1] != 1 || !net.IP(b[2:18]).Equal(net.IPv6unspecified) {
+		t.Errorf("expected hop count and unspecified link address to be kept")
+	}
+	fixtpeer := a.Addr(netip.MustParseAddr("2001:db8:1::1")).AsSlice()
+	if !net.IP(b[18:34]).Equal(fixtpeer) {
+		t.Errorf("expected peer address %s, got %s", net.IP(fixtpeer), net.IP(b[18:34]))
+	}
+
+	// inner relay agent
+	b = b[38:]
+	if !net.IP(b[2:18]).Equal(fixtpeer) {
+		t.Errorf("expected link address %s, got %s", net.IP(fixtpeer), net.IP(b[2:18]))
+	}
+	if !netip.MustParsePrefix("fe80::/64").Contains(netip.AddrFrom16([16]byte(b[18:34]))) {
+		t.Errorf("expected link-local peer address, got %s", net.IP(b[18:34]))
+	}
+	if !bytes.Equal(b[34:38], interfaceID[:4]) || bytes.Equal(b[38:42], interfaceID[4:]) {
+		t.Errorf("expected interface-ID to be pseudonymised, got %v", b[34:42])
+	}
+
+	// relayed client message
+	msg, err := DecodeMessage(b[46:])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if id, _ := Get[*OptionClientID](msg.Options); id == nil || !EqualDUIDs(id.DUID, a.DUID(clientID)) {
+		t.Errorf("expected client DUID %s, got %v", a.DUID(clientID), id)
+	}
+
+	// test for error on truncated relay message
+	if _, err := a.Bytes(outer[:20]); err != errMessageTooShort {
+		t.Errorf("expected error %s, got %v", errMessageTooShort, err)
+	}
+	if _, err := a.Bytes(outer[:len(outer)-1]); err != errOptionTooShort {
+		t.Errorf("expected error %s, got %v", errOptionTooShort, err)
+	}
+}
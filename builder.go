@@ -0,0 +1,294 @@
+package dhcpv6
+
+import (
+	"errors"
+	"net"
+)
+
+var (
+	errNoClientID         = errors.New("message has no client identifier")
+	errNoServerID         = errors.New("message has no server identifier")
+	errUnexpectedMessage  = errors.New("unexpected message type")
+	errNoDeclineAddresses = errors.New("no addresses to decline")
+)
+
+// option types requested in the Option Request option of messages built by
+// the message builders, callers can change the list in the returned Message
+var defaultOptionRequest = []OptionType{OptionTypeDNSServer, OptionTypeDNSSearchList}
+
+// NewSolicit returns a Solicit message for the client with given DUID, as
+// described at https://tools.ietf.org/html/rfc8415#section-18.2.1. Any IA_NA
+// or IA_PD options in ias are added to the message as is
+func NewSolicit(clientID DUID, ias ...Option) (*Message, error) {
+	if clientID == nil {
+		return nil, errNoClientID
+	}
+
+	m, err := newClientMessage(MessageTypeSolicit, clientID)
+	if err != nil {
+		return nil, err
+	}
+	m.AddOption(newOptionRequest())
+	for _, ia := range ias {
+		m.AddOption(ia)
+	}
+
+	return m, nil
+}
+
+// NewRequest returns a Request message for the addresses and prefixes offered
+// in given Advertise message, as described at
+// https://tools.ietf.org/html/rfc8415#section-18.2.2
+func NewRequest(advertise *Message) (*Message, error) {
+	if advertise == nil || advertise.MessageType != MessageTypeAdvertise {
+		return nil, errUnexpectedMessage
+	}
+
+	return newLeaseMessage(MessageTypeRequest, advertise, true, true)
+}
+
+// NewRenew returns a Renew message extending the leases in given Reply
+// message with the server that sent it, as described at
+// https://tools.ietf.org/html/rfc8415#section-18.2.4
+func NewRenew(reply *Message) (*Message, error) {
+	if reply == nil || reply.MessageType != MessageTypeReply {
+		return nil, errUnexpectedMessage
+	}
+
+	return newLeaseMessage(MessageTypeRenew, reply, true, true)
+}
+
+// NewRebind returns a Rebind message extending the leases in given Reply
+// message with any server, as described at
+// https://tools.ietf.org/html/rfc8415#section-18.2.5
+func NewRebind(reply *Message) (*Message, error) {
+	if reply == nil || reply.MessageType != MessageTypeReply {
+		return nil, errUnexpectedMessage
+	}
+
+	return newLeaseMessage(MessageTypeRebind, reply, false, true)
+}
+
+// NewRelease returns a Release message giving back the leases in given Reply
+// message, as described at https://tools.ietf.org/html/rfc8415#section-18.2.7
+func NewRelease(reply *Message) (*Message, error) {
+	if reply == nil || reply.MessageType != MessageTypeReply {
+		return nil, errUnexpectedMessage
+	}
+
+	return newLeaseMessage(MessageTypeRelease, reply, true, false)
+}
+
+// NewDecline returns a Decline message for given addresses leased in given
+// Reply message, for instance because duplicate address detection failed for
+// them, as described at https://tools.ietf.org/html/rfc8415#section-18.2.8
+func NewDecline(reply *Message, addresses ...net.IP) (*Message, error) {
+	if reply == nil || reply.MessageType != MessageTypeReply {
+		return nil, errUnexpectedMessage
+	}
+
+	m, err := newLeaseMessage(MessageTypeDecline, reply, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// only keep the IA_NAs and addresses that are declined
+	var options Options
+	var declined bool
+	for _, opt := range m.Options {
+		switch o := opt.(type) {
+		case *OptionIANA:
+			var addrs Options
+			for _, nested := range o.options {
+				if containsIP(addresses, nested.(*OptionIAAddress).Address) {
+					addrs = append(addrs, nested)
+				}
+			}
+			if len(addrs) > 0 {
+				o.options = addrs
+				options = append(options, o)
+				declined = true
+			}
+		case *OptionIAPD:
+			// prefixes cannot be declined
+		default:
+			options = append(options, opt)
+		}
+	}
+	if !declined {
+		return nil, errNoDeclineAddresses
+	}
+	m.Options = options
+
+	return m, nil
+}
+
+// NewConfirm returns a Confirm message asking whether the addresses leased in
+// given Reply message are still appropriate for the link the client is
+// attached to, as described at
+// https://tools.ietf.org/html/rfc8415#section-18.2.3
+func NewConfirm(reply *Message) (*Message, error) {
+	if reply == nil || reply.MessageType != MessageTypeReply {
+		return nil, errUnexpectedMessage
+	}
+
+	m, err := newLeaseMessage(MessageTypeConfirm, reply, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Confirm messages only cover addresses, not delegated prefixes
+	var options Options
+	for _, opt := range m.Options {
+		if opt.Type() != OptionTypeIAPD {
+			options = append(options, opt)
+		}
+	}
+	m.Options = options
+
+	return m, nil
+}
+
+// NewInformationRequest returns an Information-request message, as described
+// at https://tools.ietf.org/html/rfc8415#section-18.2.6. Since a client
+// identifier is optional in this message, clientID may be nil
+func NewInformationRequest(clientID DUID) (*Message, error) {
+	m, err := newClientMessage(MessageTypeInformationRequest, clientID)
+	if err != nil {
+		return nil, err
+	}
+	m.AddOption(newOptionRequest())
+
+	return m, nil
+}
+
+// helper function to create a message of given type with a new transaction-id,
+// the client identifier, if any, and the elapsed time every client message
+// carries
+func newClientMessage(t MessageType, clientID DUID) (*Message, error) {
+	xid, err := NewTransactionID()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Message{
+		MessageType: t,
+		Xid:         xid,
+	}
+	if clientID != nil {
+		m.AddOption(&OptionClientID{DUID: clientID})
+	}
+	m.AddOption(&OptionElapsedTime{})
+
+	return m, nil
+}
+
+// helper function to create a message of given type for the leases in msg,
+// optionally identifying the server that sent msg and requesting options
+func newLeaseMessage(t MessageType, msg *Message, withServerID, withOptionRequest bool) (*Message, error) {
+	clientID, ok := msg.HasOption(OptionTypeClientID).(*OptionClientID)
+	if !ok {
+		return nil, errNoClientID
+	}
+	serverID, ok := msg.HasOption(OptionTypeServerID).(*OptionServerID)
+	if withServerID && !ok {
+		return nil, errNoServerID
+	}
+
+	m, err := newClientMessage(t, clientID.DUID)
+	if err != nil {
+		return nil, err
+	}
+	if withServerID {
+		m.AddOption(&OptionServerID{DUID: serverID.DUID})
+	}
+	if withOptionRequest {
+		m.AddOption(newOptionRequest())
+	}
+	for _, ia := range leasedIAs(msg) {
+		m.AddOption(ia)
+	}
+
+	return m, nil
+}
+
+// helper function returning a fresh option request option with the default
+// list of option types
+func newOptionRequest() *OptionOptionRequest {
+	return &OptionOptionRequest{
+		Options: append([]OptionType(nil), defaultOptionRequest...),
+	}
+}
+
+// helper function to copy the IA_NA and IA_PD options from msg to a new list
+// of options for a client message. As described at
+// https://tools.ietf.org/html/rfc8415#section-21.4 and further, clients set
+// T1, T2 and lifetimes to 0. IAs for which the server reported an error and
+// addresses or prefixes that are no longer valid are left out
+func leasedIAs(msg *Message) Options {
+	var ias Options
+	for _, opt := range msg.Options {
+		switch o := opt.(type) {
+		case *OptionIANA:
+			if !iaSucceeded(o.options) {
+				continue
+			}
+			iana := &OptionIANA{IAID: o.IAID}
+			for _, nested := range o.options {
+				addr, ok := nested.(*OptionIAAddress)
+				if !ok || addr.ValidLifetime == 0 {
+					continue
+				}
+				iana.AddOption(&OptionIAAddress{
+					Address: append(net.IP(nil), addr.Address...),
+				})
+			}
+			if len(iana.options) > 0 {
+				ias = append(ias, iana)
+			}
+		case *OptionIAPD:
+			if !iaSucceeded(o.options) {
+				continue
+			}
+			iapd := &OptionIAPD{IAID: o.IAID}
+			for _, nested := range o.options {
+				prefix, ok := nested.(*OptionIAPrefix)
+				if !ok || prefix.ValidLifetime == 0 {
+					continue
+				}
+				iapd.AddOption(&OptionIAPrefix{
+					PrefixLength: prefix.PrefixLength,
+					Prefix:       append(net.IP(nil), prefix.Prefix...),
+				})
+			}
+			if len(iapd.options) > 0 {
+				ias = append(ias, iapd)
+			}
+		}
+	}
+
+	return ias
+}
+
+// helper function returning false when the options of an IA contain a status
+// code other than success
+func iaSucceeded(opts Options) bool {
+	for _, opt := range opts {
+		if sc, ok := opt.(*OptionStatusCode); ok && sc.Code != StatusCodeSuccess {
+			return false
+		}
+	}
+
+	return true
+}
+
+// helper function returning true if ip is in list
+func containsIP(list []net.IP, ip net.IP) bool {
+	for _, i := range list {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
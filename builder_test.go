@@ -0,0 +1,218 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+)
+
+// helper function returning a Reply message as a server would send it, leasing
+// two addresses and a prefix and refusing a second IA_NA
+func testReply(t *testing.T, mtype MessageType) *Message {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+
+	iana := &OptionIANA{IAID: 1, T1: 300 * time.Second, T2: 450 * time.Second}
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1"), PreferredLifetime: 600 * time.Second, ValidLifetime: 900 * time.Second})
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::2"), PreferredLifetime: 600 * time.Second, ValidLifetime: 900 * time.Second})
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::3")})
+	noaddrs := &OptionIANA{IAID: 2}
+	noaddrs.AddOption(&OptionStatusCode{Code: StatusCodeNoAddrsAvail})
+	iapd := &OptionIAPD{IAID: 3, T1: 300 * time.Second, T2: 450 * time.Second}
+	iapd.AddOption(&OptionIAPrefix{Prefix: net.ParseIP("2001:db8:1::"), PrefixLength: 56, PreferredLifetime: 600 * time.Second, ValidLifetime: 900 * time.Second})
+
+	msg := &Message{MessageType: mtype, Xid: 123456}
+	msg.AddOption(&OptionClientID{DUID: &DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac}})
+	msg.AddOption(&OptionServerID{DUID: &DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac}})
+	msg.AddOption(iana)
+	msg.AddOption(noaddrs)
+	msg.AddOption(iapd)
+
+	// decode marshalled message, like it was received from the network
+	b, err := msg.Marshal()
+	if err != nil {
+		t.Fatalf("error marshalling message: %s", err)
+	}
+	msg, err = DecodeMessage(b)
+	if err != nil {
+		t.Fatalf("error decoding message: %s", err)
+	}
+
+	return msg
+}
+
+// helper function to compare a built message against fixture bytes, ignoring
+// its random transaction-id
+func checkBuiltMessage(t *testing.T, msg *Message, mtype MessageType, fixtbyte []byte) {
+	t.Helper()
+
+	if msg.MessageType != mtype {
+		t.Errorf("expected message type %s, got %s", mtype, msg.MessageType)
+	}
+	if msg.Xid > MaxTransactionID {
+		t.Errorf("expected 24 bit transaction-id, got %d", msg.Xid)
+	}
+	msg.Xid = 123456
+	if mshByte, err := msg.Marshal(); err != nil {
+		t.Errorf("error marshalling message: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+}
+
+var (
+	fixtBuilderClientID = []byte{0, 1, 0, 10, 0, 3, 0, 1, 170, 187, 204, 221, 238, 255}
+	fixtBuilderServerID = []byte{0, 2, 0, 10, 0, 3, 0, 1, 170, 187, 204, 221, 238, 255}
+	fixtBuilderElapsed  = []byte{0, 8, 0, 2, 0, 0}
+	fixtBuilderORO      = []byte{0, 6, 0, 4, 0, 23, 0, 24}
+	fixtBuilderIANA     = []byte{0, 3, 0, 68, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 5, 0, 24, 32, 1, 13, 184, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 5, 0, 24, 32, 1, 13, 184, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0}
+	fixtBuilderIAPD = []byte{0, 25, 0, 41, 0, 0, 0, 3, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 26, 0, 25, 0, 0, 0, 0, 0, 0, 0, 0, 56, 32, 1, 13, 184, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+)
+
+// helper function to concatenate message header and options
+func fixtMessage(mtype MessageType, opts ...[]byte) []byte {
+	b := []byte{uint8(mtype), 1, 226, 64}
+	for _, opt := range opts {
+		b = append(b, opt...)
+	}
+	return b
+}
+
+func TestNewSolicit(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	clientID := &DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac}
+
+	msg, err := NewSolicit(clientID, &OptionIANA{IAID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkBuiltMessage(t, msg, MessageTypeSolicit, fixtMessage(MessageTypeSolicit,
+		fixtBuilderClientID, fixtBuilderElapsed, fixtBuilderORO,
+		[]byte{0, 3, 0, 12, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0}))
+
+	// test for error without client identifier
+	if _, err := NewSolicit(nil); err != errNoClientID {
+		t.Errorf("expected error %s, got %v", errNoClientID, err)
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	msg, err := NewRequest(testReply(t, MessageTypeAdvertise))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkBuiltMessage(t, msg, MessageTypeRequest, fixtMessage(MessageTypeRequest,
+		fixtBuilderClientID, fixtBuilderElapsed, fixtBuilderServerID, fixtBuilderORO,
+		fixtBuilderIANA, fixtBuilderIAPD))
+
+	// test for error when not answering an Advertise
+	if _, err := NewRequest(testReply(t, MessageTypeReply)); err != errUnexpectedMessage {
+		t.Errorf("expected error %s, got %v", errUnexpectedMessage, err)
+	}
+	if _, err := NewRequest(nil); err != errUnexpectedMessage {
+		t.Errorf("expected error %s, got %v", errUnexpectedMessage, err)
+	}
+
+	// test for error when server identifier is missing
+	advertise := testReply(t, MessageTypeAdvertise)
+	advertise.Options = advertise.Options[:1]
+	if _, err := NewRequest(advertise); err != errNoServerID {
+		t.Errorf("expected error %s, got %v", errNoServerID, err)
+	}
+
+	// test for error when client identifier is missing
+	advertise.Options = nil
+	if _, err := NewRequest(advertise); err != errNoClientID {
+		t.Errorf("expected error %s, got %v", errNoClientID, err)
+	}
+}
+
+func TestNewRenewRebindRelease(t *testing.T) {
+	tests := []struct {
+		build    func(*Message) (*Message, error)
+		mtype    MessageType
+		fixtbyte []byte
+	}{
+		{
+			NewRenew, MessageTypeRenew,
+			fixtMessage(MessageTypeRenew, fixtBuilderClientID, fixtBuilderElapsed,
+				fixtBuilderServerID, fixtBuilderORO, fixtBuilderIANA, fixtBuilderIAPD),
+		},
+		{
+			NewRebind, MessageTypeRebind,
+			fixtMessage(MessageTypeRebind, fixtBuilderClientID, fixtBuilderElapsed,
+				fixtBuilderORO, fixtBuilderIANA, fixtBuilderIAPD),
+		},
+		{
+			NewRelease, MessageTypeRelease,
+			fixtMessage(MessageTypeRelease, fixtBuilderClientID, fixtBuilderElapsed,
+				fixtBuilderServerID, fixtBuilderIANA, fixtBuilderIAPD),
+		},
+		{
+			NewConfirm, MessageTypeConfirm,
+			fixtMessage(MessageTypeConfirm, fixtBuilderClientID, fixtBuilderElapsed,
+				fixtBuilderIANA),
+		},
+	}
+
+	for _, test := range tests {
+		msg, err := test.build(testReply(t, MessageTypeReply))
+		if err != nil {
+			t.Errorf("unexpected error building %s: %s", test.mtype, err)
+			continue
+		}
+		checkBuiltMessage(t, msg, test.mtype, test.fixtbyte)
+
+		// test for error when not answering a Reply
+		if _, err := test.build(testReply(t, MessageTypeAdvertise)); err != errUnexpectedMessage {
+			t.Errorf("expected error %s building %s, got %v", errUnexpectedMessage, test.mtype, err)
+		}
+		if _, err := test.build(nil); err != errUnexpectedMessage {
+			t.Errorf("expected error %s building %s from nil, got %v", errUnexpectedMessage, test.mtype, err)
+		}
+	}
+}
+
+func TestNewDecline(t *testing.T) {
+	msg, err := NewDecline(testReply(t, MessageTypeReply), net.ParseIP("2001:db8::2"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkBuiltMessage(t, msg, MessageTypeDecline, fixtMessage(MessageTypeDecline,
+		fixtBuilderClientID, fixtBuilderElapsed, fixtBuilderServerID,
+		[]byte{0, 3, 0, 40, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0,
+			0, 5, 0, 24, 32, 1, 13, 184, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0}))
+
+	// test for error without a Reply
+	if _, err := NewDecline(nil, net.ParseIP("2001:db8::2")); err != errUnexpectedMessage {
+		t.Errorf("expected error %s, got %v", errUnexpectedMessage, err)
+	}
+
+	// test for error when declining addresses that were not leased
+	if _, err := NewDecline(testReply(t, MessageTypeReply), net.ParseIP("2001:db8::3")); err != errNoDeclineAddresses {
+		t.Errorf("expected error %s, got %v", errNoDeclineAddresses, err)
+	}
+}
+
+func TestNewInformationRequest(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	clientID := &DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac}
+
+	msg, err := NewInformationRequest(clientID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkBuiltMessage(t, msg, MessageTypeInformationRequest, fixtMessage(MessageTypeInformationRequest,
+		fixtBuilderClientID, fixtBuilderElapsed, fixtBuilderORO))
+
+	// client identifier is optional
+	msg, err = NewInformationRequest(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkBuiltMessage(t, msg, MessageTypeInformationRequest, fixtMessage(MessageTypeInformationRequest,
+		fixtBuilderElapsed, fixtBuilderORO))
+}
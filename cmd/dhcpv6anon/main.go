@@ -0,0 +1,209 @@
+// Command dhcpv6anon pseudonymises DUIDs, link-layer addresses, IPv6
+// addresses and prefixes, Remote-IDs and domain names in DHCPv6 messages and
+// the DHCPv4 messages they carry, so captures can be shared without revealing
+// who was in them.
+//
+// Usage:
+//
+//	dhcpv6anon [-key key | -keyfile file] [-o file] [capture ...]
+//	dhcpv6anon -in hex [-key key | -keyfile file] [-o file] [file ...]
+//
+// Captures are read as pcap or pcapng and written as a single pcap, holding
+// only the DHCPv6 packets in them. Hex strings are read and written one
+// message per line. Without arguments, input is read from stdin.
+//
+// The same key gives the same pseudonyms, so captures anonymized with the
+// same key can be correlated. Without a key, a random one is used. Messages
+// that can't be decoded are left out, since they might carry identifying data,
+// and so are options of types dhcpv6anon doesn't know
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/skoef/dhcpv6"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs dhcpv6anon with given arguments and returns its exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("dhcpv6anon", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: dhcpv6anon [flags] [file ...]")
+		flags.PrintDefaults()
+	}
+	in := flags.String("in", "pcap", "input and output `format`: pcap (also reads pcapng) or hex")
+	key := flags.String("key", "", "`key` for the keyed hash, random if empty")
+	keyFile := flags.String("keyfile", "", "read the key for the keyed hash from `file`")
+	output := flags.String("o", "", "write output to `file` instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *in != "pcap" && *in != "hex" {
+		fmt.Fprintf(stderr, "dhcpv6anon: unknown input format %q\n", *in)
+		return 2
+	}
+	if *key != "" && *keyFile != "" {
+		fmt.Fprintln(stderr, "dhcpv6anon: use either -key or -keyfile")
+		return 2
+	}
+
+	k := []byte(*key)
+	switch {
+	case *keyFile != "":
+		var err error
+		if k, err = os.ReadFile(*keyFile); err != nil {
+			fmt.Fprintf(stderr, "dhcpv6anon: %s\n", err)
+			return 1
+		}
+	case *key == "":
+		k = make([]byte, 32)
+		if _, err := rand.Read(k); err != nil {
+			fmt.Fprintf(stderr, "dhcpv6anon: %s\n", err)
+			return 1
+		}
+	}
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "dhcpv6anon: %s\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+
+	var failed bool
+	an := &anonymizer{
+		a: dhcpv6.NewAnonymizer(k),
+		report: func(err error) {
+			fmt.Fprintf(stderr, "dhcpv6anon: %s\n", err)
+			failed = true
+		},
+	}
+	read := an.readHex
+	if *in == "pcap" {
+		var err error
+		if an.pcap, err = dhcpv6.NewPcapWriter(bw); err != nil {
+			fmt.Fprintf(stderr, "dhcpv6anon: %s\n", err)
+			return 1
+		}
+		read = an.readPcap
+	}
+	an.out = bw
+
+	if flags.NArg() == 0 {
+		if err := read(stdin, "stdin"); err != nil {
+			an.report(err)
+		}
+	}
+	for _, name := range flags.Args() {
+		if err := readFile(name, read); err != nil {
+			an.report(err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		an.report(err)
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// helper function to open the named file and pass it to read
+func readFile(name string, read func(io.Reader, string) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return read(f, name)
+}
+
+// anonymizer writes the anonymized version of the messages it reads
+type anonymizer struct {
+	a    *dhcpv6.Anonymizer
+	out  io.Writer
+	pcap *dhcpv6.PcapWriter
+	// report is called for messages that could not be anonymized, after
+	// which the anonymizer continues with the next message
+	report func(error)
+}
+
+// readPcap anonymizes the DHCPv6 packets captured in r, including their
+// addresses
+func (an *anonymizer) readPcap(r io.Reader, name string) error {
+	p, err := dhcpv6.NewPcapReader(r)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	for i := 1; ; i++ {
+		pkt, err := p.ReadPacket()
+		if err == io.EOF {
+			return nil
+		}
+		if pkt == nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+
+		data, err := an.a.Bytes(pkt.Data)
+		if err != nil {
+			an.report(fmt.Errorf("%s: packet %d: %s", name, i, err))
+			continue
+		}
+		err = an.pcap.WritePacket(&dhcpv6.Packet{
+			Timestamp: pkt.Timestamp,
+			Src:       netip.AddrPortFrom(an.a.Addr(pkt.Src.Addr()), pkt.Src.Port()),
+			Dst:       netip.AddrPortFrom(an.a.Addr(pkt.Dst.Addr()), pkt.Dst.Port()),
+			Data:      data,
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readHex anonymizes the messages in r, given as hex strings one per line
+func (an *anonymizer) readHex(r io.Reader, name string) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1024*1024)
+	for line := 1; s.Scan(); line++ {
+		text := strings.NewReplacer(" ", "", "\t", "", ":", "").Replace(s.Text())
+		if text == "" {
+			continue
+		}
+
+		b, err := hex.DecodeString(text)
+		if err == nil {
+			b, err = an.a.Bytes(b)
+		}
+		if err != nil {
+			an.report(fmt.Errorf("%s:%d: %s", name, line, err))
+			continue
+		}
+		if _, err := fmt.Fprintln(an.out, hex.EncodeToString(b)); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skoef/dhcpv6"
+)
+
+// Solicit with a DUID-LLT holding aa:bb:cc:dd:ee:ff and an IA_NA holding
+// 2001:db8::1
+const fixtSolicit = "0101e2400001000e0001000100000001aabbccddeeff000800020000000300280000000100000000000000000005001820010db800000000000000000000000100000064000000c8"
+
+// helper function to run dhcpv6anon and return its exit status and output
+func testRun(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return status, stdout.String(), stderr.String()
+}
+
+func TestAnonHex(t *testing.T) {
+	status, stdout, stderr := testRun(t, fixtSolicit+"\n\nzz\n", "-in", "hex", "-key", "foo")
+	if status != 1 {
+		t.Errorf("expected exit status 1, got %d", status)
+	}
+	if !strings.Contains(stderr, "stdin:3:") {
+		t.Errorf("expected error for line 3, got %s", stderr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(lines))
+	}
+	b, err := hex.DecodeString(lines[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	msg, err := dhcpv6.DecodeMessage(b)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if strings.Contains(msg.Dump(), "aa:bb:cc:dd:ee:ff") || strings.Contains(msg.Dump(), "2001:db8::1") {
+		t.Errorf("expected identifying data to be replaced, got %s", msg.Dump())
+	}
+
+	// options of unknown types are left out without anything else ending up
+	// in the output, and the client FQDN is pseudonymised
+	fixtfqdn := fixtSolicit + "0027000801066c6170746f70" + "012c0003010203"
+	if _, out, _ := testRun(t, fixtfqdn, "-in", "hex", "-key", "foo"); strings.Count(out, "\n") != 1 {
+		t.Errorf("expected a single line of output, got %q", out)
+	} else if b, err := hex.DecodeString(strings.TrimSpace(out)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if msg, err := dhcpv6.DecodeMessage(b); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if fqdn, ok := dhcpv6.Get[*dhcpv6.OptionClientFQDN](msg.Options); !ok || fqdn.DomainName == "laptop" {
+		t.Errorf("expected pseudonymised client FQDN, got %s", msg.Dump())
+	} else if len(msg.Options) != 4 {
+		t.Errorf("expected option of unknown type to be left out, got %s", msg.Dump())
+	}
+
+	// the same key gives the same output
+	if _, again, _ := testRun(t, fixtSolicit, "-in", "hex", "-key", "foo"); again != stdout {
+		t.Errorf("expected output %s for same key, got %s", stdout, again)
+	}
+
+	// a key file holding the same key as well
+	name := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(name, []byte("foo"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, again, _ := testRun(t, fixtSolicit, "-in", "hex", "-keyfile", name); again != stdout {
+		t.Errorf("expected output %s for same key file, got %s", stdout, again)
+	}
+
+	// test for usage errors
+	for _, args := range [][]string{
+		{"-in", "foo"},
+		{"-key", "foo", "-keyfile", name},
+	} {
+		if status, _, _ := testRun(t, "", args...); status != 2 {
+			t.Errorf("expected exit status 2 for %v, got %d", args, status)
+		}
+	}
+}
+
+func TestAnonPcap(t *testing.T) {
+	data, err := hex.DecodeString(fixtSolicit)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fixtts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fixtsrc := netip.MustParseAddrPort("[2001:db8::2]:546")
+
+	var capture bytes.Buffer
+	w, err := dhcpv6.NewPcapWriter(&capture)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for _, pkt := range []*dhcpv6.Packet{
+		{Timestamp: fixtts, Src: fixtsrc, Dst: netip.MustParseAddrPort("[ff02::1:2]:547"), Data: data},
+		// truncated message is left out
+		{Timestamp: fixtts, Src: fixtsrc, Dst: netip.MustParseAddrPort("[ff02::1:2]:547"), Data: data[:10]},
+	} {
+		if err := w.WritePacket(pkt); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	output := filepath.Join(t.TempDir(), "anonymized.pcap")
+	status, _, stderr := testRun(t, capture.String(), "-o", output)
+	if status != 1 {
+		t.Errorf("expected exit status 1, got %d", status)
+	}
+	if !strings.Contains(stderr, "packet 2:") {
+		t.Errorf("expected error for packet 2, got %s", stderr)
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer f.Close()
+	r, err := dhcpv6.NewPcapReader(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	pkt, err := r.ReadPacket()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !pkt.Timestamp.Equal(fixtts) {
+		t.Errorf("expected timestamp %s, got %s", fixtts, pkt.Timestamp)
+	}
+	if pkt.Src.Addr() == fixtsrc.Addr() || pkt.Src.Port() != fixtsrc.Port() {
+		t.Errorf("expected anonymized source address with port %d, got %s", fixtsrc.Port(), pkt.Src)
+	}
+	// multicast addresses are kept
+	if pkt.Dst.Addr() != dhcpv6.AllDHCPRelayAgentsAndServers {
+		t.Errorf("expected destination %s, got %s", dhcpv6.AllDHCPRelayAgentsAndServers, pkt.Dst.Addr())
+	}
+	if pkt.Message.MessageType != dhcpv6.MessageTypeSolicit || pkt.Message.Xid != 0x01e240 {
+		t.Errorf("expected Solicit with xid 0x01e240, got %s with xid %#06x", pkt.Message.MessageType, pkt.Message.Xid)
+	}
+	if _, err := r.ReadPacket(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+
+	// test for error on input that isn't a capture
+	if status, _, _ := testRun(t, "foo"); status != 1 {
+		t.Errorf("expected exit status 1, got %d", status)
+	}
+}
@@ -0,0 +1,319 @@
+// Command dhcpv6craft builds DHCPv6 messages from JSON descriptions and writes
+// them as hex strings, raw bytes or a pcap capture.
+//
+// Usage:
+//
+//	dhcpv6craft [-out hex|raw|pcap] [-o file] [description ...]
+//
+// A description is a message in the JSON encoding of this package, as printed
+// by dhcpv6dump -out json, optionally wrapped in an object with Timestamp, Src
+// and Dst of the packet carrying it:
+//
+//	{
+//	  "MessageType": 1,
+//	  "Xid": 123456,
+//	  "Options": [
+//	    {"Type": 8, "ElapsedTime": 0},
+//	    {"Type": 14, "Length": 2},
+//	    {"Type": 300, "Body": "01:02:03"},
+//	    {"Raw": "00:06:00:02:00"}
+//	  ],
+//	  "Trailer": "ff:ff"
+//	}
+//
+// To craft malformed messages, options can override their length field with
+// Length, be given as a type and Body only or be given as Raw bytes including
+// their header. Trailer bytes are appended after the options. Without
+// arguments, descriptions are read from stdin. Files can hold a single
+// description, a stream of them or a JSON array of them.
+//
+// Descriptions are JSON only: YAML input is out of scope, since it would add a
+// YAML parser as dependency for no more than a different notation. YAML
+// descriptions using the same fields can be converted first, for instance
+// with yq -o json
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/skoef/dhcpv6"
+)
+
+var errInvalidRawOption = errors.New("raw option shorter than option header")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs dhcpv6craft with given arguments and returns its exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("dhcpv6craft", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: dhcpv6craft [flags] [description ...]")
+		flags.PrintDefaults()
+	}
+	out := flags.String("out", "hex", "output `format`: hex, raw or pcap")
+	output := flags.String("o", "", "write output to `file` instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *out != "hex" && *out != "raw" && *out != "pcap" {
+		fmt.Fprintf(stderr, "dhcpv6craft: unknown output format %q\n", *out)
+		return 2
+	}
+
+	// craft all packets before writing any output
+	var pkts []*dhcpv6.Packet
+	craftAll := func(r io.Reader, name string) error {
+		descs, err := readDescriptions(r)
+		if err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+		for i, desc := range descs {
+			pkt, err := craft(desc)
+			if err != nil {
+				return fmt.Errorf("%s: description %d: %s", name, i+1, err)
+			}
+			pkts = append(pkts, pkt)
+		}
+		return nil
+	}
+	if flags.NArg() == 0 {
+		if err := craftAll(stdin, "stdin"); err != nil {
+			fmt.Fprintf(stderr, "dhcpv6craft: %s\n", err)
+			return 1
+		}
+	}
+	for _, name := range flags.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "dhcpv6craft: %s\n", err)
+			return 1
+		}
+		err = craftAll(f, name)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(stderr, "dhcpv6craft: %s\n", err)
+			return 1
+		}
+	}
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "dhcpv6craft: %s\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(w, *out, pkts); err != nil {
+		fmt.Fprintf(stderr, "dhcpv6craft: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// helper function to write crafted packets to w in given format
+func write(w io.Writer, format string, pkts []*dhcpv6.Packet) error {
+	bw := bufio.NewWriter(w)
+
+	switch format {
+	case "hex":
+		for _, pkt := range pkts {
+			fmt.Fprintln(bw, hex.EncodeToString(pkt.Data))
+		}
+	case "raw":
+		for _, pkt := range pkts {
+			bw.Write(pkt.Data)
+		}
+	case "pcap":
+		p, err := dhcpv6.NewPcapWriter(bw)
+		if err != nil {
+			return err
+		}
+		for _, pkt := range pkts {
+			if err := p.WritePacket(pkt); err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// helper function to read the descriptions in r, which holds a JSON array of
+// descriptions or a stream of them
+func readDescriptions(r io.Reader) ([]json.RawMessage, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var descs []json.RawMessage
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		err := json.Unmarshal(b, &descs)
+		return descs, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	for {
+		var desc json.RawMessage
+		if err := dec.Decode(&desc); err == io.EOF {
+			return descs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		descs = append(descs, desc)
+	}
+}
+
+// packetDescription describes a packet carrying a message, as printed by
+// dhcpv6dump -out json
+type packetDescription struct {
+	Timestamp time.Time
+	Src       string
+	Dst       string
+	Message   json.RawMessage
+}
+
+// messageDescription describes a message, its options are crafted one by one
+type messageDescription struct {
+	MessageType dhcpv6.MessageType
+	Xid         uint32
+	Flags       uint32
+	Options     []json.RawMessage
+	Trailer     string
+}
+
+// optionDescription holds the escape hatches of an option description
+type optionDescription struct {
+	Type   uint16
+	Length *uint16
+	Body   *string
+	Raw    *string
+}
+
+// craft returns a packet with the message in given description as Data
+func craft(desc json.RawMessage) (*dhcpv6.Packet, error) {
+	pkt := &dhcpv6.Packet{Timestamp: time.Now()}
+
+	var pd packetDescription
+	if err := json.Unmarshal(desc, &pd); err != nil {
+		return nil, err
+	}
+	if pd.Message != nil {
+		desc = pd.Message
+		if !pd.Timestamp.IsZero() {
+			pkt.Timestamp = pd.Timestamp
+		}
+		var err error
+		if pd.Src != "" {
+			if pkt.Src, err = netip.ParseAddrPort(pd.Src); err != nil {
+				return nil, err
+			}
+		}
+		if pd.Dst != "" {
+			if pkt.Dst, err = netip.ParseAddrPort(pd.Dst); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var md messageDescription
+	if err := json.Unmarshal(desc, &md); err != nil {
+		return nil, err
+	}
+
+	// let the package marshal and validate the message header
+	header := dhcpv6.Message{
+		MessageType: md.MessageType,
+		Xid:         md.Xid,
+		Flags:       md.Flags,
+	}
+	b, err := header.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, od := range md.Options {
+		ob, err := craftOption(od)
+		if err != nil {
+			return nil, fmt.Errorf("option %d: %s", i+1, err)
+		}
+		b = append(b, ob...)
+	}
+
+	trailer, err := parseHex(md.Trailer)
+	if err != nil {
+		return nil, fmt.Errorf("trailer: %s", err)
+	}
+	pkt.Data = append(b, trailer...)
+
+	return pkt, nil
+}
+
+// helper function to return the bytes of the option in given description
+func craftOption(desc json.RawMessage) ([]byte, error) {
+	var od optionDescription
+	if err := json.Unmarshal(desc, &od); err != nil {
+		return nil, err
+	}
+
+	var b []byte
+	switch {
+	case od.Raw != nil:
+		var err error
+		if b, err = parseHex(*od.Raw); err != nil {
+			return nil, err
+		}
+		if len(b) < 4 && od.Length != nil {
+			return nil, errInvalidRawOption
+		}
+	case od.Body != nil:
+		body, err := parseHex(*od.Body)
+		if err != nil {
+			return nil, err
+		}
+		b = binary.BigEndian.AppendUint16(nil, od.Type)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(body)))
+		b = append(b, body...)
+	default:
+		// let the package decode and marshal the option
+		var opts dhcpv6.Options
+		if err := json.Unmarshal(append(append([]byte("["), desc...), ']'), &opts); err != nil {
+			return nil, err
+		}
+		var err error
+		if b, err = opts[0].Marshal(); err != nil {
+			return nil, err
+		}
+	}
+
+	if od.Length != nil {
+		binary.BigEndian.PutUint16(b[2:4], *od.Length)
+	}
+
+	return b, nil
+}
+
+// helper function to parse hex bytes, optionally separated by colons or
+// spaces
+func parseHex(s string) ([]byte, error) {
+	return hex.DecodeString(strings.NewReplacer(":", "", " ", "").Replace(s))
+}
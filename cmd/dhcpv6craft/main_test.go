@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skoef/dhcpv6"
+)
+
+// helper function to run dhcpv6craft and return its exit status and output
+func testRun(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return status, stdout.String(), stderr.String()
+}
+
+func TestCraft(t *testing.T) {
+	tests := []struct {
+		desc string
+		hex  string
+	}{
+		{
+			`{"MessageType":7,"Xid":123456,"Options":[{"Type":14}]}`,
+			"0701e240000e0000",
+		},
+		{
+			// as printed by dhcpv6dump
+			`{"Message":{"Flags":0,"MessageType":7,"Name":"Reply","Options":[{"Name":"Rapid Commit","Type":14}],"Xid":1}}`,
+			"07000001000e0000",
+		},
+		{
+			`{"MessageType":20,"Flags":8388608}`,
+			"14800000",
+		},
+		{
+			// wrong length
+			`{"MessageType":1,"Xid":1,"Options":[{"Type":8,"ElapsedTime":0,"Length":4}]}`,
+			"01000001000800040000",
+		},
+		{
+			// unknown option type with opaque body
+			`{"MessageType":1,"Xid":1,"Options":[{"Type":300,"Body":"01:02:03"}]}`,
+			"01000001012c0003010203",
+		},
+		{
+			// truncated raw option and trailing garbage
+			`{"MessageType":1,"Xid":1,"Options":[{"Raw":"00 06 00 02 00"}],"Trailer":"ffff"}`,
+			"010000010006000200ffff",
+		},
+	}
+
+	for _, test := range tests {
+		status, stdout, stderr := testRun(t, test.desc)
+		if status != 0 {
+			t.Errorf("expected exit status 0 for %s, got %d: %s", test.desc, status, stderr)
+			continue
+		}
+		if stdout != test.hex+"\n" {
+			t.Errorf("unexpected output for %s: expected %s, got %s", test.desc, test.hex, stdout)
+		}
+	}
+
+	// test for errors on invalid descriptions
+	for _, desc := range []string{
+		`{"MessageType":1,"Xid":16777216}`,
+		`{"MessageType":1,"Options":[{"Type":99}]}`,
+		`{"MessageType":1,"Options":[{"Raw":"zz"}]}`,
+		`{"MessageType":1,"Options":[{"Raw":"00","Length":1}]}`,
+		`{"MessageType":1,"Trailer":"z"}`,
+		`{"Message":{"MessageType":1},"Src":"foo"}`,
+		`{"MessageType":1`,
+	} {
+		if status, _, _ := testRun(t, desc); status != 1 {
+			t.Errorf("expected exit status 1 for %s, got %d", desc, status)
+		}
+	}
+
+	// test for usage error on unknown output format
+	if status, _, _ := testRun(t, "", "-out", "foo"); status != 2 {
+		t.Errorf("expected exit status 2, got %d", status)
+	}
+}
+
+func TestCraftOutput(t *testing.T) {
+	descs := `[
+		{"MessageType":1,"Xid":1,"Options":[{"Type":8,"ElapsedTime":0}]},
+		{"Timestamp":"2024-03-01T12:00:00Z","Src":"[fe80::1]:547","Dst":"[fe80::2]:546","Message":{"MessageType":7,"Xid":1,"Options":[{"Type":8,"Length":0}]}}
+	]`
+	dir := t.TempDir()
+	name := filepath.Join(dir, "descriptions.json")
+	if err := os.WriteFile(name, []byte(descs), 0o644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// raw output of all messages
+	if status, stdout, stderr := testRun(t, "", "-out", "raw", name); status != 0 {
+		t.Errorf("expected exit status 0, got %d: %s", status, stderr)
+	} else if fixtbyte := []byte{1, 0, 0, 1, 0, 8, 0, 2, 0, 0, 7, 0, 0, 1, 0, 8, 0, 0, 0, 0}; !bytes.Equal([]byte(stdout), fixtbyte) {
+		t.Errorf("raw output didn't match fixture!\nfixture: %v\noutput:  %v", fixtbyte, []byte(stdout))
+	}
+
+	// pcap output to file
+	output := filepath.Join(dir, "crafted.pcap")
+	if status, _, stderr := testRun(t, "", "-out", "pcap", "-o", output, name); status != 0 {
+		t.Fatalf("expected exit status 0, got %d: %s", status, stderr)
+	}
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer f.Close()
+	r, err := dhcpv6.NewPcapReader(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the Solicit is sent between the default addresses
+	pkt, err := r.ReadPacket()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pkt.Message.MessageType != dhcpv6.MessageTypeSolicit || pkt.Dst.Addr() != dhcpv6.AllDHCPRelayAgentsAndServers {
+		t.Errorf("expected Solicit to %s, got %s to %s", dhcpv6.AllDHCPRelayAgentsAndServers, pkt.Message.MessageType, pkt.Dst)
+	}
+
+	// the malformed Reply keeps its addresses and timestamp
+	pkt, err = r.ReadPacket()
+	if err == nil {
+		t.Error("expected error decoding malformed message")
+	}
+	if pkt == nil {
+		t.Fatal("expected packet with malformed message")
+	}
+	if pkt.Src != netip.MustParseAddrPort("[fe80::1]:547") || pkt.Dst != netip.MustParseAddrPort("[fe80::2]:546") {
+		t.Errorf("unexpected addresses %s -> %s", pkt.Src, pkt.Dst)
+	}
+	if fixtts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC); !pkt.Timestamp.Equal(fixtts) {
+		t.Errorf("expected timestamp %s, got %s", fixtts, pkt.Timestamp)
+	}
+	if _, err := r.ReadPacket(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
@@ -0,0 +1,395 @@
+// Command dhcpv6dump decodes DHCPv6 messages from hex strings, raw files or
+// pcap and pcapng captures and prints them as indented text or JSON lines.
+//
+// Usage:
+//
+//	dhcpv6dump [flags] [hex string ...]
+//	dhcpv6dump -in raw [flags] [file ...]
+//	dhcpv6dump -in pcap [flags] [file ...]
+//
+// Without arguments, hex strings are read from stdin one message per line and
+// raw messages and captures are read from stdin as a whole. Messages can be
+// filtered on message type, option type, DUID and transaction-id, all given
+// filters must match for a message to be printed
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skoef/dhcpv6"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs dhcpv6dump with given arguments and returns its exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("dhcpv6dump", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: dhcpv6dump [flags] [hex string ...]")
+		fmt.Fprintln(stderr, "       dhcpv6dump -in raw|pcap [flags] [file ...]")
+		flags.PrintDefaults()
+	}
+	in := flags.String("in", "hex", "input `format`: hex, raw or pcap (also reads pcapng)")
+	out := flags.String("out", "text", "output `format`: text or json")
+	hexDump := flags.Bool("x", false, "add a hex view of every message to text output")
+	types := flags.String("type", "", "only print messages of these comma separated message `types`, by name or code")
+	options := flags.String("option", "", "only print messages carrying any of these comma separated option `types`, by name or code")
+	duid := flags.String("duid", "", "only print messages with this client, server or relay `DUID`")
+	xid := flags.String("xid", "", "only print messages with this `transaction-id`")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	d := &dumper{out: stdout, json: *out == "json", hexDump: *hexDump}
+	if *out != "text" && *out != "json" {
+		fmt.Fprintf(stderr, "dhcpv6dump: unknown output format %q\n", *out)
+		return 2
+	}
+	var err error
+	if d.filter, err = newFilter(*types, *options, *duid, *xid); err != nil {
+		fmt.Fprintf(stderr, "dhcpv6dump: %s\n", err)
+		return 2
+	}
+
+	var read func(io.Reader, string) error
+	switch *in {
+	case "hex":
+		read = d.readHex
+	case "raw":
+		read = d.readRaw
+	case "pcap":
+		read = d.readPcap
+	default:
+		fmt.Fprintf(stderr, "dhcpv6dump: unknown input format %q\n", *in)
+		return 2
+	}
+
+	// hex strings can be given as arguments, the other formats are read from
+	// the files given as arguments
+	var failed bool
+	report := func(err error) {
+		fmt.Fprintf(stderr, "dhcpv6dump: %s\n", err)
+		failed = true
+	}
+	d.report = report
+	switch {
+	case flags.NArg() == 0:
+		if err := read(stdin, "stdin"); err != nil {
+			report(err)
+		}
+	case *in == "hex":
+		for i, arg := range flags.Args() {
+			if err := d.readHex(strings.NewReader(arg), fmt.Sprintf("argument %d", i+1)); err != nil {
+				report(err)
+			}
+		}
+	default:
+		for _, name := range flags.Args() {
+			if err := readFile(name, read); err != nil {
+				report(err)
+			}
+		}
+	}
+
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// helper function to open the named file and pass it to read
+func readFile(name string, read func(io.Reader, string) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return read(f, name)
+}
+
+// dumper prints the messages passing its filter
+type dumper struct {
+	out     io.Writer
+	json    bool
+	hexDump bool
+	filter  *filter
+	// report is called for messages that could not be decoded, after which
+	// the dumper continues with the next message
+	report func(error)
+}
+
+// readHex prints the messages in r, given as hex strings one per line. Spaces,
+// colons and a 0x prefix are allowed, as are empty lines and comments
+// starting with #
+func (d *dumper) readHex(r io.Reader, name string) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1024*1024)
+	for line := 1; s.Scan(); line++ {
+		text, _, _ := strings.Cut(s.Text(), "#")
+		text = strings.NewReplacer(" ", "", "\t", "", ":", "").Replace(text)
+		text = strings.TrimPrefix(strings.TrimPrefix(text, "0x"), "0X")
+		if text == "" {
+			continue
+		}
+
+		b, err := hex.DecodeString(text)
+		if err != nil {
+			d.report(fmt.Errorf("%s:%d: invalid hex string: %s", name, line, err))
+			continue
+		}
+		if err := d.decode(&dhcpv6.Packet{Data: b}, fmt.Sprintf("%s:%d", name, line)); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
+
+// readRaw prints the single raw message in r
+func (d *dumper) readRaw(r io.Reader, name string) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return d.decode(&dhcpv6.Packet{Data: b}, name)
+}
+
+// readPcap prints the DHCPv6 messages captured in r
+func (d *dumper) readPcap(r io.Reader, name string) error {
+	p, err := dhcpv6.NewPcapReader(r)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	for i := 1; ; i++ {
+		pkt, err := p.ReadPacket()
+		if err == io.EOF {
+			return nil
+		}
+		if pkt == nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+		if err != nil {
+			d.report(fmt.Errorf("%s: packet %d: %s", name, i, err))
+			continue
+		}
+		if err := d.print(pkt); err != nil {
+			return err
+		}
+	}
+}
+
+// helper function to decode the message in given packet and print it
+func (d *dumper) decode(pkt *dhcpv6.Packet, name string) error {
+	msg, err := dhcpv6.DecodeMessage(pkt.Data)
+	if err != nil {
+		d.report(fmt.Errorf("%s: could not decode message: %s", name, err))
+		return nil
+	}
+	pkt.Message = msg
+
+	return d.print(pkt)
+}
+
+// packetJSON is the JSON representation of a printed packet, addresses and
+// timestamp are left out for messages not read from a capture
+type packetJSON struct {
+	Timestamp *time.Time      `json:",omitempty"`
+	Src       string          `json:",omitempty"`
+	Dst       string          `json:",omitempty"`
+	Message   *dhcpv6.Message `json:",omitempty"`
+}
+
+// print prints given packet if it passes the filter
+func (d *dumper) print(pkt *dhcpv6.Packet) error {
+	if !d.filter.match(pkt.Message) {
+		return nil
+	}
+
+	if d.json {
+		p := packetJSON{Message: pkt.Message}
+		if !pkt.Timestamp.IsZero() {
+			p.Timestamp = &pkt.Timestamp
+		}
+		if pkt.Src.IsValid() {
+			p.Src, p.Dst = pkt.Src.String(), pkt.Dst.String()
+		}
+		b, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(d.out, "%s\n", b)
+		return err
+	}
+
+	var b strings.Builder
+	if pkt.Src.IsValid() {
+		if !pkt.Timestamp.IsZero() {
+			fmt.Fprintf(&b, "%s ", pkt.Timestamp.Format(time.RFC3339Nano))
+		}
+		fmt.Fprintf(&b, "%s > %s\n", pkt.Src, pkt.Dst)
+	}
+	b.WriteString(pkt.Message.Dump())
+	if d.hexDump {
+		h, err := pkt.Message.HexDump()
+		if err != nil {
+			return err
+		}
+		b.WriteString(h)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(d.out, b.String())
+
+	return err
+}
+
+// filter describes which messages to print, nil fields match any message
+type filter struct {
+	types   map[dhcpv6.MessageType]bool
+	options map[dhcpv6.OptionType]bool
+	duid    dhcpv6.DUID
+	xid     *uint32
+}
+
+// newFilter returns a filter for given flag values
+func newFilter(types, options, duid, xid string) (*filter, error) {
+	f := &filter{}
+
+	if types != "" {
+		f.types = make(map[dhcpv6.MessageType]bool)
+		for _, s := range strings.Split(types, ",") {
+			v, err := lookupType(s, 8, func(i int) fmt.Stringer { return dhcpv6.MessageType(i) })
+			if err != nil {
+				return nil, fmt.Errorf("unknown message type %q", s)
+			}
+			f.types[dhcpv6.MessageType(v)] = true
+		}
+	}
+
+	if options != "" {
+		f.options = make(map[dhcpv6.OptionType]bool)
+		for _, s := range strings.Split(options, ",") {
+			v, err := lookupType(s, 16, func(i int) fmt.Stringer { return dhcpv6.OptionType(i) })
+			if err != nil {
+				return nil, fmt.Errorf("unknown option type %q", s)
+			}
+			f.options[dhcpv6.OptionType(v)] = true
+		}
+	}
+
+	if duid != "" {
+		var err error
+		if f.duid, err = dhcpv6.ParseDUID(duid); err != nil {
+			return nil, err
+		}
+	}
+
+	if xid != "" {
+		v, err := strconv.ParseUint(xid, 0, 32)
+		if err != nil || uint32(v) > dhcpv6.MaxTransactionID {
+			return nil, fmt.Errorf("invalid transaction-id %q", xid)
+		}
+		x := uint32(v)
+		f.xid = &x
+	}
+
+	return f, nil
+}
+
+// helper function to find a message or option type by its code or its name as
+// printed by its String method, ignoring case, spaces, dashes and underscores.
+// Codes are at most bitSize bits
+func lookupType(s string, bitSize int, stringer func(int) fmt.Stringer) (int, error) {
+	if v, err := strconv.ParseUint(strings.TrimSpace(s), 0, bitSize); err == nil {
+		return int(v), nil
+	}
+
+	normalize := strings.NewReplacer(" ", "", "-", "", "_", "")
+	want := strings.ToLower(normalize.Replace(s))
+	for i := 1; i < 256; i++ {
+		name := stringer(i).String()
+		name = strings.TrimSuffix(name, fmt.Sprintf(" (%d)", i))
+		if strings.ToLower(normalize.Replace(name)) == want {
+			return i, nil
+		}
+	}
+
+	return 0, errors.New("unknown type")
+}
+
+// match returns true if given message passes all filters
+func (f *filter) match(m *dhcpv6.Message) bool {
+	if f.types != nil && !f.types[m.MessageType] {
+		return false
+	}
+	if f.xid != nil && m.Xid != *f.xid {
+		return false
+	}
+	if f.options != nil && !f.matchOptions(m.Options) {
+		return false
+	}
+	if f.duid != nil && !f.matchDUID(m.Options) {
+		return false
+	}
+
+	return true
+}
+
+// interface of options containing options themselves
+type optionsContainer interface {
+	Options() dhcpv6.Options
+}
+
+// helper function returning true if any of opts or their nested options has
+// one of the filtered option types
+func (f *filter) matchOptions(opts dhcpv6.Options) bool {
+	for _, opt := range opts {
+		if f.options[opt.Type()] {
+			return true
+		}
+		if c, ok := opt.(optionsContainer); ok && f.matchOptions(c.Options()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// helper function returning true if any of opts or their nested options
+// identifies a client, server or relay agent by the filtered DUID
+func (f *filter) matchDUID(opts dhcpv6.Options) bool {
+	for _, opt := range opts {
+		var duid dhcpv6.DUID
+		switch o := opt.(type) {
+		case *dhcpv6.OptionClientID:
+			duid = o.DUID
+		case *dhcpv6.OptionServerID:
+			duid = o.DUID
+		case *dhcpv6.OptionRelayID:
+			duid = o.DUID
+		}
+		if dhcpv6.EqualDUIDs(f.duid, duid) {
+			return true
+		}
+		if c, ok := opt.(optionsContainer); ok && f.matchDUID(c.Options()) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skoef/dhcpv6"
+)
+
+var (
+	// Solicit with elapsed time and client identifier aa:bb:cc:dd:ee:ff
+	fixtSolicit = "0101e240 0008 0002 0000 0001 000a 0003 0001 aabbccddeeff"
+	// Reply with rapid commit and another transaction-id
+	fixtReply = "07000001 000e 0000"
+)
+
+// helper function to run dhcpv6dump and return its exit status and output
+func testRun(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	return status, stdout.String(), stderr.String()
+}
+
+func TestRunHex(t *testing.T) {
+	fixtout := `Solicit (1), transaction-id 0x01e240
+  Elapsed Time (8), length 2
+    ElapsedTime: 0s
+  Client Identifier (1), length 10
+    DUID: LinkLayer, length 10
+      HardwareType: Ethernet (1)
+      LinkLayerAddress: aa:bb:cc:dd:ee:ff
+
+Reply (7), transaction-id 0x000001
+  Rapid Commit (14), length 0
+
+`
+	status, stdout, stderr := testRun(t, "# some messages\n"+fixtSolicit+"\n\n"+fixtReply+"\n")
+	if status != 0 {
+		t.Errorf("expected exit status 0, got %d: %s", status, stderr)
+	}
+	if stdout != fixtout {
+		t.Errorf("unexpected output:\nexpected:\n%s\ngot:\n%s", fixtout, stdout)
+	}
+
+	// hex strings given as arguments, with a hex view
+	fixtout = `Reply (7), transaction-id 0x000001
+  Rapid Commit (14), length 0
+0000  07 00 00 01                                      Reply (7)
+0004  00 0e 00 00                                      Rapid Commit (14)
+
+`
+	if _, stdout, _ := testRun(t, "", "-x", strings.ReplaceAll(fixtReply, " ", "")); stdout != fixtout {
+		t.Errorf("unexpected output:\nexpected:\n%s\ngot:\n%s", fixtout, stdout)
+	}
+
+	// messages that can't be decoded are reported, but don't stop the dump
+	status, stdout, stderr = testRun(t, "zz\n0101\n"+fixtReply+"\n")
+	if status != 1 {
+		t.Errorf("expected exit status 1, got %d", status)
+	}
+	if strings.Count(stderr, "\n") != 2 {
+		t.Errorf("expected 2 errors, got %q", stderr)
+	}
+	if !strings.HasPrefix(stdout, "Reply (7)") {
+		t.Errorf("expected Reply to be printed, got %q", stdout)
+	}
+
+	// options this package doesn't decode are shown as well
+	fixtout = `{"Message":{"Flags":0,"MessageType":1,"Name":"Solicit","Options":[{"Data":"01:02","Name":"Unknown","Type":200},{"ElapsedTime":100000000,"Name":"Elapsed Time","Type":8}],"Xid":11259375}}
+`
+	if _, stdout, _ := testRun(t, "", "-out", "json", "01abcdef00c80002010200080002000a"); stdout != fixtout {
+		t.Errorf("unexpected output:\nexpected:\n%s\ngot:\n%s", fixtout, stdout)
+	}
+}
+
+func TestRunFilter(t *testing.T) {
+	tests := []struct {
+		args   []string
+		output string
+	}{
+		{[]string{"-type", "reply"}, "Reply (7)"},
+		{[]string{"-type", "Solicit,7"}, "Solicit (1)Reply (7)"},
+		{[]string{"-type", "advertise"}, ""},
+		{[]string{"-option", "rapid-commit"}, "Reply (7)"},
+		{[]string{"-option", "1"}, "Solicit (1)"},
+		{[]string{"-xid", "0x01e240"}, "Solicit (1)"},
+		{[]string{"-xid", "1"}, "Reply (7)"},
+		{[]string{"-duid", "00:03:00:01:aa:bb:cc:dd:ee:ff"}, "Solicit (1)"},
+		{[]string{"-duid", "00030001aabbccddeeff", "-type", "reply"}, ""},
+	}
+
+	for _, test := range tests {
+		status, stdout, stderr := testRun(t, fixtSolicit+"\n"+fixtReply+"\n", test.args...)
+		if status != 0 {
+			t.Errorf("expected exit status 0 for %v, got %d: %s", test.args, status, stderr)
+			continue
+		}
+
+		// only compare message types
+		var output string
+		for _, line := range strings.Split(stdout, "\n") {
+			if strings.HasPrefix(line, "Solicit") || strings.HasPrefix(line, "Reply") {
+				output += line[:strings.Index(line, ",")]
+			}
+		}
+		if output != test.output {
+			t.Errorf("unexpected messages for %v: expected %q, got %q", test.args, test.output, output)
+		}
+	}
+
+	// test for usage errors on invalid filters
+	for _, args := range [][]string{
+		{"-type", "foo"},
+		{"-option", "bar"},
+		{"-xid", "0x1000000"},
+		{"-duid", "zz"},
+		{"-in", "foo"},
+		{"-out", "foo"},
+	} {
+		if status, _, _ := testRun(t, "", args...); status != 2 {
+			t.Errorf("expected exit status 2 for %v, got %d", args, status)
+		}
+	}
+}
+
+func TestRunPcap(t *testing.T) {
+	msg, err := dhcpv6.DecodeMessage([]byte{7, 0, 0, 1, 0, 14, 0, 0})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := dhcpv6.NewPcapWriter(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := w.WriteMessage(msg, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	name := filepath.Join(t.TempDir(), "reply.pcap")
+	if err := os.WriteFile(name, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fixtout := `{"Timestamp":"2024-03-01T12:00:00Z","Src":"[fe80::211:22ff:fe33:4455]:547","Dst":"[fe80::a8bb:ccff:fedd:eeff]:546","Message":{"Flags":0,"MessageType":7,"Name":"Reply","Options":[{"Name":"Rapid Commit","Type":14}],"Xid":1}}
+`
+	status, stdout, stderr := testRun(t, "", "-in", "pcap", "-out", "json", name)
+	if status != 0 {
+		t.Errorf("expected exit status 0, got %d: %s", status, stderr)
+	}
+	if stdout != fixtout {
+		t.Errorf("unexpected output:\nexpected:\n%s\ngot:\n%s", fixtout, stdout)
+	}
+
+	// the same capture can be read from stdin
+	if _, stdout, _ := testRun(t, buf.String(), "-in", "pcap", "-out", "json"); stdout != fixtout {
+		t.Errorf("unexpected output:\nexpected:\n%s\ngot:\n%s", fixtout, stdout)
+	}
+
+	// test for error on missing files
+	if status, _, _ := testRun(t, "", "-in", "pcap", filepath.Join(t.TempDir(), "missing.pcap")); status != 1 {
+		t.Errorf("expected exit status 1, got %d", status)
+	}
+}
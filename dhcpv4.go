@@ -0,0 +1,190 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+)
+
+var (
+	errDHCPv4MessageTooShort = errors.New("DHCPv4 message too short")
+	errDHCPv4InvalidCookie   = errors.New("invalid DHCPv4 magic cookie")
+	errDHCPv4OptionTooLong   = errors.New("DHCPv4 option too long")
+	errDHCPv4InvalidField    = errors.New("invalid DHCPv4 field")
+
+	dhcpv4MagicCookie = []byte{99, 130, 83, 99}
+)
+
+// DHCPv4 option codes with special meaning as described at
+// https://tools.ietf.org/html/rfc2132#section-3
+const (
+	dhcpv4OptionPad uint8 = 0
+	dhcpv4OptionEnd uint8 = 255
+)
+
+// DHCPv4 option codes as described at https://tools.ietf.org/html/rfc2132,
+// https://tools.ietf.org/html/rfc4361#section-6.1 and
+// https://tools.ietf.org/html/rfc4702#section-2
+const (
+	dhcpv4OptionSubnetMask     uint8 = 1
+	dhcpv4OptionRouter         uint8 = 3
+	dhcpv4OptionDNSServer      uint8 = 6
+	dhcpv4OptionHostName       uint8 = 12
+	dhcpv4OptionDomainName     uint8 = 15
+	dhcpv4OptionRequestedAddr  uint8 = 50
+	dhcpv4OptionLeaseTime      uint8 = 51
+	dhcpv4OptionOverload       uint8 = 52
+	dhcpv4OptionMessageType    uint8 = 53
+	dhcpv4OptionServerID       uint8 = 54
+	dhcpv4OptionParameterList  uint8 = 55
+	dhcpv4OptionMaxMessageSize uint8 = 57
+	dhcpv4OptionRenewalTime    uint8 = 58
+	dhcpv4OptionRebindingTime  uint8 = 59
+	dhcpv4OptionVendorClassID  uint8 = 60
+	dhcpv4OptionClientID       uint8 = 61
+	dhcpv4OptionClientFQDN     uint8 = 81
+)
+
+// client identifier types with special meaning as described at
+// https://tools.ietf.org/html/rfc2132#section-9.14 and
+// https://tools.ietf.org/html/rfc4361#section-6.1
+const (
+	dhcpv4ClientIDTypeOpaque uint8 = 0
+	dhcpv4ClientIDTypeDUID   uint8 = 255
+)
+
+// flag of the Client FQDN option telling its domain name is in DNS wire
+// format, as described at https://tools.ietf.org/html/rfc4702#section-2.1
+const dhcpv4FQDNFlagE uint8 = 0x04
+
+// DHCPv4Option represents a single option of a DHCPv4 message
+type DHCPv4Option struct {
+	Code uint8
+	Data []byte
+}
+
+// DHCPv4Message represents a DHCPv4 message as described at
+// https://tools.ietf.org/html/rfc2131#section-2, as it is carried by the
+// DHCPv4 Message option of DHCPv4-over-DHCPv6
+type DHCPv4Message struct {
+	Op                 uint8
+	HardwareType       uint8
+	Hops               uint8
+	Xid                uint32
+	Secs               uint16
+	Flags              uint16
+	ClientAddr         net.IP
+	YourAddr           net.IP
+	ServerAddr         net.IP
+	GatewayAddr        net.IP
+	ClientHardwareAddr net.HardwareAddr
+	ServerName         string
+	File               string
+	Options            []DHCPv4Option
+}
+
+func (m DHCPv4Message) String() string {
+	return fmt.Sprintf("DHCPv4 op %d xid %#08x chaddr %s ciaddr %s yiaddr %s",
+		m.Op, m.Xid, m.ClientHardwareAddr, m.ClientAddr, m.YourAddr)
+}
+
+// Marshal returns byte slice representing this DHCPv4Message
+func (m DHCPv4Message) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	// the magic cookie and options are appended later
+	b := make([]byte, 236)
+	b[0] = m.Op
+	b[1] = m.HardwareType
+	b[3] = m.Hops
+	binary.BigEndian.PutUint32(b[4:8], m.Xid)
+	binary.BigEndian.PutUint16(b[8:10], m.Secs)
+	binary.BigEndian.PutUint16(b[10:12], m.Flags)
+	// set addresses, leaving unset ones zero
+	for i, addr := range []net.IP{m.ClientAddr, m.YourAddr, m.ServerAddr, m.GatewayAddr} {
+		if addr == nil {
+			continue
+		}
+		if addr.To4() == nil {
+			return nil, errDHCPv4InvalidField
+		}
+		copy(b[12+(i*4):16+(i*4)], addr.To4())
+	}
+	// set client hardware address and its length
+	if len(m.ClientHardwareAddr) > 16 || len(m.ServerName) > 64 || len(m.File) > 128 {
+		return nil, errDHCPv4InvalidField
+	}
+	b[2] = uint8(len(m.ClientHardwareAddr))
+	copy(b[28:44], m.ClientHardwareAddr)
+	copy(b[44:108], m.ServerName)
+	copy(b[108:236], m.File)
+	// append magic cookie and options
+	b = append(b, dhcpv4MagicCookie...)
+	for _, opt := range m.Options {
+		if len(opt.Data) > 255 {
+			return nil, errDHCPv4OptionTooLong
+		}
+		b = append(b, opt.Code, uint8(len(opt.Data)))
+		b = append(b, opt.Data...)
+	}
+	b = append(b, dhcpv4OptionEnd)
+
+	return b, nil
+}
+
+// DecodeDHCPv4Message takes DHCPv4 message bytes and tries to decode the
+// message and its options
+func DecodeDHCPv4Message(data []byte) (*DHCPv4Message, error) {
+	// the fixed part of the message and the magic cookie take 240 bytes
+	if len(data) < 240 {
+		return nil, errDHCPv4MessageTooShort
+	}
+	if !bytes.Equal(data[236:240], dhcpv4MagicCookie) {
+		return nil, errDHCPv4InvalidCookie
+	}
+
+	hlen := int(data[2])
+	if hlen > 16 {
+		return nil, errDHCPv4InvalidField
+	}
+
+	m := &DHCPv4Message{
+		Op:                 data[0],
+		HardwareType:       data[1],
+		Hops:               data[3],
+		Xid:                binary.BigEndian.Uint32(data[4:8]),
+		Secs:               binary.BigEndian.Uint16(data[8:10]),
+		Flags:              binary.BigEndian.Uint16(data[10:12]),
+		ClientAddr:         net.IP(data[12:16]),
+		YourAddr:           net.IP(data[16:20]),
+		ServerAddr:         net.IP(data[20:24]),
+		GatewayAddr:        net.IP(data[24:28]),
+		ClientHardwareAddr: net.HardwareAddr(data[28 : 28+hlen]),
+		ServerName:         string(bytes.TrimRight(data[44:108], "\x00")),
+		File:               string(bytes.TrimRight(data[108:236], "\x00")),
+	}
+
+	// decode options until the end option or the end of the data
+	data = data[240:]
+	for len(data) > 0 {
+		code := data[0]
+		if code == dhcpv4OptionEnd {
+			break
+		}
+		if code == dhcpv4OptionPad {
+			data = data[1:]
+			continue
+		}
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			return nil, errDHCPv4MessageTooShort
+		}
+		m.Options = append(m.Options, DHCPv4Option{
+			Code: code,
+			Data: data[2 : 2+int(data[1])],
+		})
+		data = data[2+int(data[1]):]
+	}
+
+	return m, nil
+}
@@ -0,0 +1,93 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func TestDHCPv4Message(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	msg := &DHCPv4Message{
+		Op:                 1,
+		HardwareType:       1,
+		Xid:                0xdeadbeef,
+		Flags:              0x8000,
+		ClientAddr:         net.ParseIP("192.0.2.10"),
+		ClientHardwareAddr: fixtmac,
+		ServerName:         "server",
+		Options: []DHCPv4Option{
+			{Code: 53, Data: []byte{1}},
+			{Code: 61, Data: []byte{1, 170, 187, 204, 221, 238, 255}},
+		},
+	}
+
+	mshByte, err := msg.Marshal()
+	if err != nil {
+		t.Fatalf("error marshalling DHCPv4 message: %s", err)
+	}
+
+	// fixed part, magic cookie, options and end option
+	fixtlen := 240 + 3 + 9 + 1
+	if len(mshByte) != fixtlen {
+		t.Errorf("expected length %d, got %d", fixtlen, len(mshByte))
+	}
+	if mshByte[2] != 6 {
+		t.Errorf("expected hardware length 6, got %d", mshByte[2])
+	}
+	if !bytes.Equal(mshByte[236:240], dhcpv4MagicCookie) {
+		t.Errorf("expected magic cookie, got %v", mshByte[236:240])
+	}
+
+	// decode marshalled message and compare
+	dec, err := DecodeDHCPv4Message(mshByte)
+	if err != nil {
+		t.Fatalf("could not decode DHCPv4 message: %s", err)
+	}
+	if dec.Xid != msg.Xid || dec.Flags != msg.Flags || dec.Op != msg.Op {
+		t.Errorf("decoded header didn't match: %s", dec)
+	}
+	if !dec.ClientAddr.Equal(msg.ClientAddr) {
+		t.Errorf("expected ciaddr %s, got %s", msg.ClientAddr, dec.ClientAddr)
+	}
+	if !bytes.Equal(dec.ClientHardwareAddr, fixtmac) {
+		t.Errorf("expected chaddr %s, got %s", fixtmac, dec.ClientHardwareAddr)
+	}
+	if dec.ServerName != "server" || dec.File != "" {
+		t.Errorf("unexpected sname %q or file %q", dec.ServerName, dec.File)
+	}
+	if len(dec.Options) != 2 || dec.Options[1].Code != 61 || !bytes.Equal(dec.Options[1].Data, msg.Options[1].Data) {
+		t.Errorf("unexpected options: %v", dec.Options)
+	}
+
+	// test matching output for String()
+	fixtstr := "DHCPv4 op 1 xid 0xdeadbeef chaddr aa:bb:cc:dd:ee:ff ciaddr 192.0.2.10 yiaddr 0.0.0.0"
+	if fixtstr != dec.String() {
+		t.Errorf("unexpected String() output: %s", dec.String())
+	}
+
+	// padding should be skipped
+	padded := append(append([]byte{}, mshByte[:240]...), 0, 0, 53, 1, 3, 255)
+	if dec, err := DecodeDHCPv4Message(padded); err != nil {
+		t.Errorf("could not decode padded DHCPv4 message: %s", err)
+	} else if len(dec.Options) != 1 || dec.Options[0].Code != 53 {
+		t.Errorf("unexpected options: %v", dec.Options)
+	}
+
+	// error cases
+	if _, err := DecodeDHCPv4Message(mshByte[:239]); err != errDHCPv4MessageTooShort {
+		t.Errorf("expected message too short error, got %v", err)
+	}
+	if _, err := DecodeDHCPv4Message(mshByte[:245]); err != errDHCPv4MessageTooShort {
+		t.Errorf("expected message too short error for truncated option, got %v", err)
+	}
+	mshByte[236] = 0
+	if _, err := DecodeDHCPv4Message(mshByte); err != errDHCPv4InvalidCookie {
+		t.Errorf("expected invalid cookie error, got %v", err)
+	}
+
+	msg.ClientAddr = net.ParseIP("2001:db8::1")
+	if _, err := msg.Marshal(); err != errDHCPv4InvalidField {
+		t.Errorf("expected invalid field error, got %v", err)
+	}
+}
@@ -1,22 +1,42 @@
 package dhcpv6
 
 import (
+	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"net"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 var (
-	errDUIDTooShort      = errors.New("duid too short")
-	thirtyYearsInSeconds = uint32(946771200)
+	errDUIDTooShort     = errors.New("duid too short")
+	errDUIDTooLong      = errors.New("duid too long")
+	errInvalidDUIDTime  = errors.New("time not representable as DUID time")
+	errInvalidMachineID = errors.New("invalid client machine identifier")
 )
 
+// as stated in RFC8415, DUID epoch is at Jan 1st 2000 (UTC)
+// ErrInvalidLinkLayerAddress is returned when the length of a link-layer
+// address does not match its hardware type. DecodeDUID returns it along with
+// the decoded DUID-LLT or DUID-LL, since such a DUID still identifies a client
+var ErrInvalidLinkLayerAddress = errors.New("link-layer address length does not match hardware type")
+
+var duidEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// maximum length of a DUID including its type as described at
+// https://tools.ietf.org/html/rfc8415#section-11.1
+const maxDUIDLen = 130
+
 // DUIDType represents the type of DUID
-type DUIDType uint8
+type DUIDType uint16
 
 func (d DUIDType) String() string {
 	switch d {
@@ -53,19 +73,219 @@ type DUID interface {
 	Marshal() ([]byte, error)
 }
 
+// DUIDKey is a comparable representation of a DUID, so DUIDs can be used as
+// map keys. It contains the DUID's wire bytes
+type DUIDKey string
+
+// NewDUIDKey returns the DUIDKey for given DUID or error if it could not be
+// marshalled. An invalid DUID has no key, since it has no wire bytes
+func NewDUIDKey(d DUID) (DUIDKey, error) {
+	b, err := d.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return DUIDKey(b), nil
+}
+
+// DUID decodes the DUID this DUIDKey represents
+func (k DUIDKey) DUID() (DUID, error) {
+	return DecodeDUID([]byte(k))
+}
+
+func (k DUIDKey) String() string {
+	return formatHex([]byte(k), ":")
+}
+
+// EqualDUIDs returns true if a and b are the same DUID. As described at
+// https://tools.ietf.org/html/rfc8415#section-11, DUIDs are opaque values that
+// are equal when they are byte-wise identical. DUIDs that can't be marshalled
+// are compared field by field instead, so an invalid DUID is still equal to
+// itself. A nil DUID is never equal to anything
+func EqualDUIDs(a, b DUID) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	ab, aerr := a.Marshal()
+	bb, berr := b.Marshal()
+	if aerr != nil || berr != nil {
+		return fieldsEqual(a, b)
+	}
+
+	return bytes.Equal(ab, bb)
+}
+
+// helper function to compare the fields of a and b, regardless of either of
+// them being a pointer
+func fieldsEqual(a, b any) bool {
+	return reflect.DeepEqual(reflect.Indirect(reflect.ValueOf(a)).Interface(),
+		reflect.Indirect(reflect.ValueOf(b)).Interface())
+}
+
+// CloneDUID returns a deep copy of d, sharing no memory with d, or nil if d is
+// nil. All DUIDs of this package have a Clone method for this; DUIDs
+// implemented elsewhere without a Clone() DUID method are returned as is
+func CloneDUID(d DUID) DUID {
+	if c, ok := d.(interface{ Clone() DUID }); ok {
+		return c.Clone()
+	}
+
+	return d
+}
+
+// HardwareType represents the hardware type of a link-layer address
+type HardwareType uint16
+
+// Hardware types as registered at
+// https://www.iana.org/assignments/arp-parameters/arp-parameters.xhtml#arp-parameters-2
+const (
+	HardwareTypeEthernet             HardwareType = 1
+	HardwareTypeExperimentalEthernet HardwareType = 2
+	HardwareTypeAX25                 HardwareType = 3
+	HardwareTypeProteonTokenRing     HardwareType = 4
+	HardwareTypeChaos                HardwareType = 5
+	HardwareTypeIEEE802              HardwareType = 6
+	HardwareTypeARCNET               HardwareType = 7
+	HardwareTypeFrameRelay           HardwareType = 15
+	HardwareTypeATM                  HardwareType = 16
+	HardwareTypeHDLC                 HardwareType = 17
+	HardwareTypeFibreChannel         HardwareType = 18
+	HardwareTypeSerialLine           HardwareType = 20
+	HardwareTypeIEEE1394             HardwareType = 24
+	HardwareTypeEUI64                HardwareType = 27
+	HardwareTypeInfiniBand           HardwareType = 32
+)
+
+func (h HardwareType) String() string {
+	name := func() string {
+		switch h {
+		case HardwareTypeEthernet:
+			return "Ethernet"
+		case HardwareTypeExperimentalEthernet:
+			return "Experimental Ethernet"
+		case HardwareTypeAX25:
+			return "AX.25"
+		case HardwareTypeProteonTokenRing:
+			return "Proteon ProNET Token Ring"
+		case HardwareTypeChaos:
+			return "Chaos"
+		case HardwareTypeIEEE802:
+			return "IEEE 802"
+		case HardwareTypeARCNET:
+			return "ARCNET"
+		case HardwareTypeFrameRelay:
+			return "Frame Relay"
+		case HardwareTypeATM:
+			return "ATM"
+		case HardwareTypeHDLC:
+			return "HDLC"
+		case HardwareTypeFibreChannel:
+			return "Fibre Channel"
+		case HardwareTypeSerialLine:
+			return "Serial Line"
+		case HardwareTypeIEEE1394:
+			return "IEEE 1394"
+		case HardwareTypeEUI64:
+			return "EUI-64"
+		case HardwareTypeInfiniBand:
+			return "InfiniBand"
+		default:
+			return typeUnknown
+		}
+	}
+
+	return fmt.Sprintf("%s (%d)", name(), h)
+}
+
+// AddressLen returns the length in bytes of link-layer addresses of this
+// hardware type, or 0 if the length is variable or unknown
+func (h HardwareType) AddressLen() int {
+	switch h {
+	case HardwareTypeEthernet, HardwareTypeIEEE802:
+		return 6
+	case HardwareTypeExperimentalEthernet, HardwareTypeARCNET:
+		return 1
+	case HardwareTypeIEEE1394, HardwareTypeEUI64:
+		return 8
+	case HardwareTypeInfiniBand:
+		return 20
+	default:
+		return 0
+	}
+}
+
+// ValidateAddress returns an error if the length of given link-layer address
+// does not match this hardware type
+func (h HardwareType) ValidateAddress(addr net.HardwareAddr) error {
+	if l := h.AddressLen(); l > 0 && len(addr) != l {
+		return ErrInvalidLinkLayerAddress
+	}
+
+	return nil
+}
+
+// DUIDTime represents the time field of a DUID-LLT: the number of seconds
+// since midnight (UTC), January 1, 2000, modulo 2^32 as described at
+// https://tools.ietf.org/html/rfc8415#section-11.2
+type DUIDTime uint32
+
+// NewDUIDTime converts given time to a DUIDTime, truncating it to whole
+// seconds. The zero time, as in a DUIDLLT without time, converts to DUIDTime 0.
+// Other times before the DUID epoch or more than 2^32-1 seconds after it
+// cannot be represented and return an error
+func NewDUIDTime(t time.Time) (DUIDTime, error) {
+	if t.IsZero() {
+		return 0, nil
+	}
+
+	secs := t.Unix() - duidEpoch.Unix()
+	if secs < 0 || secs > math.MaxUint32 {
+		return 0, errInvalidDUIDTime
+	}
+
+	return DUIDTime(secs), nil
+}
+
+// Time returns the time represented by this DUIDTime in UTC
+func (d DUIDTime) Time() time.Time {
+	return duidEpoch.Add(time.Duration(d) * time.Second)
+}
+
+func (d DUIDTime) String() string {
+	return fmt.Sprintf("%d", uint32(d))
+}
+
 // DUIDLLT - as described in https://tools.ietf.org/html/rfc3315#section-9.2
 type DUIDLLT struct {
-	HardwareType     uint16
+	HardwareType     HardwareType
 	Time             time.Time
 	LinkLayerAddress net.HardwareAddr
 }
 
+// NewDUIDLLT returns a DUIDLLT for given hardware type, time and link-layer
+// address or error if the address doesn't match the hardware type or the time
+// can't be represented
+func NewDUIDLLT(hwtype HardwareType, t time.Time, addr net.HardwareAddr) (*DUIDLLT, error) {
+	if err := hwtype.ValidateAddress(addr); err != nil {
+		return nil, err
+	}
+	if _, err := NewDUIDTime(t); err != nil {
+		return nil, err
+	}
+
+	return &DUIDLLT{
+		HardwareType:     hwtype,
+		Time:             t,
+		LinkLayerAddress: addr,
+	}, nil
+}
+
 func (d DUIDLLT) String() string {
-	output := fmt.Sprintf("hwaddr/time type %d", d.Type())
+	output := fmt.Sprintf("hwaddr/time type %d hwtype %s", d.Type(), d.HardwareType)
 
-	if !d.Time.IsZero() {
-		// subtract 30 year offset from time
-		output += fmt.Sprintf(" time %d", d.Time.Unix()-int64(thirtyYearsInSeconds))
+	if t, err := NewDUIDTime(d.Time); err == nil {
+		output += fmt.Sprintf(" time %s", t)
 	}
 
 	output += fmt.Sprintf(" %v", d.LinkLayerAddress)
@@ -85,6 +305,11 @@ func (d DUIDLLT) Type() DUIDType {
 
 // Marshal returns byte slice representing this DUIDLLT
 func (d DUIDLLT) Marshal() ([]byte, error) {
+	t, err := NewDUIDTime(d.Time)
+	if err != nil {
+		return nil, err
+	}
+
 	// prepare byte slice of appropriate length
 	// LinkLayerAddress will be appended later
 	b := make([]byte, 8) // type, hwtype, time
@@ -93,27 +318,127 @@ func (d DUIDLLT) Marshal() ([]byte, error) {
 	binary.BigEndian.PutUint16(b[0:2], uint16(DUIDTypeLLT))
 	// set hw type
 	binary.BigEndian.PutUint16(b[2:4], uint16(d.HardwareType))
-	// set time (subtract 30 years offset)
-	binary.BigEndian.PutUint32(b[4:8], uint32(d.Time.Unix()-int64(thirtyYearsInSeconds)))
+	// set time
+	binary.BigEndian.PutUint32(b[4:8], uint32(t))
 	// append LinkLayerAddress
 	b = append(b, d.LinkLayerAddress...)
 	return b, nil
 }
 
+// Clone returns a deep copy of this DUIDLLT
+func (d DUIDLLT) Clone() DUID {
+	d.LinkLayerAddress = append(net.HardwareAddr(nil), d.LinkLayerAddress...)
+
+	return &d
+}
+
+// EnterpriseNumber represents an IANA Private Enterprise Number as used in
+// DUID-EN and several options
+type EnterpriseNumber uint32
+
+// Well-known enterprise numbers as registered at
+// https://www.iana.org/assignments/enterprise-numbers
+const (
+	EnterpriseNumberIBM                       EnterpriseNumber = 2
+	EnterpriseNumberCisco                     EnterpriseNumber = 9
+	EnterpriseNumberHP                        EnterpriseNumber = 11
+	EnterpriseNumberEricsson                  EnterpriseNumber = 193
+	EnterpriseNumberMicrosoft                 EnterpriseNumber = 311
+	EnterpriseNumberHuawei                    EnterpriseNumber = 2011
+	EnterpriseNumberInternetSystemsConsortium EnterpriseNumber = 2495
+	EnterpriseNumberJuniper                   EnterpriseNumber = 2636
+	EnterpriseNumberBroadbandForum            EnterpriseNumber = 3561
+	EnterpriseNumberBroadcom                  EnterpriseNumber = 4413
+	EnterpriseNumberCableLabs                 EnterpriseNumber = 4491
+	EnterpriseNumberNokia                     EnterpriseNumber = 6527
+	EnterpriseNumberMikroTik                  EnterpriseNumber = 14988
+	EnterpriseNumberExample                   EnterpriseNumber = 32473
+)
+
+func (e EnterpriseNumber) String() string {
+	name := func() string {
+		switch e {
+		case EnterpriseNumberIBM:
+			return "IBM"
+		case EnterpriseNumberCisco:
+			return "Cisco Systems"
+		case EnterpriseNumberHP:
+			return "Hewlett-Packard"
+		case EnterpriseNumberEricsson:
+			return "Ericsson"
+		case EnterpriseNumberMicrosoft:
+			return "Microsoft"
+		case EnterpriseNumberHuawei:
+			return "Huawei"
+		case EnterpriseNumberInternetSystemsConsortium:
+			return "Internet Systems Consortium"
+		case EnterpriseNumberJuniper:
+			return "Juniper Networks"
+		case EnterpriseNumberBroadbandForum:
+			return "Broadband Forum"
+		case EnterpriseNumberBroadcom:
+			return "Broadcom"
+		case EnterpriseNumberCableLabs:
+			return "CableLabs"
+		case EnterpriseNumberNokia:
+			return "Nokia"
+		case EnterpriseNumberMikroTik:
+			return "MikroTik"
+		case EnterpriseNumberExample:
+			return "Example (documentation)"
+		default:
+			return typeUnknown
+		}
+	}
+
+	return fmt.Sprintf("%s (%d)", name(), e)
+}
+
 // DUIDEN - as described in https://tools.ietf.org/html/rfc3315#section-9.3
-// NOTE: currently not implemented
 type DUIDEN struct {
 	EnterpriseNumber uint32
 	ID               []byte
 }
 
+// NewDUIDEN returns a DUIDEN for given enterprise number and identifier or
+// error if the identifier is empty or too long to fit in a DUID
+func NewDUIDEN(enterpriseNumber uint32, id []byte) (*DUIDEN, error) {
+	d := &DUIDEN{
+		EnterpriseNumber: enterpriseNumber,
+		ID:               id,
+	}
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// NewDUIDENFromSerial returns a DUIDEN for given enterprise number using given
+// serial number as identifier
+func NewDUIDENFromSerial(enterpriseNumber uint32, serial string) (*DUIDEN, error) {
+	return NewDUIDEN(enterpriseNumber, []byte(serial))
+}
+
+// helper function to check whether the identifier of this DUIDEN is valid
+func (d DUIDEN) validate() error {
+	if len(d.ID) == 0 {
+		return errDUIDTooShort
+	}
+	if d.Len() > maxDUIDLen {
+		return errDUIDTooLong
+	}
+
+	return nil
+}
+
 // Len returns length in bytes for entire DUIDEN
 func (d DUIDEN) Len() uint16 {
 	return 6 + uint16(len(d.ID))
 }
 
 func (d DUIDEN) String() string {
-	return fmt.Sprintf("enterprise number %d (id: %x)", d.EnterpriseNumber, d.ID)
+	return fmt.Sprintf("enterprise number %s id %x", EnterpriseNumber(d.EnterpriseNumber), d.ID)
 }
 
 // Type returns DUIDTypeEN
@@ -121,7 +446,12 @@ func (DUIDEN) Type() DUIDType {
 	return DUIDTypeEN
 }
 
+// Marshal returns byte slice representing this DUIDEN
 func (d DUIDEN) Marshal() ([]byte, error) {
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
 	// prepare byte slice of appropriate length
 	// ID will be appended later
 	b := make([]byte, 6) // type, enterprise number
@@ -135,14 +465,34 @@ func (d DUIDEN) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this DUIDEN
+func (d DUIDEN) Clone() DUID {
+	d.ID = append([]byte(nil), d.ID...)
+
+	return &d
+}
+
 // DUIDLL - as described in https://tools.ietf.org/html/rfc3315#section-9.4
 type DUIDLL struct {
-	HardwareType     uint16
+	HardwareType     HardwareType
 	LinkLayerAddress net.HardwareAddr
 }
 
+// NewDUIDLL returns a DUIDLL for given hardware type and link-layer address or
+// error if the address doesn't match the hardware type
+func NewDUIDLL(hwtype HardwareType, addr net.HardwareAddr) (*DUIDLL, error) {
+	if err := hwtype.ValidateAddress(addr); err != nil {
+		return nil, err
+	}
+
+	return &DUIDLL{
+		HardwareType:     hwtype,
+		LinkLayerAddress: addr,
+	}, nil
+}
+
 func (d DUIDLL) String() string {
-	return fmt.Sprintf("hwaddr type %d %v", d.Type(), d.LinkLayerAddress)
+	return fmt.Sprintf("hwaddr type %d hwtype %s %v", d.Type(), d.HardwareType, d.LinkLayerAddress)
 }
 
 // Len returns length in bytes for entire DUIDLL
@@ -170,13 +520,56 @@ func (d DUIDLL) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this DUIDLL
+func (d DUIDLL) Clone() DUID {
+	d.LinkLayerAddress = append(net.HardwareAddr(nil), d.LinkLayerAddress...)
+
+	return &d
+}
+
 // DUIDUUID as described in https://tools.ietf.org/html/rfc6355#section-4
 type DUIDUUID struct {
 	UUID uuid.UUID
 }
 
+// NewDUIDUUIDFromString returns a DUIDUUID for given UUID in its textual
+// representation, such as 7e66eaa2-e6dd-497b-8e21-31944b282b43
+func NewDUIDUUIDFromString(s string) (*DUIDUUID, error) {
+	u, err := uuid.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DUIDUUID{UUID: u}, nil
+}
+
+// NewDUIDUUIDFromMachineID returns a DUIDUUID for the Client Machine
+// Identifier data a PXE client sends as described at
+// https://tools.ietf.org/html/rfc4578#section-2.3
+func NewDUIDUUIDFromMachineID(data []byte) (*DUIDUUID, error) {
+	// the identifier consists of type 0 followed by a 16 byte UUID
+	if len(data) != 17 || data[0] != 0 {
+		return nil, errInvalidMachineID
+	}
+
+	d := &DUIDUUID{}
+	copy(d.UUID[:], data[1:17])
+
+	return d, nil
+}
+
 func (d DUIDUUID) String() string {
-	return fmt.Sprintf("type %d", d.Type())
+	return fmt.Sprintf("uuid type %d %s", d.Type(), d.UUID)
+}
+
+// MachineID returns the Client Machine Identifier data for this DUIDUUID. As
+// described at https://tools.ietf.org/html/rfc6355#section-4 a PXE client
+// uses the same UUID in its DUID-UUID as in its Client Machine Identifier
+func (d DUIDUUID) MachineID() []byte {
+	b := make([]byte, 17)
+	copy(b[1:17], d.UUID[:])
+
+	return b
 }
 
 // Len returns length in bytes for the entire DUIDUUID
@@ -206,10 +599,70 @@ func (d DUIDUUID) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this DUIDUUID
+func (d DUIDUUID) Clone() DUID {
+	return &d
+}
+
+// DUIDOpaque holds a DUID of a type that is not handled by this package. As
+// described at https://tools.ietf.org/html/rfc8415#section-11 DUIDs are
+// opaque values, so they can still be compared and marshalled
+type DUIDOpaque struct {
+	// Data contains the entire DUID, including its type
+	Data []byte
+}
+
+func (d DUIDOpaque) String() string {
+	if len(d.Data) < 2 {
+		return fmt.Sprintf("opaque %x", d.Data)
+	}
+
+	return fmt.Sprintf("opaque type %d %x", d.Type(), d.Data[2:])
+}
+
+// Len returns length in bytes for the entire DUIDOpaque
+func (d DUIDOpaque) Len() uint16 {
+	return uint16(len(d.Data))
+}
+
+// Type returns the type as found in the DUID
+func (d DUIDOpaque) Type() DUIDType {
+	if len(d.Data) < 2 {
+		return 0
+	}
+
+	return DUIDType(binary.BigEndian.Uint16(d.Data[0:2]))
+}
+
+// Marshal returns byte slice representing this DUIDOpaque
+func (d DUIDOpaque) Marshal() ([]byte, error) {
+	if len(d.Data) < 2 {
+		return nil, errDUIDTooShort
+	}
+	if len(d.Data) > maxDUIDLen {
+		return nil, errDUIDTooLong
+	}
+
+	b := make([]byte, len(d.Data))
+	copy(b, d.Data)
+	return b, nil
+}
+
+// Clone returns a deep copy of this DUIDOpaque
+func (d DUIDOpaque) Clone() DUID {
+	d.Data = append([]byte(nil), d.Data...)
+
+	return &d
+}
+
 // DecodeDUID tries to decode given byte slice to one of the defined
-// DUIDTypes
+// DUIDTypes. DUIDs of any other type are decoded to DUIDOpaque. A DUID-LLT or
+// DUID-LL with a link-layer address that doesn't match the length of its
+// hardware type is returned along with ErrInvalidLinkLayerAddress: DUIDs are
+// opaque to servers, so such a DUID still identifies the client
 func DecodeDUID(data []byte) (DUID, error) {
 	var currentDUID DUID
+	var err error
 
 	// type is defined in the first 2 bytes
 	if len(data) < 2 {
@@ -222,17 +675,15 @@ func DecodeDUID(data []byte) (DUID, error) {
 	case DUIDTypeLLT:
 		// DUID-LLTs should be at least 8 bytes
 		// containing hardware type, time
-		// the link layer address is variable in length, but here a regular MAC
-		// address is assumed
+		// the link layer address is variable in length, but should match the
+		// hardware type when it is known
 		if len(data) < 8 {
 			return currentDUID, errDUIDTooShort
 		}
+		err = HardwareType(binary.BigEndian.Uint16(data[2:4])).ValidateAddress(data[8:])
 		currentDUID = &DUIDLLT{
-			HardwareType: binary.BigEndian.Uint16(data[2:4]),
-			// as stated in RFC3315, DUID epoch is at Jan 1st 2000 (UTC)
-			// and golang Time works with an epoch at Jan 1st 1970 (UTC)
-			// I'm adding 30 years of seconds to the uint32 we decode
-			Time: time.Unix(int64(binary.BigEndian.Uint32(data[4:8])+thirtyYearsInSeconds), 0),
+			HardwareType: HardwareType(binary.BigEndian.Uint16(data[2:4])),
+			Time:         DUIDTime(binary.BigEndian.Uint32(data[4:8])).Time(),
 		}
 		if len(data) > 8 {
 			currentDUID.(*DUIDLLT).LinkLayerAddress = data[8:]
@@ -240,13 +691,14 @@ func DecodeDUID(data []byte) (DUID, error) {
 	case DUIDTypeLL:
 		// DUID-LLs should be at least 4 bytes
 		// containing hardware type
-		// the link layer address is variable in length, but here a regular MAC
-		// address is assumed
+		// the link layer address is variable in length, but should match the
+		// hardware type when it is known
 		if len(data) < 4 {
 			return currentDUID, errDUIDTooShort
 		}
+		err = HardwareType(binary.BigEndian.Uint16(data[2:4])).ValidateAddress(data[4:])
 		currentDUID = &DUIDLL{
-			HardwareType: binary.BigEndian.Uint16(data[2:4]),
+			HardwareType: HardwareType(binary.BigEndian.Uint16(data[2:4])),
 		}
 		if len(data) > 4 {
 			currentDUID.(*DUIDLL).LinkLayerAddress = data[4:]
@@ -262,14 +714,168 @@ func DecodeDUID(data []byte) (DUID, error) {
 			return currentDUID, err
 		}
 	case DUIDTypeEN:
+		// DUID-ENs should be at least 7 bytes
+		// containing enterprise number and an identifier of at least 1 byte
+		if len(data) < 7 {
+			return currentDUID, errDUIDTooShort
+		}
+		if len(data) > maxDUIDLen {
+			return currentDUID, errDUIDTooLong
+		}
 		currentDUID = &DUIDEN{
 			EnterpriseNumber: binary.BigEndian.Uint32(data[2:6]),
 			ID:               data[6:],
 		}
 
 	default:
-		return currentDUID, fmt.Errorf("unhandled DUIDType %s", duidType)
+		if len(data) > maxDUIDLen {
+			return currentDUID, errDUIDTooLong
+		}
+		currentDUID = &DUIDOpaque{Data: data}
+	}
+
+	return currentDUID, err
+}
+
+// DUIDFormat describes a textual notation of DUIDs
+type DUIDFormat uint8
+
+// DUID notations as used by common tools
+const (
+	// colon separated lowercase hex, as used by Kea and systemd-networkd
+	DUIDFormatColon DUIDFormat = iota
+	// plain lowercase hex
+	DUIDFormatHex
+	// dash separated uppercase hex, as printed by Windows' ipconfig
+	DUIDFormatWindows
+	// quoted string with octal escapes, as used in ISC dhclient lease files
+	DUIDFormatOctal
+)
+
+// FormatDUID returns given DUID in the textual notation described by f
+func FormatDUID(d DUID, f DUIDFormat) (string, error) {
+	b, err := d.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	switch f {
+	case DUIDFormatColon:
+		return formatHex(b, ":"), nil
+	case DUIDFormatHex:
+		return hex.EncodeToString(b), nil
+	case DUIDFormatWindows:
+		return strings.ToUpper(formatHex(b, "-")), nil
+	case DUIDFormatOctal:
+		var sb strings.Builder
+		sb.WriteByte('"')
+		for _, c := range b {
+			switch {
+			case c == '"' || c == '\\':
+				sb.WriteByte('\\')
+				sb.WriteByte(c)
+			case c >= 0x20 && c < 0x7f:
+				sb.WriteByte(c)
+			default:
+				fmt.Fprintf(&sb, "\\%03o", c)
+			}
+		}
+		sb.WriteByte('"')
+		return sb.String(), nil
+	default:
+		return "", fmt.Errorf("unhandled DUIDFormat %d", f)
+	}
+}
+
+// ParseDUID parses a DUID in any of the notations described by DUIDFormat and
+// returns the decoded DUID. Separated hex notations may omit leading zeroes
+// and plain hex may be prefixed by 0x
+func ParseDUID(s string) (DUID, error) {
+	s = strings.TrimSpace(s)
+
+	var (
+		b   []byte
+		err error
+	)
+	switch {
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		b, err = parseOctalDUID(s[1 : len(s)-1])
+	case strings.Contains(s, `\`):
+		b, err = parseOctalDUID(s)
+	case strings.ContainsAny(s, ":-"):
+		b, err = parseSeparatedDUID(s)
+	default:
+		b, err = hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse DUID %q: %s", s, err)
+	}
+
+	return DecodeDUID(b)
+}
+
+// helper function to parse hex bytes separated by either colons or dashes
+func parseSeparatedDUID(s string) ([]byte, error) {
+	sep := ":"
+	if !strings.Contains(s, sep) {
+		sep = "-"
+	}
+
+	parts := strings.Split(s, sep)
+	b := make([]byte, len(parts))
+	for i, part := range parts {
+		if len(part) == 0 || len(part) > 2 {
+			return nil, fmt.Errorf("invalid byte %q", part)
+		}
+		v, err := strconv.ParseUint(part, 16, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte %q", part)
+		}
+		b[i] = uint8(v)
+	}
+
+	return b, nil
+}
+
+// helper function to parse a string with octal escapes as written by ISC
+// dhclient
+func parseOctalDUID(s string) ([]byte, error) {
+	b := []byte{}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b = append(b, s[i])
+			continue
+		}
+
+		// escaped character or 3 digit octal value
+		if i+1 >= len(s) {
+			return nil, errors.New("trailing backslash")
+		}
+		if s[i+1] < '0' || s[i+1] > '7' {
+			b = append(b, s[i+1])
+			i++
+			continue
+		}
+		if i+4 > len(s) {
+			return nil, fmt.Errorf("invalid octal escape %q", s[i:])
+		}
+		v, err := strconv.ParseUint(s[i+1:i+4], 8, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid octal escape %q", s[i:i+4])
+		}
+		b = append(b, uint8(v))
+		i += 3
+	}
+
+	return b, nil
+}
+
+// helper function to format bytes as hex string, separating bytes by sep
+func formatHex(b []byte, sep string) string {
+	parts := make([]string, len(b))
+	for i := range b {
+		parts[i] = hex.EncodeToString(b[i : i+1])
 	}
 
-	return currentDUID, nil
+	return strings.Join(parts, sep)
 }
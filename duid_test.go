@@ -2,7 +2,9 @@ package dhcpv6
 
 import (
 	"bytes"
+	"encoding/binary"
 	"net"
+	"reflect"
 	"testing"
 	"time"
 
@@ -36,14 +38,56 @@ func TestDUIDDecode(t *testing.T) {
 		t.Errorf("unexpected error: %s", err)
 	}
 
-	// test decoding unknown DUIDType
-	if _, err := DecodeDUID([]byte{0, 255}); err == nil {
-		t.Error("expected error while decoding unknown DUIDType")
-	} else if err.Error() != "unhandled DUIDType Unknown" {
+	// test decoding too many bytes for an unknown DUIDType
+	if _, err := DecodeDUID(append([]byte{0, 255}, make([]byte, 129)...)); err == nil {
+		t.Error("expected error while decoding too long DUID")
+	} else if err != errDUIDTooLong {
 		t.Errorf("unexpected error: %s", err)
 	}
 }
 
+func TestDuidOpaque(t *testing.T) {
+	// test decoding unknown DUIDType, including a type not fitting in 1 byte
+	for _, fixtbyte := range [][]byte{{0, 255}, {1, 2, 222, 173, 190, 239}} {
+		duid, err := DecodeDUID(fixtbyte)
+		if err != nil {
+			t.Errorf("error decoding fixture: %s", err)
+			continue
+		}
+
+		duidopaque := duid.(*DUIDOpaque)
+		// check contents of duid
+		fixttype := DUIDType(uint16(fixtbyte[0])<<8 | uint16(fixtbyte[1]))
+		if duidopaque.Type() != fixttype {
+			t.Errorf("expected duid type %d, got %d", fixttype, duidopaque.Type())
+		}
+
+		// test matching output for Len()
+		if duidopaque.Len() != uint16(len(fixtbyte)) {
+			t.Errorf("expected Len of %d, got %d", len(fixtbyte), duidopaque.Len())
+		}
+
+		// test if marshalled bytes match fixture
+		if mshByte, err := duidopaque.Marshal(); err != nil {
+			t.Errorf("error marshalling DUID: %s", err)
+		} else if !bytes.Equal(fixtbyte, mshByte) {
+			t.Errorf("marshalled DUID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+		}
+	}
+
+	// test matching output for String()
+	duid := &DUIDOpaque{Data: []byte{1, 2, 222, 173, 190, 239}}
+	fixtstr := "opaque type 258 deadbeef"
+	if duid.String() != fixtstr {
+		t.Errorf("unexpected String() output: %s", duid.String())
+	}
+
+	// test marshalling too short DUIDOpaque
+	if _, err := (&DUIDOpaque{Data: []byte{0}}).Marshal(); err != errDUIDTooShort {
+		t.Errorf("expected DUID too short error, got %v", err)
+	}
+}
+
 func TestDuidEN(t *testing.T) {
 	fixtbyte := []byte{0, 2, 0, 0, 0, 9, 12, 192, 132, 221, 3, 0, 9, 18}
 	duid, err := DecodeDUID(fixtbyte)
@@ -62,7 +106,7 @@ func TestDuidEN(t *testing.T) {
 	}
 
 	// test matching output for String()
-	fixtstr := "enterprise number 9 (id: 0cc084dd03000912)"
+	fixtstr := "enterprise number Cisco Systems (9) id 0cc084dd03000912"
 	if duiden.String() != fixtstr {
 		t.Errorf("unexpected String() output: %s", duiden.String())
 	}
@@ -79,6 +123,84 @@ func TestDuidEN(t *testing.T) {
 	} else if !bytes.Equal(fixtbyte, mshByte) {
 		t.Errorf("marshalled DUID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
 	}
+
+	// recreate same struct and see if its marshal matches fixture
+	if duiden, err = NewDUIDEN(fixtenum, fixtbyte[6:]); err != nil {
+		t.Errorf("error creating DUID: %s", err)
+	} else if mshByte, err := duiden.Marshal(); err != nil {
+		t.Errorf("error marshalling DUID: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled DUID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// test for error when decoding too small DUIDEN
+	for _, l := range []int{3, 6} {
+		if _, err := DecodeDUID(fixtbyte[:l]); err == nil {
+			t.Error("expected error decoding too small DUIDEN")
+		} else if err != errDUIDTooShort {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+
+	// test for error when decoding too long DUIDEN
+	if _, err := DecodeDUID(append(fixtbyte, make([]byte, 117)...)); err == nil {
+		t.Error("expected error decoding too long DUIDEN")
+	} else if err != errDUIDTooLong {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestNewDUIDEN(t *testing.T) {
+	duid, err := NewDUIDENFromSerial(uint32(EnterpriseNumberExample), "SN1234")
+	if err != nil {
+		t.Fatalf("error creating DUID: %s", err)
+	}
+
+	fixtbyte := []byte{0, 2, 0, 0, 126, 217, 83, 78, 49, 50, 51, 52}
+	if mshByte, err := duid.Marshal(); err != nil {
+		t.Errorf("error marshalling DUID: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled DUID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	fixtstr := "enterprise number Example (documentation) (32473) id 534e31323334"
+	if duid.String() != fixtstr {
+		t.Errorf("unexpected String() output: %s", duid.String())
+	}
+
+	// identifier should not be empty
+	if _, err := NewDUIDEN(9, nil); err != errDUIDTooShort {
+		t.Errorf("expected DUID too short error, got %v", err)
+	}
+	if _, err := (&DUIDEN{EnterpriseNumber: 9}).Marshal(); err != errDUIDTooShort {
+		t.Errorf("expected DUID too short error, got %v", err)
+	}
+
+	// identifier should fit in the maximum DUID length
+	if _, err := NewDUIDEN(9, make([]byte, 124)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := NewDUIDEN(9, make([]byte, 125)); err != errDUIDTooLong {
+		t.Errorf("expected DUID too long error, got %v", err)
+	}
+}
+
+func TestEnterpriseNumberString(t *testing.T) {
+	tests := []struct {
+		in  EnterpriseNumber
+		out string
+	}{
+		{EnterpriseNumberCisco, "Cisco Systems (9)"},
+		{EnterpriseNumberInternetSystemsConsortium, "Internet Systems Consortium (2495)"},
+		{EnterpriseNumberBroadbandForum, "Broadband Forum (3561)"},
+		{1234567, "Unknown (1234567)"},
+	}
+
+	for _, test := range tests {
+		if test.in.String() != test.out {
+			t.Errorf("expected %s but got %s", test.out, test.in.String())
+		}
+	}
 }
 
 func TestDuidLLT(t *testing.T) {
@@ -94,12 +216,12 @@ func TestDuidLLT(t *testing.T) {
 	if duidllt.Type() != DUIDTypeLLT {
 		t.Errorf("expected duid type %d, got %d", DUIDTypeLLT, duidllt.Type())
 	}
-	fixthwtype := uint16(1)
+	fixthwtype := HardwareTypeEthernet
 	if duidllt.HardwareType != fixthwtype {
 		t.Errorf("expected hw type %d, got %d", fixthwtype, duidllt.HardwareType)
 	}
-	// fixture with offset of 30 years
-	fixttime := time.Unix(1446771200, 0)
+	// 500000000 seconds after the DUID epoch
+	fixttime := time.Unix(1446684800, 0)
 	if !duidllt.Time.Equal(fixttime) {
 		t.Errorf("expected time %s, got %s", fixttime, duidllt.Time)
 	}
@@ -116,7 +238,7 @@ func TestDuidLLT(t *testing.T) {
 	}
 
 	// test matching output for String()
-	fixtstr := "hwaddr/time type 1 time 500000000 aa:bb:cc:dd:ee:ff"
+	fixtstr := "hwaddr/time type 1 hwtype Ethernet (1) time 500000000 aa:bb:cc:dd:ee:ff"
 	if duidllt.String() != fixtstr {
 		t.Errorf("unexpected String() output: %s", duidllt.String())
 	}
@@ -147,6 +269,109 @@ func TestDuidLLT(t *testing.T) {
 	}
 }
 
+func TestDUIDTime(t *testing.T) {
+	// test boundaries of the DUID time range
+	fixtures := []struct {
+		time  time.Time
+		value DUIDTime
+	}{
+		{time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2000, time.January, 1, 0, 0, 1, 0, time.UTC), 1},
+		{time.Unix(1446684800, 0), 500000000},
+		{time.Date(2068, time.January, 19, 3, 14, 7, 0, time.UTC), 0x7fffffff},
+		{time.Date(2068, time.January, 19, 3, 14, 8, 0, time.UTC), 0x80000000},
+		{time.Date(2136, time.February, 7, 6, 28, 15, 0, time.UTC), 0xffffffff},
+	}
+	for _, fixt := range fixtures {
+		if v, err := NewDUIDTime(fixt.time); err != nil {
+			t.Errorf("unexpected error converting %s: %s", fixt.time, err)
+		} else if v != fixt.value {
+			t.Errorf("expected DUID time %d for %s, got %d", fixt.value, fixt.time, v)
+		}
+		if !fixt.value.Time().Equal(fixt.time) {
+			t.Errorf("expected time %s for DUID time %d, got %s", fixt.time, fixt.value, fixt.value.Time())
+		}
+	}
+
+	// sub-second parts are truncated
+	if v, _ := NewDUIDTime(time.Unix(1446684800, 999999999)); v != 500000000 {
+		t.Errorf("expected DUID time 500000000, got %d", v)
+	}
+
+	// the zero time is DUID time 0, so a DUIDLLT without time still marshals
+	if v, err := NewDUIDTime(time.Time{}); err != nil || v != 0 {
+		t.Errorf("expected DUID time 0 for zero time, got %d (%v)", v, err)
+	}
+	duid := DUIDLLT{HardwareType: HardwareTypeEthernet, LinkLayerAddress: []byte{170, 187, 204, 221, 238, 255}}
+	if mshByte, err := duid.Marshal(); err != nil {
+		t.Errorf("error marshalling DUIDLLT without time: %s", err)
+	} else if !bytes.Equal(mshByte[4:8], []byte{0, 0, 0, 0}) {
+		t.Errorf("expected DUID time 0, got %v", mshByte[4:8])
+	}
+
+	// test for error on times outside the DUID time range
+	for _, fixt := range []time.Time{
+		time.Date(1999, time.December, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2136, time.February, 7, 6, 28, 16, 0, time.UTC),
+	} {
+		if _, err := NewDUIDTime(fixt); err == nil {
+			t.Errorf("expected error converting %s", fixt)
+		} else if err != errInvalidDUIDTime {
+			t.Errorf("unexpected error: %s", err)
+		}
+		duid := DUIDLLT{HardwareType: HardwareTypeEthernet, Time: fixt, LinkLayerAddress: []byte{170, 187, 204, 221, 238, 255}}
+		if _, err := duid.Marshal(); err != errInvalidDUIDTime {
+			t.Errorf("expected error marshalling DUIDLLT with time %s, got %v", fixt, err)
+		}
+	}
+}
+
+func FuzzDUIDTime(f *testing.F) {
+	for _, v := range []uint32{0, 1, 500000000, 0x7fffffff, 0x80000000, 0xfffffffe, 0xffffffff} {
+		f.Add(v)
+	}
+	f.Fuzz(func(t *testing.T, v uint32) {
+		// any 32 bit value should survive conversion to time.Time and back
+		if got, err := NewDUIDTime(DUIDTime(v).Time()); err != nil {
+			t.Fatalf("unexpected error converting %d: %s", v, err)
+		} else if got != DUIDTime(v) {
+			t.Fatalf("expected DUID time %d, got %d", v, got)
+		}
+
+		// and so should a DUIDLLT carrying it
+		fixtbyte := []byte{0, 1, 0, 1, 0, 0, 0, 0, 170, 187, 204, 221, 238, 255}
+		binary.BigEndian.PutUint32(fixtbyte[4:8], v)
+		duid, err := DecodeDUID(fixtbyte)
+		if err != nil {
+			t.Fatalf("error decoding fixture: %s", err)
+		}
+		if mshByte, err := duid.Marshal(); err != nil {
+			t.Fatalf("error marshalling DUID: %s", err)
+		} else if !bytes.Equal(fixtbyte, mshByte) {
+			t.Fatalf("marshalled DUID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+		}
+	})
+}
+
+func FuzzDecodeDUID(f *testing.F) {
+	f.Add([]byte{0, 1, 0, 1, 29, 205, 101, 0, 170, 187, 204, 221, 238, 255})
+	f.Add([]byte{0, 2, 0, 0, 126, 217, 1, 2, 3})
+	f.Add([]byte{0, 3, 0, 1, 170, 187, 204, 221, 238, 255})
+	f.Add([]byte{0, 4, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// every DUID that decodes should marshal to the same bytes
+		duid, err := DecodeDUID(data)
+		if err != nil {
+			return
+		}
+		if mshByte, err := duid.Marshal(); err != nil {
+			t.Fatalf("error marshalling decoded DUID %v: %s", data, err)
+		} else if !bytes.Equal(data, mshByte) {
+			t.Fatalf("marshalled DUID didn't match input!\ninput:   %v\nmarshal: %v", data, mshByte)
+		}
+	})
+}
+
 func TestDuidLL(t *testing.T) {
 	// test decoding bytes to DUIDLL
 	fixtbyte := []byte{0, 3, 0, 1, 170, 187, 204, 221, 238, 255}
@@ -160,7 +385,7 @@ func TestDuidLL(t *testing.T) {
 	if duidll.Type() != DUIDTypeLL {
 		t.Errorf("expected duid type %d, got %d", DUIDTypeLL, duidll.Type())
 	}
-	fixthwtype := uint16(1)
+	fixthwtype := HardwareTypeEthernet
 	if duidll.HardwareType != fixthwtype {
 		t.Errorf("expected hw type %d, got %d", fixthwtype, duidll.HardwareType)
 	}
@@ -177,7 +402,7 @@ func TestDuidLL(t *testing.T) {
 	}
 
 	// test matching output for String()
-	fixtstr := "hwaddr type 3 aa:bb:cc:dd:ee:ff"
+	fixtstr := "hwaddr type 3 hwtype Ethernet (1) aa:bb:cc:dd:ee:ff"
 	if duidll.String() != fixtstr {
 		t.Errorf("unexpected String() output: %s", duidll.String())
 	}
@@ -234,7 +459,7 @@ func TestDuidUUID(t *testing.T) {
 	}
 
 	// test matching output for String()
-	fixtstr := "type 4"
+	fixtstr := "uuid type 4 7e66eaa2-e6dd-497b-8e21-31944b282b43"
 	if duiduuid.String() != fixtstr {
 		t.Errorf("unexpected String() output: %s", duiduuid.String())
 	}
@@ -262,4 +487,347 @@ func TestDuidUUID(t *testing.T) {
 	} else if !bytes.Equal(fixtbyte, mshByte) {
 		t.Errorf("marshalled DUID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
 	}
+
+	// recreate same struct from string
+	if duiduuid, err = NewDUIDUUIDFromString(" " + fixtuuid + "\n"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if mshByte, err := duiduuid.Marshal(); err != nil {
+		t.Errorf("error marshalling DUID: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled DUID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+	if _, err := NewDUIDUUIDFromString("7e66eaa2-e6dd-497b-8e21"); err == nil {
+		t.Error("expected error parsing invalid UUID")
+	}
+}
+
+func TestDuidUUIDMachineID(t *testing.T) {
+	fixtbyte := []byte{0, 126, 102, 234, 162, 230, 221, 73, 123, 142, 33, 49, 148, 75, 40, 43, 67}
+	duiduuid, err := NewDUIDUUIDFromMachineID(fixtbyte)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fixtuuid := "7e66eaa2-e6dd-497b-8e21-31944b282b43"
+	if fixtuuid != duiduuid.UUID.String() {
+		t.Errorf("expected UUID %s, got %s", fixtuuid, duiduuid.UUID.String())
+	}
+	if mid := duiduuid.MachineID(); !bytes.Equal(fixtbyte, mid) {
+		t.Errorf("machine identifier didn't match fixture!\nfixture: %v\nmachine identifier: %v", fixtbyte, mid)
+	}
+
+	// test for error on wrong length or type
+	for _, fixt := range [][]byte{fixtbyte[:16], append([]byte{1}, fixtbyte[1:]...), append(fixtbyte, 0)} {
+		if _, err := NewDUIDUUIDFromMachineID(fixt); err == nil {
+			t.Errorf("expected error for machine identifier %v", fixt)
+		} else if err != errInvalidMachineID {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+}
+
+func TestFormatDUID(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	duid := &DUIDLLT{
+		HardwareType:     1,
+		Time:             time.Unix(1446684800, 0),
+		LinkLayerAddress: fixtmac,
+	}
+
+	tests := []struct {
+		format DUIDFormat
+		out    string
+	}{
+		{DUIDFormatColon, "00:01:00:01:1d:cd:65:00:aa:bb:cc:dd:ee:ff"},
+		{DUIDFormatHex, "000100011dcd6500aabbccddeeff"},
+		{DUIDFormatWindows, "00-01-00-01-1D-CD-65-00-AA-BB-CC-DD-EE-FF"},
+		{DUIDFormatOctal, `"\000\001\000\001\035\315e\000\252\273\314\335\356\377"`},
+	}
+
+	for _, test := range tests {
+		if out, err := FormatDUID(duid, test.format); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		} else if out != test.out {
+			t.Errorf("expected %s but got %s", test.out, out)
+		}
+	}
+
+	// quotes and backslashes should be escaped in octal notation
+	en := &DUIDEN{EnterpriseNumber: 34, ID: []byte(`a"b\`)}
+	if out, _ := FormatDUID(en, DUIDFormatOctal); out != `"\000\002\000\000\000\"a\"b\\"` {
+		t.Errorf("unexpected octal notation: %s", out)
+	}
+
+	if _, err := FormatDUID(duid, 255); err == nil {
+		t.Error("expected error for unhandled format")
+	}
+}
+
+func TestParseDUID(t *testing.T) {
+	fixtbyte := []byte{0, 1, 0, 1, 29, 205, 101, 0, 170, 187, 204, 221, 238, 255}
+	for _, in := range []string{
+		"00:01:00:01:1d:cd:65:00:aa:bb:cc:dd:ee:ff",
+		"0:1:0:1:1d:cd:65:0:aa:bb:cc:dd:ee:ff",
+		"000100011dcd6500aabbccddeeff",
+		"0x000100011DCD6500AABBCCDDEEFF",
+		" 00-01-00-01-1D-CD-65-00-AA-BB-CC-DD-EE-FF\n",
+		`"\000\001\000\001\035\315e\000\252\273\314\335\356\377"`,
+		`\000\001\000\001\035\315e\000\252\273\314\335\356\377`,
+	} {
+		duid, err := ParseDUID(in)
+		if err != nil {
+			t.Errorf("could not parse %q: %s", in, err)
+			continue
+		}
+		if duid.Type() != DUIDTypeLLT {
+			t.Errorf("expected DUID type %s for %q, got %s", DUIDTypeLLT, in, duid.Type())
+		}
+		if mshByte, err := duid.Marshal(); err != nil {
+			t.Errorf("error marshalling DUID: %s", err)
+		} else if !bytes.Equal(fixtbyte, mshByte) {
+			t.Errorf("parsed DUID %q didn't match fixture!\nfixture: %v\nmarshal: %v", in, fixtbyte, mshByte)
+		}
+	}
+
+	// escaped characters in octal notation
+	if duid, err := ParseDUID(`"\000\002\000\000\000\"a\"b\\"`); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if !bytes.Equal(duid.(*DUIDEN).ID, []byte(`a"b\`)) {
+		t.Errorf("unexpected ID %q", duid.(*DUIDEN).ID)
+	}
+
+	for _, in := range []string{
+		"00:01:00:01:1d:cd:65:00:aa:bb:cc:dd:ee:fff",
+		"00::01",
+		"00:01:0g",
+		"0001abc",
+		`"\000\00"`,
+		`"\000\`,
+		`\000\377\3`,
+		"",
+	} {
+		if _, err := ParseDUID(in); err == nil {
+			t.Errorf("expected error parsing %q", in)
+		}
+	}
+}
+
+func TestDUIDEqual(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	fixtuuid, _ := uuid.Parse("7e66eaa2-e6dd-497b-8e21-31944b282b43")
+	duids := []DUID{
+		&DUIDLLT{HardwareType: 1, Time: time.Unix(1446684800, 0), LinkLayerAddress: fixtmac},
+		&DUIDEN{EnterpriseNumber: 9, ID: []byte{1, 2, 3}},
+		&DUIDLL{HardwareType: 1, LinkLayerAddress: fixtmac},
+		&DUIDUUID{UUID: fixtuuid},
+		&DUIDOpaque{Data: []byte{0, 99, 1, 2, 3}},
+	}
+
+	for i, a := range duids {
+		// decode marshalled DUID and compare it to the original
+		b, _ := a.Marshal()
+		decoded, err := DecodeDUID(b)
+		if err != nil {
+			t.Fatalf("could not decode DUID: %s", err)
+		}
+		if !EqualDUIDs(a, decoded) || !EqualDUIDs(decoded, a) {
+			t.Errorf("expected %s to equal %s", a, decoded)
+		}
+
+		for j, other := range duids {
+			if i != j && EqualDUIDs(a, other) {
+				t.Errorf("expected %s not to equal %s", a, other)
+			}
+		}
+
+		if EqualDUIDs(a, nil) {
+			t.Errorf("expected %s not to equal nil", a)
+		}
+	}
+
+	// DUIDs of an unknown type are equal to known types with the same bytes
+	b, _ := duids[2].Marshal()
+	if !EqualDUIDs(duids[2], &DUIDOpaque{Data: b}) {
+		t.Error("expected DUIDLL to equal opaque DUID with same bytes")
+	}
+
+	// invalid DUIDs are still equal to themselves and their clones
+	invalid := &DUIDLLT{HardwareType: 1, Time: time.Unix(0, 0)}
+	if _, err := invalid.Marshal(); err == nil {
+		t.Errorf("expected %s to fail marshalling", invalid)
+	}
+	if !EqualDUIDs(invalid, invalid) || !EqualDUIDs(invalid, CloneDUID(invalid)) {
+		t.Errorf("expected %s to equal itself", invalid)
+	}
+	if EqualDUIDs(invalid, &DUIDLLT{HardwareType: 6, Time: time.Unix(0, 0)}) {
+		t.Errorf("expected %s not to equal DUID with other hardware type", invalid)
+	}
+}
+
+func TestDUIDKey(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	duid := &DUIDLL{HardwareType: 1, LinkLayerAddress: fixtmac}
+
+	key, err := NewDUIDKey(duid)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fixtstr := "00:03:00:01:aa:bb:cc:dd:ee:ff"
+	if key.String() != fixtstr {
+		t.Errorf("unexpected String() output: %s", key.String())
+	}
+
+	// equal DUIDs should result in the same map entry
+	decoded, _ := DecodeDUID([]byte{0, 3, 0, 1, 170, 187, 204, 221, 238, 255})
+	otherkey, _ := NewDUIDKey(decoded)
+	leases := map[DUIDKey]int{key: 1}
+	leases[otherkey]++
+	if len(leases) != 1 || leases[key] != 2 {
+		t.Errorf("expected single map entry, got %v", leases)
+	}
+
+	// key should decode to the same DUID
+	if d, err := key.DUID(); err != nil {
+		t.Errorf("could not decode key: %s", err)
+	} else if !EqualDUIDs(d, duid) {
+		t.Errorf("expected %s, got %s", duid, d)
+	}
+
+	if _, err := NewDUIDKey(&DUIDEN{}); err != errDUIDTooShort {
+		t.Errorf("expected DUID too short error, got %v", err)
+	}
+}
+
+func TestHardwareTypeString(t *testing.T) {
+	tests := []struct {
+		in  HardwareType
+		out string
+	}{
+		{HardwareTypeEthernet, "Ethernet (1)"},
+		{HardwareTypeIEEE802, "IEEE 802 (6)"},
+		{HardwareTypeIEEE1394, "IEEE 1394 (24)"},
+		{HardwareTypeInfiniBand, "InfiniBand (32)"},
+		{65535, "Unknown (65535)"},
+	}
+
+	for _, test := range tests {
+		if test.in.String() != test.out {
+			t.Errorf("expected %s but got %s", test.out, test.in.String())
+		}
+	}
+}
+
+func TestDUIDLinkLayerAddressValidation(t *testing.T) {
+	tests := []struct {
+		hwtype HardwareType
+		addr   []byte
+		valid  bool
+	}{
+		{HardwareTypeEthernet, []byte{170, 187, 204, 221, 238, 255}, true},
+		{HardwareTypeEthernet, []byte{170, 187, 204, 221, 238}, false},
+		{HardwareTypeEthernet, nil, false},
+		{HardwareTypeIEEE802, []byte{170, 187, 204, 221, 238, 255, 0, 1}, false},
+		{HardwareTypeEUI64, []byte{170, 187, 204, 221, 238, 255, 0, 1}, true},
+		{HardwareTypeInfiniBand, make([]byte, 20), true},
+		{HardwareTypeInfiniBand, make([]byte, 6), false},
+		// variable or unknown length
+		{HardwareTypeFrameRelay, []byte{1, 2}, true},
+		{1000, []byte{1, 2, 3}, true},
+	}
+
+	for _, test := range tests {
+		var fixterr error
+		if !test.valid {
+			fixterr = ErrInvalidLinkLayerAddress
+		}
+
+		hw := []byte{byte(test.hwtype >> 8), byte(test.hwtype)}
+		// DUID-LLT
+		fixtbyte := append(append([]byte{0, 1}, hw...), 29, 205, 101, 0)
+		fixtbyte = append(fixtbyte, test.addr...)
+		testDecodeLinkLayerDUID(t, fixtbyte, fixterr)
+		if _, err := NewDUIDLLT(test.hwtype, time.Unix(1446684800, 0), test.addr); err != fixterr {
+			t.Errorf("expected error %v creating DUID-LLT for %s with %d byte address, got %v", fixterr, test.hwtype, len(test.addr), err)
+		}
+
+		// DUID-LL
+		fixtbyte = append(append([]byte{0, 3}, hw...), test.addr...)
+		testDecodeLinkLayerDUID(t, fixtbyte, fixterr)
+		if _, err := NewDUIDLL(test.hwtype, test.addr); err != fixterr {
+			t.Errorf("expected error %v creating DUID-LL for %s with %d byte address, got %v", fixterr, test.hwtype, len(test.addr), err)
+		}
+	}
+
+	// test for error on unrepresentable time
+	if _, err := NewDUIDLLT(HardwareTypeEthernet, time.Unix(0, 0), []byte{170, 187, 204, 221, 238, 255}); err != errInvalidDUIDTime {
+		t.Errorf("expected invalid DUID time error, got %v", err)
+	}
+}
+
+// helper function to check that DUID-LLT or DUID-LL in data decodes to its own
+// type, along with given error, and marshals to the same bytes
+func testDecodeLinkLayerDUID(t *testing.T, data []byte, fixterr error) {
+	t.Helper()
+
+	duid, err := DecodeDUID(data)
+	if err != fixterr {
+		t.Errorf("expected error %v decoding %x, got %v", fixterr, data, err)
+	}
+	if duid == nil {
+		t.Fatalf("expected DUID decoding %x", data)
+	}
+	switch duid.(type) {
+	case *DUIDLLT, *DUIDLL:
+	default:
+		t.Errorf("expected %x to decode to link-layer DUID, got %T", data, duid)
+	}
+	if duid.Type() != DUIDType(data[1]) {
+		t.Errorf("expected DUID type %s, got %s", DUIDType(data[1]), duid.Type())
+	}
+	if b, err := duid.Marshal(); err != nil || !bytes.Equal(b, data) {
+		t.Errorf("expected %x to marshal to the same bytes, got %x (%v)", data, b, err)
+	}
+}
+
+func TestDUIDClone(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	fixtuuid, _ := uuid.Parse("7e66eaa2-e6dd-497b-8e21-31944b282b43")
+	duids := []DUID{
+		&DUIDLLT{HardwareType: HardwareTypeEthernet, Time: time.Unix(1446684800, 0), LinkLayerAddress: fixtmac},
+		&DUIDEN{EnterpriseNumber: 32473, ID: []byte{1, 2, 3}},
+		&DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac},
+		&DUIDUUID{UUID: fixtuuid},
+		&DUIDOpaque{Data: []byte{0, 99, 1, 2, 3}},
+	}
+
+	for _, duid := range duids {
+		clone := CloneDUID(duid)
+		if !EqualDUIDs(duid, clone) || !reflect.DeepEqual(duid, clone) {
+			t.Errorf("expected clone of %s to be equal", duid.Type())
+		}
+	}
+
+	// clones should not share memory with the original
+	for _, duid := range duids {
+		fixtbyte, _ := duid.Marshal()
+		clone := CloneDUID(duid)
+		switch d := clone.(type) {
+		case *DUIDLLT:
+			d.LinkLayerAddress[0] = 0
+		case *DUIDEN:
+			d.ID[0] = 0
+		case *DUIDLL:
+			d.LinkLayerAddress[0] = 0
+		case *DUIDUUID:
+			d.UUID[0] = 0
+		case *DUIDOpaque:
+			d.Data[2] = 0
+		}
+		if mshByte, _ := duid.Marshal(); !bytes.Equal(fixtbyte, mshByte) {
+			t.Errorf("modifying clone of %s changed original", duid.Type())
+		}
+		if EqualDUIDs(duid, clone) {
+			t.Errorf("expected modified clone of %s not to be equal", duid.Type())
+		}
+	}
 }
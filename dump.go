@@ -0,0 +1,170 @@
+package dhcpv6
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// indentation used for every level of nesting in Dump and HexDump
+const dumpIndent = "  "
+
+// Dump returns a multi-line description of this Message, in the spirit of the
+// verbose output of tcpdump or Wireshark. Every option is listed with its
+// code and length, followed by all of its fields and its nested options, each
+// level of nesting indented a bit further
+func (m Message) Dump() string {
+	var b strings.Builder
+	if m.MessageType.hasFlags() {
+		fmt.Fprintf(&b, "%s, flags 0x%06x\n", m.MessageType, m.Flags)
+	} else {
+		fmt.Fprintf(&b, "%s, transaction-id 0x%06x\n", m.MessageType, m.Xid)
+	}
+	dumpOptions(&b, m.Options, 1)
+
+	return b.String()
+}
+
+// HexDump returns a hex view of the marshalled Message, in which every line
+// starts with the offset of its first byte and is annotated with the message
+// header or option its bytes belong to. Bytes of nested options are listed
+// after those of the option containing them, with the annotation indented a
+// bit further
+func (m Message) HexDump() (string, error) {
+	b, err := m.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	hexDumpLines(&out, b[:4], 0, m.MessageType.String())
+	if _, err := hexDumpOptions(&out, b, 4, m.Options, 0); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// interface of options containing options themselves
+type optionsContainer interface {
+	Options() Options
+}
+
+// helper function to write the description of given options to b
+func dumpOptions(b *strings.Builder, opts Options, depth int) {
+	indent := strings.Repeat(dumpIndent, depth)
+	for _, opt := range opts {
+		fmt.Fprintf(b, "%s%s, length %d\n", indent, opt.Type(), opt.Len())
+		dumpFields(b, reflect.ValueOf(opt), depth+1)
+		if c, ok := opt.(optionsContainer); ok {
+			dumpOptions(b, c.Options(), depth+1)
+		}
+	}
+}
+
+// helper function to write the exported fields of given option or DUID to b,
+// describing DUIDs in a nested block
+func dumpFields(b *strings.Builder, v reflect.Value, depth int) {
+	v = reflect.Indirect(v)
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	indent := strings.Repeat(dumpIndent, depth)
+	for _, f := range reflect.VisibleFields(v.Type()) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+
+		fv := v.FieldByIndex(f.Index)
+		if duid, ok := fv.Interface().(DUID); ok && duid != nil {
+			fmt.Fprintf(b, "%s%s: %s, length %d\n", indent, f.Name, duid.Type(), duid.Len())
+			dumpFields(b, reflect.ValueOf(duid), depth+1)
+			continue
+		}
+		fmt.Fprintf(b, "%s%s: %s\n", indent, f.Name, dumpValue(fv))
+	}
+}
+
+// helper function to format a single field value for Dump
+func dumpValue(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case net.IP:
+		return x.String()
+	case net.HardwareAddr:
+		return x.String()
+	case []byte:
+		return colonHex(x)
+	case []string:
+		return strings.Join(x, ", ")
+	case time.Time:
+		return x.Format(time.RFC3339)
+	case fmt.Stringer:
+		return x.String()
+	}
+
+	if v.Kind() == reflect.Slice {
+		values := make([]string, v.Len())
+		for i := range values {
+			values[i] = dumpValue(v.Index(i))
+		}
+		return strings.Join(values, ", ")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// helper function to format bytes as colon separated hex, like hardware
+// addresses are
+func colonHex(b []byte) string {
+	return net.HardwareAddr(b).String()
+}
+
+// helper function to write the hex lines of given options, found in b from
+// offset on, to out and return the offset following the options
+func hexDumpOptions(out *strings.Builder, b []byte, offset int, opts Options, depth int) (int, error) {
+	for _, opt := range opts {
+		var nested Options
+		if c, ok := opt.(optionsContainer); ok {
+			nested = c.Options()
+		}
+
+		// nested options follow the fields of the option containing them
+		own := 4 + int(opt.Len()) - int(nested.Len())
+		if own < 4 || offset+own > len(b) {
+			return 0, errOptionTooLong
+		}
+		hexDumpLines(out, b[offset:offset+own], offset, strings.Repeat(dumpIndent, depth)+opt.Type().String())
+		offset += own
+
+		var err error
+		if offset, err = hexDumpOptions(out, b, offset, nested, depth+1); err != nil {
+			return 0, err
+		}
+	}
+
+	return offset, nil
+}
+
+// helper function to write given bytes to out in lines of at most 16 bytes,
+// annotating the first line with label
+func hexDumpLines(out *strings.Builder, b []byte, offset int, label string) {
+	for i := 0; i < len(b); i += 16 {
+		end := i + 16
+		if end > len(b) {
+			end = len(b)
+		}
+
+		hex := make([]string, end-i)
+		for j, c := range b[i:end] {
+			hex[j] = fmt.Sprintf("%02x", c)
+		}
+		line := fmt.Sprintf("%04x  %-47s", offset+i, strings.Join(hex, " "))
+		if i == 0 {
+			line += "  " + label
+		}
+		out.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+}
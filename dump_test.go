@@ -0,0 +1,79 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// helper function returning the message used in the dump tests
+func testDumpMessage() *Message {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+
+	iana := &OptionIANA{IAID: 1, T1: 300 * time.Second, T2: 450 * time.Second}
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1"), PreferredLifetime: 600 * time.Second, ValidLifetime: 900 * time.Second})
+	msg := &Message{MessageType: MessageTypeReply, Xid: 123456}
+	msg.AddOption(&OptionClientID{DUID: &DUIDLLT{HardwareType: HardwareTypeEthernet, Time: time.Unix(1446684800, 0), LinkLayerAddress: fixtmac}})
+	msg.AddOption(iana)
+	msg.AddOption(&OptionVendorClass{classDataContainer{ClassData: []string{"foo", "bar"}}, 32473})
+	msg.AddOption(&OptionOptionRequest{Options: []OptionType{OptionTypeDNSServer, OptionTypeDNSSearchList}})
+
+	return msg
+}
+
+func TestMessageDump(t *testing.T) {
+	fixtdump := `Reply (7), transaction-id 0x01e240
+  Client Identifier (1), length 14
+    DUID: LinkLayerTime, length 14
+      HardwareType: Ethernet (1)
+      Time: 2015-11-05T00:53:20Z
+      LinkLayerAddress: aa:bb:cc:dd:ee:ff
+  Identity Association for Non-temporary Addresses (3), length 40
+    IAID: 1
+    T1: 5m0s
+    T2: 7m30s
+    Identity Association Address (5), length 24
+      Address: 2001:db8::1
+      PreferredLifetime: 10m0s
+      ValidLifetime: 15m0s
+  Vendor Class (16), length 14
+    ClassData: foo, bar
+    EnterpriseNumber: 32473
+  Option Request (6), length 4
+    Options: DNS Server (23), DNS Search List (24)
+`
+	if dump := testDumpMessage().Dump(); dump != fixtdump {
+		t.Errorf("unexpected Dump() output:\nexpected:\n%s\ngot:\n%s", fixtdump, dump)
+	}
+
+	// DHCPv4-over-DHCPv6 messages show their flags instead
+	msg := &Message{MessageType: MessageTypeDHCPv4Query, Flags: DHCPv4QueryFlagUnicast}
+	fixtdump = "DHCPv4 Query (20), flags 0x800000\n"
+	if dump := msg.Dump(); dump != fixtdump {
+		t.Errorf("unexpected Dump() output:\nexpected:\n%s\ngot:\n%s", fixtdump, dump)
+	}
+}
+
+func TestMessageHexDump(t *testing.T) {
+	fixtdump := `0000  07 01 e2 40                                      Reply (7)
+0004  00 01 00 0e 00 01 00 01 1d cd 65 00 aa bb cc dd  Client Identifier (1)
+0014  ee ff
+0016  00 03 00 28 00 00 00 01 00 00 01 2c 00 00 01 c2  Identity Association for Non-temporary Addresses (3)
+0026  00 05 00 18 20 01 0d b8 00 00 00 00 00 00 00 00    Identity Association Address (5)
+0036  00 00 00 01 00 00 02 58 00 00 03 84
+0042  00 10 00 0e 00 00 7e d9 00 03 66 6f 6f 00 03 62  Vendor Class (16)
+0052  61 72
+0054  00 06 00 04 00 17 00 18                          Option Request (6)
+`
+	if dump, err := testDumpMessage().HexDump(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if dump != fixtdump {
+		t.Errorf("unexpected HexDump() output:\nexpected:\n%s\ngot:\n%s", fixtdump, dump)
+	}
+
+	// test for error on message that cannot be marshalled
+	msg := &Message{MessageType: MessageTypeReply, Xid: MaxTransactionID + 1}
+	if _, err := msg.HexDump(); err != errInvalidXid {
+		t.Errorf("expected error %s, got %v", errInvalidXid, err)
+	}
+}
@@ -0,0 +1,201 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errNoHostDUID        = errors.New("no suitable source for a DUID found")
+	errInvalidSystemUUID = errors.New("invalid system UUID")
+)
+
+// these can be overridden for testing purposes
+var (
+	netInterfaces   = net.Interfaces
+	machineIDPath   = "/etc/machine-id"
+	productUUIDPath = "/sys/class/dmi/id/product_uuid"
+	timeNow         = time.Now
+)
+
+// application ID used to derive a DUID-UUID from the machine-id, so the
+// machine-id itself is not disclosed on the network
+var machineIDAppID = []byte{0xa5, 0x0a, 0xd1, 0x12, 0xbf, 0x60, 0x45, 0x77,
+	0xa2, 0xfb, 0x74, 0x1a, 0xb1, 0x95, 0x5b, 0x03}
+
+// GenerateDUID returns a new DUID for this host. It prefers a DUID-LLT based on
+// the hardware address of the first suitable network interface, then a
+// DUID-UUID derived from the machine-id and finally, when enterpriseNumber is
+// not 0, a DUID-EN with a random identifier. Since DUID-LLTs and DUID-ENs are
+// not reproducible, you will probably want to use LoadOrGenerateDUID instead
+func GenerateDUID(enterpriseNumber uint32) (DUID, error) {
+	if duid, err := generateDUIDLLT(); err == nil {
+		return duid, nil
+	}
+
+	if duid, err := generateDUIDUUID(); err == nil {
+		return duid, nil
+	}
+
+	if enterpriseNumber != 0 {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return nil, err
+		}
+		return NewDUIDEN(enterpriseNumber, id)
+	}
+
+	return nil, errNoHostDUID
+}
+
+// LoadOrGenerateDUID returns the DUID stored in the file at path. If the file
+// does not exist, a DUID is generated using GenerateDUID and stored in the
+// file, so the same DUID is returned on subsequent runs
+func LoadOrGenerateDUID(path string, enterpriseNumber uint32) (DUID, error) {
+	duid, err := LoadDUID(path)
+	if err == nil {
+		return duid, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	duid, err = GenerateDUID(enterpriseNumber)
+	if err != nil {
+		return nil, err
+	}
+	if err := SaveDUID(path, duid); err != nil {
+		return nil, err
+	}
+
+	return duid, nil
+}
+
+// LoadDUID reads a DUID from the file at path, as written by SaveDUID. The
+// DUID can be in any notation accepted by ParseDUID
+func LoadDUID(path string) (DUID, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseDUID(string(content))
+}
+
+// SaveDUID writes given DUID to the file at path as colon separated hex
+// string. The file is replaced atomically, so a concurrent LoadDUID never
+// reads a partially written DUID
+func SaveDUID(path string, duid DUID) error {
+	text, err := FormatDUID(duid, DUIDFormatColon)
+	if err != nil {
+		return fmt.Errorf("could not marshal DUID: %s", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := fmt.Fprintln(tmp, text); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// NewDUIDUUIDFromSMBIOS returns a DUIDUUID for the SMBIOS system UUID of this
+// host, as UEFI and PXE firmware use it in their DUID-UUID. The firmware sends
+// the UUID in its raw SMBIOS encoding, which stores the first three fields in
+// little-endian byte order, so the UUID of the returned DUID differs from the
+// one the system reports in those fields. Reading the system UUID usually
+// requires root privileges
+func NewDUIDUUIDFromSMBIOS() (*DUIDUUID, error) {
+	content, err := os.ReadFile(productUUIDPath)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := NewDUIDUUIDFromString(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid system UUID in %s: %s", productUUIDPath, err)
+	}
+	// firmware without a system UUID reports all zeros or all ones
+	if d.UUID == uuid.Nil || bytes.Equal(d.UUID[:], bytes.Repeat([]byte{0xff}, 16)) {
+		return nil, errInvalidSystemUUID
+	}
+
+	// swap time_low, time_mid and time_hi_and_version to their SMBIOS encoding
+	// as described at https://www.dmtf.org/standards/smbios, section 7.2.1
+	u := d.UUID
+	d.UUID = uuid.UUID{u[3], u[2], u[1], u[0], u[5], u[4], u[7], u[6],
+		u[8], u[9], u[10], u[11], u[12], u[13], u[14], u[15]}
+
+	return d, nil
+}
+
+// helper function to generate a DUID-LLT for the first interface that is not
+// a loopback interface and has an ethernet hardware address
+func generateDUIDLLT() (DUID, error) {
+	ifaces, err := netInterfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 || len(iface.HardwareAddr) != 6 {
+			continue
+		}
+		// skip interfaces without a real hardware address
+		if bytes.Equal(iface.HardwareAddr, make([]byte, 6)) {
+			continue
+		}
+
+		return &DUIDLLT{
+			HardwareType:     HardwareTypeEthernet,
+			Time:             timeNow().Truncate(time.Second),
+			LinkLayerAddress: iface.HardwareAddr,
+		}, nil
+	}
+
+	return nil, errNoHostDUID
+}
+
+// helper function to generate a DUID-UUID from the machine-id of this host
+func generateDUIDUUID() (DUID, error) {
+	content, err := os.ReadFile(machineIDPath)
+	if err != nil {
+		return nil, err
+	}
+
+	machineID, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(machineID) != 16 {
+		return nil, fmt.Errorf("invalid machine-id in %s", machineIDPath)
+	}
+
+	// derive an application specific version 4 UUID from the machine-id
+	mac := hmac.New(sha256.New, machineID)
+	mac.Write(machineIDAppID)
+	var u uuid.UUID
+	copy(u[:], mac.Sum(nil))
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+
+	return &DUIDUUID{UUID: u}, nil
+}
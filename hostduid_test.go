@@ -0,0 +1,188 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// helper function to override the sources of host DUIDs for the duration of
+// a test
+func mockHostDUIDSources(t *testing.T, ifaces []net.Interface, machineID string) {
+	origInterfaces, origMachineIDPath, origTimeNow := netInterfaces, machineIDPath, timeNow
+	t.Cleanup(func() {
+		netInterfaces, machineIDPath, timeNow = origInterfaces, origMachineIDPath, origTimeNow
+	})
+
+	netInterfaces = func() ([]net.Interface, error) {
+		return ifaces, nil
+	}
+	timeNow = func() time.Time {
+		return time.Unix(1446684800, 500)
+	}
+	machineIDPath = filepath.Join(t.TempDir(), "machine-id")
+	if machineID != "" {
+		if err := os.WriteFile(machineIDPath, []byte(machineID+"\n"), 0644); err != nil {
+			t.Fatalf("could not write machine-id: %s", err)
+		}
+	}
+}
+
+func TestGenerateDUID(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	ifaces := []net.Interface{
+		{Name: "lo", Flags: net.FlagLoopback | net.FlagUp},
+		{Name: "tun0", Flags: net.FlagUp},
+		{Name: "dummy0", HardwareAddr: make(net.HardwareAddr, 6)},
+		{Name: "eth0", HardwareAddr: fixtmac},
+	}
+
+	// interface with a hardware address results in DUID-LLT
+	mockHostDUIDSources(t, ifaces, "0123456789abcdef0123456789abcdef")
+	duid, err := GenerateDUID(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fixtbyte := []byte{0, 1, 0, 1, 29, 205, 101, 0, 170, 187, 204, 221, 238, 255}
+	if mshByte, err := duid.Marshal(); err != nil {
+		t.Errorf("error marshalling DUID: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled DUID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// without such interface the machine-id results in a stable DUID-UUID
+	mockHostDUIDSources(t, ifaces[:3], "0123456789abcdef0123456789abcdef")
+	duid, err = GenerateDUID(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if duid.Type() != DUIDTypeUUID {
+		t.Fatalf("expected DUID type %s, got %s", DUIDTypeUUID, duid.Type())
+	}
+	u := duid.(*DUIDUUID).UUID
+	if u.Version() != 4 {
+		t.Errorf("expected version 4 UUID, got %d", u.Version())
+	}
+	if again, _ := GenerateDUID(0); again.(*DUIDUUID).UUID != u {
+		t.Errorf("expected same UUID, got %s and %s", u, again.(*DUIDUUID).UUID)
+	}
+	// the machine-id itself should not be disclosed
+	if u.String() == "01234567-89ab-cdef-0123-456789abcdef" {
+		t.Error("expected UUID not to be the machine-id")
+	}
+
+	// without machine-id a DUID-EN is generated, if an enterprise number is set
+	mockHostDUIDSources(t, nil, "")
+	if _, err := GenerateDUID(0); err != errNoHostDUID {
+		t.Errorf("expected no host DUID error, got %v", err)
+	}
+	duid, err = GenerateDUID(uint32(EnterpriseNumberExample))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if duid.Type() != DUIDTypeEN {
+		t.Fatalf("expected DUID type %s, got %s", DUIDTypeEN, duid.Type())
+	}
+	if duid.(*DUIDEN).EnterpriseNumber != uint32(EnterpriseNumberExample) {
+		t.Errorf("unexpected enterprise number %d", duid.(*DUIDEN).EnterpriseNumber)
+	}
+}
+
+func TestLoadOrGenerateDUID(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	mockHostDUIDSources(t, []net.Interface{{Name: "eth0", HardwareAddr: fixtmac}}, "")
+
+	path := filepath.Join(t.TempDir(), "server-duid")
+	duid, err := LoadOrGenerateDUID(path, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// DUID should be persisted as colon separated hex
+	fixtstr := "00:01:00:01:1d:cd:65:00:aa:bb:cc:dd:ee:ff\n"
+	if content, err := os.ReadFile(path); err != nil {
+		t.Errorf("could not read persisted DUID: %s", err)
+	} else if string(content) != fixtstr {
+		t.Errorf("unexpected persisted DUID: %q", content)
+	}
+
+	// on subsequent runs, the DUID should be loaded even though a new DUID
+	// would differ
+	timeNow = time.Now
+	loaded, err := LoadOrGenerateDUID(path, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fixtbyte, _ := duid.Marshal()
+	if mshByte, err := loaded.Marshal(); err != nil {
+		t.Errorf("error marshalling DUID: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("loaded DUID didn't match generated DUID!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// a corrupt file should not be overwritten
+	if err := os.WriteFile(path, []byte("zz"), 0644); err != nil {
+		t.Fatalf("could not write file: %s", err)
+	}
+	if _, err := LoadOrGenerateDUID(path, 0); err == nil {
+		t.Error("expected error loading corrupt DUID")
+	}
+}
+
+func TestNewDUIDUUIDFromSMBIOS(t *testing.T) {
+	origProductUUIDPath := productUUIDPath
+	t.Cleanup(func() {
+		productUUIDPath = origProductUUIDPath
+	})
+	productUUIDPath = filepath.Join(t.TempDir(), "product_uuid")
+
+	// test for error when there is no system UUID
+	if _, err := NewDUIDUUIDFromSMBIOS(); err == nil {
+		t.Error("expected error without system UUID")
+	}
+
+	fixtures := []struct {
+		content string
+		valid   bool
+	}{
+		{"7E66EAA2-E6DD-497B-8E21-31944B282B43\n", true},
+		{"00000000-0000-0000-0000-000000000000\n", false},
+		{"FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF\n", false},
+		{"Not Settable\n", false},
+	}
+	for _, fixt := range fixtures {
+		if err := os.WriteFile(productUUIDPath, []byte(fixt.content), 0644); err != nil {
+			t.Fatalf("could not write product_uuid: %s", err)
+		}
+		duid, err := NewDUIDUUIDFromSMBIOS()
+		if !fixt.valid {
+			if err == nil {
+				t.Errorf("expected error for system UUID %q", fixt.content)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		} else if duid.UUID.String() != "a2ea667e-dde6-7b49-8e21-31944b282b43" {
+			t.Errorf("unexpected UUID %s", duid.UUID)
+		}
+	}
+
+	// the system UUID of a Dell system and the DUID-UUID its UEFI firmware
+	// sends, holding the raw SMBIOS bytes with the first three fields
+	// little-endian
+	if err := os.WriteFile(productUUIDPath, []byte("4C4C4544-0042-3510-8052-B4C04F4E4D32\n"), 0644); err != nil {
+		t.Fatalf("could not write product_uuid: %s", err)
+	}
+	fixtbyte := []byte{0, 4, 0x44, 0x45, 0x4c, 0x4c, 0x42, 0x00, 0x10, 0x35, 0x80, 0x52, 0xb4, 0xc0, 0x4f, 0x4e, 0x4d, 0x32}
+	if duid, err := NewDUIDUUIDFromSMBIOS(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if mshByte, err := duid.Marshal(); err != nil {
+		t.Errorf("error marshalling DUIDUUID: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled DUIDUUID didn't match firmware DUID!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+}
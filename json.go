@@ -0,0 +1,494 @@
+package dhcpv6
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JSON representation of messages, options and DUIDs
+//
+// Options and DUIDs are encoded as JSON objects containing their exported
+// fields, their type code as Type and the name of their type as Name. Options
+// containing options themselves have those in Options. Since options are
+// encoded by Options, this only applies to options in a Message or a slice of
+// Options; options that need more than their exported fields implement
+// json.Marshaler and json.Unmarshaler themselves. Addresses are encoded
+// in their textual representation, hardware addresses, DUID identifiers and
+// the data of unknown options as colon separated hex and other byte slices as
+// base64. Options holding Data are decoded to OptionUnknown, whatever their
+// type, so options that couldn't be decoded from the wire survive a round
+// trip. Since JSON strings are UTF-8, string fields containing invalid UTF-8
+// don't survive a round trip.
+
+// constructors for all options that can be decoded from JSON
+var jsonOptionTypes = map[OptionType]func() Option{
+	OptionTypeClientID:                         func() Option { return &OptionClientID{} },
+	OptionTypeServerID:                         func() Option { return &OptionServerID{} },
+	OptionTypeIANA:                             func() Option { return &OptionIANA{} },
+	OptionTypeIAAddress:                        func() Option { return &OptionIAAddress{} },
+	OptionTypeOptionRequest:                    func() Option { return &OptionOptionRequest{} },
+	OptionTypeElapsedTime:                      func() Option { return &OptionElapsedTime{} },
+	OptionTypeStatusCode:                       func() Option { return &OptionStatusCode{} },
+	OptionTypeRapidCommit:                      func() Option { return &OptionRapidCommit{} },
+	OptionTypeUserClass:                        func() Option { return &OptionUserClass{} },
+	OptionTypeVendorClass:                      func() Option { return &OptionVendorClass{} },
+	OptionTypeDNSServer:                        func() Option { return &OptionDNSServer{} },
+	OptionTypeDNSSearchList:                    func() Option { return &OptionDNSSearchList{} },
+	OptionTypeIAPD:                             func() Option { return &OptionIAPD{} },
+	OptionTypeIAPrefix:                         func() Option { return &OptionIAPrefix{} },
+	OptionTypePDExclude:                        func() Option { return &OptionPDExclude{} },
+	OptionTypeRemoteID:                         func() Option { return &OptionRemoteID{} },
+	OptionTypeClientFQDN:                       func() Option { return &OptionClientFQDN{} },
+	OptionTypeLQQuery:                          func() Option { return &OptionLQQuery{} },
+	OptionTypeClientData:                       func() Option { return &OptionClientData{} },
+	OptionTypeCLTTime:                          func() Option { return &OptionCLTTime{} },
+	OptionTypeLQRelayData:                      func() Option { return &OptionLQRelayData{} },
+	OptionTypeLQClientLink:                     func() Option { return &OptionLQClientLink{} },
+	OptionTypeRelayID:                          func() Option { return &OptionRelayID{} },
+	OptionTypeBootFileURL:                      func() Option { return &OptionBootFileURL{} },
+	OptionTypeBootFileParameters:               func() Option { return &OptionBootFileParameters{} },
+	OptionTypeClientSystemArchitectureType:     func() Option { return &OptionClientSystemArchitectureType{} },
+	OptionTypeClientNetworkInterfaceIdentifier: func() Option { return &OptionClientNetworkInterfaceIdentifier{} },
+	OptionTypeDHCPv4Message:                    func() Option { return &OptionDHCPv4Message{} },
+	OptionTypeDHCP4oDHCP6Server:                func() Option { return &OptionDHCP4oDHCP6Server{} },
+	OptionTypeNextHop:                          func() Option { return &OptionNextHop{} },
+	OptionTypeRoutePrefix:                      func() Option { return &OptionRoutePrefix{} },
+}
+
+// helper function to encode fields to a JSON object, adding the fields in extra
+func marshalJSONWith(fields interface{}, extra map[string]interface{}) ([]byte, error) {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		if m[k], err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(m)
+}
+
+// helper function to encode the fields of an option along with its type and
+// nested options, if any
+func marshalOptionJSON(opt Option, fields interface{}) ([]byte, error) {
+	extra := map[string]interface{}{
+		"Type": opt.Type(),
+		"Name": opt.Type().name(),
+	}
+	if c, ok := opt.(interface{ Options() Options }); ok && c.Options() != nil {
+		extra["Options"] = c.Options()
+	}
+
+	return marshalJSONWith(fields, extra)
+}
+
+// helper function to decode the fields of an option after checking its type
+func unmarshalOptionJSON(data []byte, t OptionType, fields interface{}) error {
+	var meta struct {
+		Type OptionType
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return err
+	}
+	if meta.Type != t {
+		return fmt.Errorf("expected option type %s, got %s", t, meta.Type)
+	}
+
+	return json.Unmarshal(data, fields)
+}
+
+// helper function to decode the nested options of an option
+func unmarshalNestedOptionsJSON(data []byte) (Options, error) {
+	var nested struct {
+		Options Options
+	}
+	err := json.Unmarshal(data, &nested)
+
+	return nested.Options, err
+}
+
+// MarshalJSON returns the JSON encoding of all Options in slice. Options
+// encode their exported fields, unless they implement json.Marshaler
+// themselves
+func (o Options) MarshalJSON() ([]byte, error) {
+	if o == nil {
+		return []byte("null"), nil
+	}
+
+	list := make([]json.RawMessage, 0, len(o))
+	for _, opt := range o {
+		var b []byte
+		var err error
+		if m, ok := opt.(json.Marshaler); ok {
+			b, err = m.MarshalJSON()
+		} else {
+			b, err = marshalOptionJSON(opt, opt)
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, b)
+	}
+
+	return json.Marshal(list)
+}
+
+// UnmarshalJSON decodes a JSON array of options, creating the appropriate
+// Option for each element based on its type
+func (o *Options) UnmarshalJSON(data []byte) error {
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	if list == nil {
+		*o = nil
+		return nil
+	}
+
+	options := make(Options, 0, len(list))
+	for _, raw := range list {
+		var meta struct {
+			Type OptionType
+			Data *string
+		}
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return err
+		}
+		// options holding raw data are OptionUnknown, whatever their type
+		newOption, ok := jsonOptionTypes[meta.Type]
+		if !ok || meta.Data != nil {
+			newOption = func() Option { return &OptionUnknown{} }
+		}
+		opt := newOption()
+		if err := json.Unmarshal(raw, opt); err != nil {
+			return err
+		}
+		if c, ok := opt.(interface{ setOptions(Options) }); ok {
+			nested, err := unmarshalNestedOptionsJSON(raw)
+			if err != nil {
+				return err
+			}
+			c.setOptions(nested)
+		}
+		options = append(options, opt)
+	}
+
+	*o = options
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of this Message, which includes the
+// name of its type
+func (m Message) MarshalJSON() ([]byte, error) {
+	type fields Message
+	return marshalJSONWith(fields(m), map[string]interface{}{
+		"Name": m.MessageType.name(),
+	})
+}
+
+// UnmarshalJSON decodes given JSON encoding of a Message
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type fields Message
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	*m = Message(f)
+	return nil
+}
+
+// helper function to decode the DUID of an option holding just a DUID, after
+// checking its type
+func unmarshalDUIDOptionJSON(data []byte, t OptionType) (DUID, error) {
+	var f struct {
+		DUID json.RawMessage
+	}
+	if err := unmarshalOptionJSON(data, t, &f); err != nil {
+		return nil, err
+	}
+
+	return DecodeDUIDJSON(f.DUID)
+}
+
+// UnmarshalJSON decodes given JSON encoding of a OptionClientID
+func (o *OptionClientID) UnmarshalJSON(data []byte) error {
+	duid, err := unmarshalDUIDOptionJSON(data, OptionTypeClientID)
+	if err != nil {
+		return err
+	}
+	o.DUID = duid
+
+	return nil
+}
+
+// UnmarshalJSON decodes given JSON encoding of a OptionServerID
+func (o *OptionServerID) UnmarshalJSON(data []byte) error {
+	duid, err := unmarshalDUIDOptionJSON(data, OptionTypeServerID)
+	if err != nil {
+		return err
+	}
+	o.DUID = duid
+
+	return nil
+}
+
+// UnmarshalJSON decodes given JSON encoding of a OptionRelayID
+func (o *OptionRelayID) UnmarshalJSON(data []byte) error {
+	duid, err := unmarshalDUIDOptionJSON(data, OptionTypeRelayID)
+	if err != nil {
+		return err
+	}
+	o.DUID = duid
+
+	return nil
+}
+
+// JSON fields of unknown options
+type jsonOptionUnknown struct {
+	Data *string
+}
+
+// MarshalJSON returns the JSON encoding of this OptionUnknown, which contains
+// its data as colon separated hex
+func (o OptionUnknown) MarshalJSON() ([]byte, error) {
+	data := formatHex(o.Data, ":")
+	return marshalOptionJSON(o, jsonOptionUnknown{Data: &data})
+}
+
+// UnmarshalJSON decodes given JSON encoding of a OptionUnknown
+func (o *OptionUnknown) UnmarshalJSON(data []byte) error {
+	var f struct {
+		Type OptionType
+		jsonOptionUnknown
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	// without data, this is more likely a mistake than an empty option
+	if f.Data == nil {
+		return fmt.Errorf("unhandled option type: %s", f.Type)
+	}
+	b, err := parseHexJSON(*f.Data)
+	if err != nil {
+		return fmt.Errorf("invalid option data: %s", err)
+	}
+
+	*o = OptionUnknown{Code: f.Type, Data: b}
+	return nil
+}
+
+// helper function to encode the fields of a DUID along with its type
+func marshalDUIDJSON(t DUIDType, fields interface{}) ([]byte, error) {
+	return marshalJSONWith(fields, map[string]interface{}{
+		"Type": t,
+		"Name": t.String(),
+	})
+}
+
+// helper function to decode the fields of a DUID after checking its type
+func unmarshalDUIDJSON(data []byte, t DUIDType, fields interface{}) error {
+	var meta struct {
+		Type DUIDType
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return err
+	}
+	if meta.Type != t {
+		return fmt.Errorf("expected DUID type %s, got %s", t, meta.Type)
+	}
+
+	return json.Unmarshal(data, fields)
+}
+
+// helper function to decode colon separated hex as written by formatHex
+func parseHexJSON(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	return parseSeparatedDUID(s)
+}
+
+// DecodeDUIDJSON takes the JSON encoding of a DUID and returns the DUID of the
+// appropriate type, or a DUIDOpaque for DUID types not handled by this package
+func DecodeDUIDJSON(data []byte) (DUID, error) {
+	var meta struct {
+		Type DUIDType
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+
+	var d DUID
+	switch meta.Type {
+	case DUIDTypeLLT:
+		d = &DUIDLLT{}
+	case DUIDTypeEN:
+		d = &DUIDEN{}
+	case DUIDTypeLL:
+		d = &DUIDLL{}
+	case DUIDTypeUUID:
+		d = &DUIDUUID{}
+	default:
+		d = &DUIDOpaque{}
+	}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// JSON fields of DUID-LLT
+type jsonDUIDLLT struct {
+	HardwareType     HardwareType
+	Time             time.Time
+	LinkLayerAddress string
+}
+
+// MarshalJSON returns the JSON encoding of this DUIDLLT
+func (d DUIDLLT) MarshalJSON() ([]byte, error) {
+	return marshalDUIDJSON(DUIDTypeLLT, jsonDUIDLLT{
+		HardwareType:     d.HardwareType,
+		Time:             d.Time,
+		LinkLayerAddress: formatHex(d.LinkLayerAddress, ":"),
+	})
+}
+
+// UnmarshalJSON decodes given JSON encoding of a DUIDLLT
+func (d *DUIDLLT) UnmarshalJSON(data []byte) error {
+	var f jsonDUIDLLT
+	if err := unmarshalDUIDJSON(data, DUIDTypeLLT, &f); err != nil {
+		return err
+	}
+	lla, err := parseHexJSON(f.LinkLayerAddress)
+	if err != nil {
+		return fmt.Errorf("invalid link-layer address: %s", err)
+	}
+
+	*d = DUIDLLT{
+		HardwareType:     f.HardwareType,
+		Time:             f.Time,
+		LinkLayerAddress: lla,
+	}
+	return nil
+}
+
+// JSON fields of DUID-EN
+type jsonDUIDEN struct {
+	EnterpriseNumber uint32
+	ID               string
+}
+
+// MarshalJSON returns the JSON encoding of this DUIDEN
+func (d DUIDEN) MarshalJSON() ([]byte, error) {
+	return marshalDUIDJSON(DUIDTypeEN, jsonDUIDEN{
+		EnterpriseNumber: d.EnterpriseNumber,
+		ID:               formatHex(d.ID, ":"),
+	})
+}
+
+// UnmarshalJSON decodes given JSON encoding of a DUIDEN
+func (d *DUIDEN) UnmarshalJSON(data []byte) error {
+	var f jsonDUIDEN
+	if err := unmarshalDUIDJSON(data, DUIDTypeEN, &f); err != nil {
+		return err
+	}
+	id, err := parseHexJSON(f.ID)
+	if err != nil {
+		return fmt.Errorf("invalid identifier: %s", err)
+	}
+
+	*d = DUIDEN{
+		EnterpriseNumber: f.EnterpriseNumber,
+		ID:               id,
+	}
+	return nil
+}
+
+// JSON fields of DUID-LL
+type jsonDUIDLL struct {
+	HardwareType     HardwareType
+	LinkLayerAddress string
+}
+
+// MarshalJSON returns the JSON encoding of this DUIDLL
+func (d DUIDLL) MarshalJSON() ([]byte, error) {
+	return marshalDUIDJSON(DUIDTypeLL, jsonDUIDLL{
+		HardwareType:     d.HardwareType,
+		LinkLayerAddress: formatHex(d.LinkLayerAddress, ":"),
+	})
+}
+
+// UnmarshalJSON decodes given JSON encoding of a DUIDLL
+func (d *DUIDLL) UnmarshalJSON(data []byte) error {
+	var f jsonDUIDLL
+	if err := unmarshalDUIDJSON(data, DUIDTypeLL, &f); err != nil {
+		return err
+	}
+	lla, err := parseHexJSON(f.LinkLayerAddress)
+	if err != nil {
+		return fmt.Errorf("invalid link-layer address: %s", err)
+	}
+
+	*d = DUIDLL{
+		HardwareType:     f.HardwareType,
+		LinkLayerAddress: lla,
+	}
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding of this DUIDUUID
+func (d DUIDUUID) MarshalJSON() ([]byte, error) {
+	type fields DUIDUUID
+	return marshalDUIDJSON(DUIDTypeUUID, fields(d))
+}
+
+// UnmarshalJSON decodes given JSON encoding of a DUIDUUID
+func (d *DUIDUUID) UnmarshalJSON(data []byte) error {
+	type fields DUIDUUID
+	var f fields
+	if err := unmarshalDUIDJSON(data, DUIDTypeUUID, &f); err != nil {
+		return err
+	}
+
+	*d = DUIDUUID(f)
+	return nil
+}
+
+// JSON fields of opaque DUIDs
+type jsonDUIDOpaque struct {
+	Data string
+}
+
+// MarshalJSON returns the JSON encoding of this DUIDOpaque, which contains the
+// entire DUID as colon separated hex
+func (d DUIDOpaque) MarshalJSON() ([]byte, error) {
+	return marshalDUIDJSON(d.Type(), jsonDUIDOpaque{
+		Data: formatHex(d.Data, ":"),
+	})
+}
+
+// UnmarshalJSON decodes given JSON encoding of a DUIDOpaque
+func (d *DUIDOpaque) UnmarshalJSON(data []byte) error {
+	var f jsonDUIDOpaque
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	b, err := parseHexJSON(f.Data)
+	if err != nil {
+		return fmt.Errorf("invalid DUID: %s", err)
+	}
+
+	*d = DUIDOpaque{Data: b}
+	return nil
+}
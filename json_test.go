@@ -0,0 +1,230 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMessageJSON(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	fixtuuid, _ := uuid.Parse("7e66eaa2-e6dd-497b-8e21-31944b282b43")
+
+	iana := &OptionIANA{IAID: 1, T1: time.Minute, T2: 2 * time.Minute}
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1"), PreferredLifetime: time.Hour, ValidLifetime: 2 * time.Hour})
+	iana.AddOption(&OptionStatusCode{Code: StatusCodeSuccess, Message: "all good"})
+	iapd := &OptionIAPD{IAID: 2}
+	iaprefix := &OptionIAPrefix{Prefix: net.ParseIP("2001:db8::"), PrefixLength: 48}
+	if err := iaprefix.SetExcludedPrefix(netip.MustParsePrefix("2001:db8:0:1::/64")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	iapd.AddOption(iaprefix)
+	lqquery := &OptionLQQuery{QueryType: QueryTypeByAddress, LinkAddress: net.ParseIP("2001:db8::")}
+	lqquery.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1")})
+	clientData := &OptionClientData{}
+	clientData.AddOption(&OptionClientID{DUID: &DUIDUUID{UUID: fixtuuid}})
+	clientData.AddOption(&OptionCLTTime{Time: time.Minute})
+	nexthop := &OptionNextHop{Address: net.ParseIP("fe80::1")}
+	nexthop.AddOption(&OptionRoutePrefix{Prefix: net.ParseIP("2001:db8::"), PrefixLength: 48, RouteLifetime: 3600, Preference: RoutePreferenceHigh})
+
+	msg := &Message{MessageType: MessageTypeReply, Xid: 123456}
+	for _, opt := range []Option{
+		&OptionClientID{DUID: &DUIDLLT{HardwareType: HardwareTypeEthernet, Time: time.Unix(1446684800, 0), LinkLayerAddress: fixtmac}},
+		&OptionServerID{DUID: &DUIDEN{EnterpriseNumber: 32473, ID: []byte{1, 2, 3}}},
+		iana,
+		&OptionOptionRequest{Options: []OptionType{OptionTypeDNSServer, OptionTypeDNSSearchList}},
+		&OptionElapsedTime{ElapsedTime: 120 * time.Millisecond},
+		&OptionRapidCommit{},
+		&OptionUserClass{classDataContainer{ClassData: []string{"foo", "bar"}}},
+		&OptionVendorClass{classDataContainer{ClassData: []string{"baz"}}, 32473},
+		&OptionDNSServer{Servers: []net.IP{net.ParseIP("2001:db8::53")}},
+		&OptionDNSSearchList{DomainNames: []string{"example.com"}},
+		iapd,
+		&OptionRemoteID{EnterpriseNumber: 32473, RemoteID: []byte{1, 2, 3}},
+		&OptionClientFQDN{Flags: ClientFQDNFlagS, DomainName: "host"},
+		lqquery,
+		clientData,
+		&OptionLQRelayData{PeerAddress: net.ParseIP("2001:db8::1"), RelayMessage: []byte{12, 0}},
+		&OptionLQClientLink{LinkAddresses: []net.IP{net.ParseIP("2001:db8::")}},
+		&OptionRelayID{DUID: &DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac}},
+		&OptionBootFileURL{URL: "tftp://[2001:db8::1]/boot.efi"},
+		&OptionBootFileParameters{Parameters: []string{"root=/dev/sda1", "quiet"}},
+		&OptionClientSystemArchitectureType{Types: []ArchitectureType{ArchitectureTypeEFIx8664}},
+		&OptionClientNetworkInterfaceIdentifier{InterfaceType: InterfaceTypeUNDI, RevisionMajor: 3, RevisionMinor: 16},
+		&OptionDHCPv4Message{Message: []byte{1, 2, 3, 4}},
+		&OptionDHCP4oDHCP6Server{Servers: []net.IP{net.ParseIP("2001:db8::67")}},
+		nexthop,
+		&OptionUnknown{Code: 300, Data: []byte{1, 2, 3}},
+	} {
+		msg.AddOption(opt)
+	}
+
+	// use a decoded message, like it was received from the network
+	fixtbyte, err := msg.Marshal()
+	if err != nil {
+		t.Fatalf("error marshalling message: %s", err)
+	}
+	msg, err = DecodeMessage(fixtbyte)
+	if err != nil {
+		t.Fatalf("error decoding message: %s", err)
+	}
+	if len(msg.Options) != 26 {
+		t.Fatalf("expected %d options, got %d", 26, len(msg.Options))
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("error marshalling JSON: %s", err)
+	}
+
+	// message from JSON should marshal to the same bytes
+	var jsonMsg Message
+	if err := json.Unmarshal(b, &jsonMsg); err != nil {
+		t.Fatalf("error unmarshalling JSON: %s", err)
+	}
+	if mshByte, err := jsonMsg.Marshal(); err != nil {
+		t.Errorf("error marshalling message: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+	if !msg.Equal(&jsonMsg) {
+		t.Error("expected message from JSON to be equal")
+	}
+}
+
+func TestOptionJSON(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	iana := &OptionIANA{IAID: 1, T1: time.Second}
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1")})
+
+	tests := []struct {
+		opt  Option
+		json string
+	}{
+		{
+			&OptionClientID{DUID: &DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac}},
+			`{"DUID":{"HardwareType":1,"LinkLayerAddress":"aa:bb:cc:dd:ee:ff","Name":"LinkLayer","Type":3},"Name":"Client Identifier","Type":1}`,
+		},
+		{
+			iana,
+			`{"IAID":1,"Name":"Identity Association for Non-temporary Addresses","Options":[{"Address":"2001:db8::1","Name":"Identity Association Address","PreferredLifetime":0,"Type":5,"ValidLifetime":0}],"T1":1000000000,"T2":0,"Type":3}`,
+		},
+		{
+			&OptionOptionRequest{Options: []OptionType{OptionTypeDNSServer, OptionTypeDNSSearchList}},
+			`{"Name":"Option Request","Options":[23,24],"Type":6}`,
+		},
+		{
+			&OptionRapidCommit{},
+			`{"Name":"Rapid Commit","Type":14}`,
+		},
+		{
+			&OptionDNSSearchList{DomainNames: []string{"example.com", "example.net"}},
+			`{"DomainNames":["example.com","example.net"],"Name":"DNS Search List","Type":24}`,
+		},
+		{
+			&OptionClientFQDN{Flags: ClientFQDNFlagS, DomainName: "host.example.com."},
+			`{"DomainName":"host.example.com.","Flags":1,"Name":"Client FQDN","Type":39}`,
+		},
+		{
+			&OptionUnknown{Code: 300, Data: []byte{1, 2}},
+			`{"Data":"01:02","Name":"Unknown","Type":300}`,
+		},
+		// options of known types that couldn't be decoded keep their data
+		{
+			&OptionUnknown{Code: OptionTypeDNSServer, Data: []byte{1, 2}},
+			`{"Data":"01:02","Name":"DNS Server","Type":23}`,
+		},
+	}
+
+	for _, test := range tests {
+		b, err := json.Marshal(Options{test.opt})
+		if err != nil {
+			t.Errorf("error marshalling %s: %s", test.opt.Type(), err)
+			continue
+		}
+		if string(b) != "["+test.json+"]" {
+			t.Errorf("unexpected JSON for %s:\nexpected: %s\ngot:      %s", test.opt.Type(), test.json, b)
+		}
+
+		var opts Options
+		if err := json.Unmarshal([]byte("["+test.json+"]"), &opts); err != nil {
+			t.Errorf("error unmarshalling %s: %s", test.opt.Type(), err)
+		} else if len(opts) != 1 || !EqualOptions(test.opt, opts[0]) {
+			t.Errorf("expected %s from JSON to be equal", test.opt.Type())
+		}
+	}
+
+	// test for error on unknown option type without data
+	var opts Options
+	if err := json.Unmarshal([]byte(`[{"Type":99}]`), &opts); err == nil {
+		t.Error("expected error unmarshalling unknown option type")
+	}
+	// test for error on requested option type out of range
+	if err := json.Unmarshal([]byte(`[{"Type":6,"Options":[65536]}]`), &opts); err == nil {
+		t.Error("expected error unmarshalling requested option type out of range")
+	}
+	// test for error on mismatching option type
+	var id OptionClientID
+	if err := json.Unmarshal([]byte(`{"Type":2,"DUID":{"Type":3}}`), &id); err == nil {
+		t.Error("expected error unmarshalling mismatching option type")
+	}
+}
+
+func TestDUIDJSON(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	fixtuuid, _ := uuid.Parse("7e66eaa2-e6dd-497b-8e21-31944b282b43")
+
+	tests := []struct {
+		duid DUID
+		json string
+	}{
+		{
+			&DUIDLLT{HardwareType: HardwareTypeEthernet, Time: DUIDTime(500000000).Time(), LinkLayerAddress: fixtmac},
+			`{"HardwareType":1,"LinkLayerAddress":"aa:bb:cc:dd:ee:ff","Name":"LinkLayerTime","Time":"2015-11-05T00:53:20Z","Type":1}`,
+		},
+		{
+			&DUIDEN{EnterpriseNumber: 32473, ID: []byte{1, 2, 3}},
+			`{"EnterpriseNumber":32473,"ID":"01:02:03","Name":"Enterprise Number","Type":2}`,
+		},
+		{
+			&DUIDLL{HardwareType: HardwareTypeFrameRelay, LinkLayerAddress: []byte{1, 2}},
+			`{"HardwareType":15,"LinkLayerAddress":"01:02","Name":"LinkLayer","Type":3}`,
+		},
+		{
+			&DUIDUUID{UUID: fixtuuid},
+			`{"Name":"UUID","Type":4,"UUID":"7e66eaa2-e6dd-497b-8e21-31944b282b43"}`,
+		},
+		{
+			&DUIDOpaque{Data: []byte{0, 99, 1, 2}},
+			`{"Data":"00:63:01:02","Name":"Unknown","Type":99}`,
+		},
+	}
+
+	for _, test := range tests {
+		b, err := json.Marshal(test.duid)
+		if err != nil {
+			t.Errorf("error marshalling %s: %s", test.duid.Type(), err)
+			continue
+		}
+		if string(b) != test.json {
+			t.Errorf("unexpected JSON for %s:\nexpected: %s\ngot:      %s", test.duid.Type(), test.json, b)
+		}
+
+		duid, err := DecodeDUIDJSON(b)
+		if err != nil {
+			t.Errorf("error decoding %s: %s", test.duid.Type(), err)
+		} else if !EqualDUIDs(test.duid, duid) {
+			t.Errorf("expected %s from JSON to be equal", test.duid.Type())
+		}
+	}
+
+	// test for error on invalid hex
+	if _, err := DecodeDUIDJSON([]byte(`{"Type":3,"HardwareType":1,"LinkLayerAddress":"zz"}`)); err == nil {
+		t.Error("expected error decoding invalid link-layer address")
+	}
+}
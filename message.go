@@ -1,6 +1,7 @@
 package dhcpv6
 
 import (
+	"crypto/rand"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -8,15 +9,19 @@ import (
 
 var (
 	errMessageTooShort = errors.New("message too short")
+	errInvalidXid      = errors.New("transaction-id exceeds 24 bits")
+	errInvalidFlags    = errors.New("flags exceed 24 bits")
 	typeUnknown        = "Unknown"
 )
 
 // MessageType describes DHCPv6 message types
 type MessageType uint8
 
-// add constants for all DHCPv6 message types from RFC3315
+// add constants for all DHCPv6 message types from RFC3315, RFC5007, RFC5460 and
+// RFC7341
 const (
 	_ MessageType = iota
+	// RFC3315
 	MessageTypeSolicit
 	MessageTypeAdvertise
 	MessageTypeRequest
@@ -30,49 +35,102 @@ const (
 	MessageTypeInformationRequest
 	MessageTypeRelayForward
 	MessageTypeRelayReply
+	// RFC5007
+	MessageTypeLeasequery
+	MessageTypeLeasequeryReply
+	// RFC5460
+	MessageTypeLeasequeryDone
+	MessageTypeLeasequeryData
+	// RFC7341
+	MessageTypeDHCPv4Query    MessageType = 20
+	MessageTypeDHCPv4Response MessageType = 21
 )
 
+// DHCPv4QueryFlagUnicast is the unicast flag of a DHCPv4-query message as
+// described at https://tools.ietf.org/html/rfc7341#section-6.1
+const DHCPv4QueryFlagUnicast uint32 = 1 << 23
+
 func (t MessageType) String() string {
-	name := func() string {
-		switch t {
-		case MessageTypeSolicit:
-			return "Solicit"
-		case MessageTypeAdvertise:
-			return "Advertise"
-		case MessageTypeRequest:
-			return "Request"
-		case MessageTypeConfirm:
-			return "Confirm"
-		case MessageTypeRenew:
-			return "Renew"
-		case MessageTypeRebind:
-			return "Rebind"
-		case MessageTypeReply:
-			return "Reply"
-		case MessageTypeRelease:
-			return "Release"
-		case MessageTypeDecline:
-			return "Decline"
-		case MessageTypeReconfigure:
-			return "Reconfigure"
-		case MessageTypeInformationRequest:
-			return "Information Request"
-		case MessageTypeRelayForward:
-			return "Relay Forward"
-		case MessageTypeRelayReply:
-			return "Relay Reply"
-		default:
-			return typeUnknown
-		}
+	return fmt.Sprintf("%s (%d)", t.name(), t)
+}
+
+// name returns the name of this type without its code
+func (t MessageType) name() string {
+	switch t {
+	case MessageTypeSolicit:
+		return "Solicit"
+	case MessageTypeAdvertise:
+		return "Advertise"
+	case MessageTypeRequest:
+		return "Request"
+	case MessageTypeConfirm:
+		return "Confirm"
+	case MessageTypeRenew:
+		return "Renew"
+	case MessageTypeRebind:
+		return "Rebind"
+	case MessageTypeReply:
+		return "Reply"
+	case MessageTypeRelease:
+		return "Release"
+	case MessageTypeDecline:
+		return "Decline"
+	case MessageTypeReconfigure:
+		return "Reconfigure"
+	case MessageTypeInformationRequest:
+		return "Information Request"
+	case MessageTypeRelayForward:
+		return "Relay Forward"
+	case MessageTypeRelayReply:
+		return "Relay Reply"
+	case MessageTypeLeasequery:
+		return "Leasequery"
+	case MessageTypeLeasequeryReply:
+		return "Leasequery Reply"
+	case MessageTypeLeasequeryDone:
+		return "Leasequery Done"
+	case MessageTypeLeasequeryData:
+		return "Leasequery Data"
+	case MessageTypeDHCPv4Query:
+		return "DHCPv4 Query"
+	case MessageTypeDHCPv4Response:
+		return "DHCPv4 Response"
+	default:
+		return typeUnknown
 	}
-	return fmt.Sprintf("%s (%d)", name(), t)
+}
+
+// hasFlags returns true if messages of this type carry a flags field instead of
+// a transaction-id, as DHCPv4-over-DHCPv6 messages do
+func (t MessageType) hasFlags() bool {
+	return t == MessageTypeDHCPv4Query || t == MessageTypeDHCPv4Response
+}
+
+// MaxTransactionID is the highest transaction-id that fits in the 3 bytes a
+// message has for it
+const MaxTransactionID uint32 = 1<<24 - 1
+
+// NewTransactionID returns a random transaction-id from crypto/rand. As
+// described at https://tools.ietf.org/html/rfc8415#section-16.1 the
+// transaction-id should be chosen to be as unpredictable as possible
+func NewTransactionID() (uint32, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b[1:4]); err != nil {
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint32(b), nil
 }
 
 // Message represents a DHCPv6 message
 type Message struct {
 	MessageType MessageType
-	Xid         uint32
-	Options     Options
+	// Xid is the transaction-id of the message, which can be no larger than
+	// MaxTransactionID
+	Xid uint32
+	// Flags is used instead of Xid by DHCPv4-query and DHCPv4-response messages
+	Flags   uint32
+	Options Options
 }
 
 // HasOption returns Option if this Message has OptionType t as option or
@@ -92,14 +150,69 @@ func (m *Message) AddOption(o Option) {
 	m.Options = append(m.Options, o)
 }
 
+// SetOption sets given Option to slice of Options of Message, replacing first
+// potential duplicate option of the same type
+func (m *Message) SetOption(o Option) {
+	m.Options = m.Options.set(o)
+}
+
+// RemoveOption removes the first occurance of option with type t from slice of
+// Options of Message
+func (m *Message) RemoveOption(t OptionType) {
+	m.Options = m.Options.remove(t, false)
+}
+
+// DelOption removes all options with type t from slice of Options of Message
+func (m *Message) DelOption(t OptionType) {
+	m.Options = m.Options.remove(t, true)
+}
+
+// Clone returns a deep copy of this Message, sharing no memory with the
+// original
+func (m Message) Clone() *Message {
+	m.Options = m.Options.Clone()
+
+	return &m
+}
+
+// Equal returns true if given Message has the same type, transaction-id, flags
+// and options in the same order as this Message
+func (m Message) Equal(other *Message) bool {
+	if other == nil || m.MessageType != other.MessageType || m.Xid != other.Xid ||
+		m.Flags != other.Flags || len(m.Options) != len(other.Options) {
+		return false
+	}
+
+	for i, opt := range m.Options {
+		if !EqualOptions(opt, other.Options[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Marshal returns byte slice representing this Message or error
 func (m Message) Marshal() ([]byte, error) {
+	// the transaction-id and flags have only 3 bytes to fit in
+	if m.MessageType.hasFlags() {
+		if m.Flags > MaxTransactionID {
+			return nil, errInvalidFlags
+		}
+	} else if m.Xid > MaxTransactionID {
+		return nil, errInvalidXid
+	}
+
 	// prepare byte slice of appropriate length
 	b := make([]byte, 4)
-	// set transaction-id and then message type
+	// set transaction-id (or flags) and then message type
 	// the other way around would be more logical, but since transaction-id is
 	// 3 bytes, this way is easier
-	binary.BigEndian.PutUint32(b[0:4], m.Xid)
+	if m.MessageType.hasFlags() {
+		binary.BigEndian.PutUint32(b[0:4], m.Flags)
+	} else {
+		binary.BigEndian.PutUint32(b[0:4], m.Xid)
+	}
 	b[0] = uint8(m.MessageType)
 	// append option bytes
 	if len(m.Options) > 0 {
@@ -125,7 +238,11 @@ func DecodeMessage(data []byte) (*Message, error) {
 	d := &Message{
 		MessageType: MessageType(data[0]),
 	}
-	d.Xid = binary.BigEndian.Uint32(append([]byte{0}, data[1:4]...))
+	if d.MessageType.hasFlags() {
+		d.Flags = binary.BigEndian.Uint32(append([]byte{0}, data[1:4]...))
+	} else {
+		d.Xid = binary.BigEndian.Uint32(append([]byte{0}, data[1:4]...))
+	}
 
 	// additional options to decode
 	if len(data) > 4 {
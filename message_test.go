@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestMessageTypeString(t *testing.T) {
@@ -25,6 +26,12 @@ func TestMessageTypeString(t *testing.T) {
 		{MessageTypeInformationRequest, "Information Request (11)"},
 		{MessageTypeRelayForward, "Relay Forward (12)"},
 		{MessageTypeRelayReply, "Relay Reply (13)"},
+		{MessageTypeLeasequery, "Leasequery (14)"},
+		{MessageTypeLeasequeryReply, "Leasequery Reply (15)"},
+		{MessageTypeLeasequeryDone, "Leasequery Done (16)"},
+		{MessageTypeLeasequeryData, "Leasequery Data (17)"},
+		{MessageTypeDHCPv4Query, "DHCPv4 Query (20)"},
+		{MessageTypeDHCPv4Response, "DHCPv4 Response (21)"},
 	}
 
 	for _, test := range tests {
@@ -75,6 +82,18 @@ func TestDecodeMessage(t *testing.T) {
 			MessageTypeReply, 678901, []OptionType{OptionTypeRapidCommit},
 			OptionTypeElapsedTime,
 		},
+		// Leasequery
+		{
+			[]byte{14, 12, 34, 56, 0, 44, 0, 17, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			MessageTypeLeasequery, 795192, []OptionType{OptionTypeLQQuery},
+			OptionTypeClientData,
+		},
+		// Leasequery Reply
+		{
+			[]byte{15, 12, 34, 56, 0, 45, 0, 8, 0, 46, 0, 4, 0, 0, 0, 60},
+			MessageTypeLeasequeryReply, 795192, []OptionType{OptionTypeClientData},
+			OptionTypeLQQuery,
+		},
 	}
 
 	for _, test := range tests {
@@ -135,3 +154,231 @@ func TestMessageAddOption(t *testing.T) {
 		t.Errorf("message should have option of type: %s", opt.Type())
 	}
 }
+
+func TestMessageSetRemoveDelOption(t *testing.T) {
+	msg := &Message{MessageType: MessageTypeSolicit}
+	msg.AddOption(&OptionElapsedTime{})
+	msg.AddOption(&OptionIANA{IAID: 1})
+	msg.AddOption(&OptionIANA{IAID: 2})
+
+	// SetOption should replace the existing elapsed time
+	msg.SetOption(&OptionElapsedTime{ElapsedTime: time.Second})
+	if et, _ := Get[*OptionElapsedTime](msg.Options); et.ElapsedTime != time.Second {
+		t.Errorf("expected elapsed time %s, got %s", time.Second, et.ElapsedTime)
+	}
+	// and add options that aren't there yet
+	msg.SetOption(&OptionRapidCommit{})
+	if len(msg.Options) != 4 {
+		t.Errorf("expected %d options, got %d", 4, len(msg.Options))
+	}
+
+	// RemoveOption should only remove the first IA_NA
+	msg.RemoveOption(OptionTypeIANA)
+	if ianas := All[*OptionIANA](msg.Options); len(ianas) != 1 || ianas[0].IAID != 2 {
+		t.Errorf("expected only second IA_NA left, got %v", ianas)
+	}
+
+	// DelOption should remove all IA_NAs
+	msg.AddOption(&OptionIANA{IAID: 3})
+	msg.DelOption(OptionTypeIANA)
+	if msg.HasOption(OptionTypeIANA) != nil {
+		t.Error("expected no IA_NAs left")
+	}
+	if len(msg.Options) != 2 {
+		t.Errorf("expected %d options, got %d", 2, len(msg.Options))
+	}
+}
+
+func TestDecodeMessageDHCPv4Query(t *testing.T) {
+	// DHCPv4-query with the unicast flag set and an empty DHCPv4 Message option
+	fixtbyte := []byte{20, 128, 0, 0, 0, 87, 0, 0}
+	msg, err := DecodeMessage(fixtbyte)
+	if err != nil {
+		t.Fatalf("could not decode fixture: %s", err)
+	}
+
+	if msg.MessageType != MessageTypeDHCPv4Query {
+		t.Errorf("expected type %s, got %s", MessageTypeDHCPv4Query, msg.MessageType)
+	}
+	// flags should be decoded instead of the transaction-id
+	if msg.Flags != DHCPv4QueryFlagUnicast {
+		t.Errorf("expected flags %#x, got %#x", DHCPv4QueryFlagUnicast, msg.Flags)
+	}
+	if msg.Xid != 0 {
+		t.Errorf("expected no XID, got %d", msg.Xid)
+	}
+	if msg.HasOption(OptionTypeDHCPv4Message) == nil {
+		t.Errorf("expected msg to have %s", OptionTypeDHCPv4Message)
+	}
+
+	// check if marshal matches
+	if mshByte, err := msg.Marshal(); err != nil {
+		t.Errorf("error marshalling message: %s", err)
+	} else if !bytes.Equal(mshByte, fixtbyte) {
+		t.Errorf("marshalled message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+}
+
+func TestDecodeMessageUnknownDUID(t *testing.T) {
+	// Solicit with a client-ID and server-ID of an unknown DUID type
+	fixtbyte := []byte{1, 1, 226, 64, 0, 1, 0, 6, 0, 99, 1, 2, 3, 4, 0, 2, 0, 6, 0, 99, 1, 2, 3, 4}
+	msg, err := DecodeMessage(fixtbyte)
+	if err != nil {
+		t.Fatalf("could not decode fixture: %s", err)
+	}
+
+	clientID := msg.HasOption(OptionTypeClientID)
+	if clientID == nil {
+		t.Fatal("expected msg to have client-ID")
+	}
+	if clientID.(*OptionClientID).DUID.Type() != 99 {
+		t.Errorf("unexpected DUID type: %s", clientID.(*OptionClientID).DUID.Type())
+	}
+
+	// DUIDs of unknown type should still compare
+	serverID := msg.HasOption(OptionTypeServerID).(*OptionServerID)
+	if !EqualOptions(serverID, &OptionServerID{DUID: &DUIDOpaque{Data: []byte{0, 99, 1, 2, 3, 4}}}) {
+		t.Error("expected server-ID to be equal")
+	}
+	if EqualOptions(serverID, &OptionServerID{DUID: &DUIDOpaque{Data: []byte{0, 99, 1, 2, 3, 5}}}) {
+		t.Error("expected server-ID not to be equal")
+	}
+
+	// check if marshal matches
+	if mshByte, err := msg.Marshal(); err != nil {
+		t.Errorf("error marshalling message: %s", err)
+	} else if !bytes.Equal(mshByte, fixtbyte) {
+		t.Errorf("marshalled message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+}
+
+func TestDecodeMessageMismatchedDUID(t *testing.T) {
+	// Solicit with a DUID-LL for Ethernet holding an 8 byte address
+	fixtbyte := []byte{1, 1, 226, 64, 0, 1, 0, 12, 0, 3, 0, 1, 170, 187, 204, 221, 238, 255, 0, 1}
+	msg, err := DecodeMessage(fixtbyte)
+	if err != nil {
+		t.Fatalf("could not decode fixture: %s", err)
+	}
+
+	clientID, ok := Get[*OptionClientID](msg.Options)
+	if !ok {
+		t.Fatal("expected msg to have client-ID")
+	}
+	if _, ok := clientID.DUID.(*DUIDLL); !ok {
+		t.Errorf("expected DUID-LL, got %T", clientID.DUID)
+	}
+
+	// check if marshal matches
+	if mshByte, err := msg.Marshal(); err != nil {
+		t.Errorf("error marshalling message: %s", err)
+	} else if !bytes.Equal(mshByte, fixtbyte) {
+		t.Errorf("marshalled message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+}
+
+func TestDecodeMessageMalformedDomainName(t *testing.T) {
+	// Solicit with a Domain Search List holding a partial name
+	fixtbyte := []byte{1, 0, 0, 1, 0, 24, 0, 4, 3, 102, 111, 111}
+	msg, err := DecodeMessage(fixtbyte)
+	if err != nil {
+		t.Fatalf("could not decode fixture: %s", err)
+	}
+	if _, ok := Get[*OptionUnknown](msg.Options); !ok {
+		t.Errorf("expected malformed option to be kept as OptionUnknown, got %s", msg.Options)
+	}
+	if mshByte, err := msg.Marshal(); err != nil {
+		t.Errorf("error marshalling message: %s", err)
+	} else if !bytes.Equal(mshByte, fixtbyte) {
+		t.Errorf("marshalled message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+}
+
+func TestNewTransactionID(t *testing.T) {
+	// transaction-ids should always fit and should not repeat
+	seen := make(map[uint32]bool)
+	for i := 0; i < 100; i++ {
+		xid, err := NewTransactionID()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if xid > MaxTransactionID {
+			t.Errorf("expected 24 bit transaction-id, got %d", xid)
+		}
+		seen[xid] = true
+	}
+	if len(seen) < 90 {
+		t.Errorf("expected random transaction-ids, got %d unique out of 100", len(seen))
+	}
+}
+
+func TestMessageMarshalTransactionID(t *testing.T) {
+	// highest transaction-id should still marshal
+	msg := &Message{MessageType: MessageTypeSolicit, Xid: MaxTransactionID}
+	fixtbyte := []byte{1, 255, 255, 255}
+	if mshByte, err := msg.Marshal(); err != nil {
+		t.Errorf("error marshalling message: %s", err)
+	} else if !bytes.Equal(mshByte, fixtbyte) {
+		t.Errorf("marshalled message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// test for error when transaction-id doesn't fit
+	msg.Xid = MaxTransactionID + 1
+	if _, err := msg.Marshal(); err == nil {
+		t.Error("expected error marshalling message with too large transaction-id")
+	} else if err != errInvalidXid {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// test for error when flags don't fit
+	msg = &Message{MessageType: MessageTypeDHCPv4Query, Flags: 1 << 24}
+	if _, err := msg.Marshal(); err == nil {
+		t.Error("expected error marshalling message with too large flags")
+	} else if err != errInvalidFlags {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestMessageCloneEqual(t *testing.T) {
+	// Advertise with an IA_NA containing an address
+	fixtbyte := []byte{2, 3, 148, 71, 0, 3, 0, 40, 0, 250, 153, 31, 0, 0, 1, 44, 0, 0, 1, 194,
+		0, 5, 0, 24, 32, 1, 13, 184, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 2, 88, 0, 0, 3, 132}
+	msg, err := DecodeMessage(fixtbyte)
+	if err != nil {
+		t.Fatalf("could not decode fixture: %s", err)
+	}
+
+	clone := msg.Clone()
+	if !msg.Equal(clone) || !clone.Equal(msg) {
+		t.Error("expected clone to be equal")
+	}
+
+	// modifying the clone should not affect the original or the decoded bytes
+	iana, _ := Get[*OptionIANA](clone.Options)
+	iana.HasOption(OptionTypeIAAddress).(*OptionIAAddress).Address[15] = 2
+	if msg.Equal(clone) {
+		t.Error("expected modified clone not to be equal")
+	}
+	if fixtbyte[39] != 1 {
+		t.Error("modifying clone changed decoded bytes")
+	}
+	if mshByte, err := msg.Marshal(); err != nil {
+		t.Errorf("error marshalling message: %s", err)
+	} else if !bytes.Equal(mshByte, fixtbyte) {
+		t.Errorf("marshalled message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// compare other message fields
+	clone = msg.Clone()
+	clone.Xid++
+	if msg.Equal(clone) {
+		t.Error("expected messages with different transaction-id not to be equal")
+	}
+	clone = msg.Clone()
+	clone.AddOption(&OptionRapidCommit{})
+	if msg.Equal(clone) {
+		t.Error("expected messages with different options not to be equal")
+	}
+	if msg.Equal(nil) {
+		t.Error("expected message not to be equal to nil")
+	}
+}
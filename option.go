@@ -5,7 +5,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"net"
+	"net/netip"
 	"strings"
 	"time"
 )
@@ -13,6 +15,19 @@ import (
 var (
 	errOptionTooShort = errors.New("option too short")
 	errOptionTooLong  = errors.New("option too long")
+
+	errInvalidIPv6Address     = errors.New("invalid IPv6 address")
+	errInvalidPrefixLength    = errors.New("invalid prefix length")
+	errInvalidRoutePreference = errors.New("invalid route preference")
+	errInvalidPDExclude       = errors.New("excluded prefix not within delegated prefix")
+	errInvalidDomainName      = errors.New("invalid domain name")
+)
+
+// limits of domain names in DNS wire format as described at
+// https://tools.ietf.org/html/rfc1035#section-2.3.4
+const (
+	maxDomainLabelLen = 63
+	maxDomainNameLen  = 255
 )
 
 // options that contain options themselves can use optionContainer for easy
@@ -42,20 +57,35 @@ func (o *optionContainer) AddOption(opt Option) {
 // SetOption sets given Option to slice of Options, replacing first potential
 // duplicate option of the same type
 func (o *optionContainer) SetOption(newopt Option) {
-	for i, opt := range o.options {
-		if opt.Type() == newopt.Type() {
-			o.options[i] = newopt
-			return
-		}
-	}
+	o.options = o.options.set(newopt)
+}
 
-	o.options = append(o.options, newopt)
+// RemoveOption removes the first occurance of option with type t from slice of
+// Options
+func (o *optionContainer) RemoveOption(t OptionType) {
+	o.options = o.options.remove(t, false)
+}
+
+// DelOption removes all options with type t from slice of Options
+func (o *optionContainer) DelOption(t OptionType) {
+	o.options = o.options.remove(t, true)
+}
+
+// Options returns the options contained in this option
+func (o optionContainer) Options() Options {
+	return o.options
+}
+
+// helper function to replace the options contained in this option
+func (o *optionContainer) setOptions(opts Options) {
+	o.options = opts
 }
 
 // OptionType describes DHCPv6 option types
-type OptionType uint8
+type OptionType uint16
 
-// DHCPv6 option types as described in RFC's 3315, 3646, 5970 and a draft for
+// DHCPv6 option types as described in RFC's 3315, 3633, 3646, 4649, 5007, 5460,
+// 5970, 6603, 7341 and a draft for
 // Route Options
 const (
 	_ OptionType = iota
@@ -85,74 +115,122 @@ const (
 	// RFC3646
 	OptionTypeDNSServer
 	OptionTypeDNSSearchList
+	// RFC3633
+	OptionTypeIAPD     OptionType = 25
+	OptionTypeIAPrefix OptionType = 26
+	// RFC4649
+	OptionTypeRemoteID OptionType = 37
+	// RFC4704
+	OptionTypeClientFQDN OptionType = 39
+	// RFC5007
+	OptionTypeLQQuery      OptionType = 44
+	OptionTypeClientData   OptionType = 45
+	OptionTypeCLTTime      OptionType = 46
+	OptionTypeLQRelayData  OptionType = 47
+	OptionTypeLQClientLink OptionType = 48
+	// RFC5460
+	OptionTypeRelayID OptionType = 53
 	// RFC5970
 	OptionTypeBootFileURL                      OptionType = 59
 	OptionTypeBootFileParameters               OptionType = 60
 	OptionTypeClientSystemArchitectureType     OptionType = 61
 	OptionTypeClientNetworkInterfaceIdentifier OptionType = 62
+	// RFC6603
+	OptionTypePDExclude OptionType = 67
+	// RFC7341
+	OptionTypeDHCPv4Message     OptionType = 87
+	OptionTypeDHCP4oDHCP6Server OptionType = 88
 	// draft-ietf-mif-dhcpv6-route-option
 	OptionTypeNextHop     OptionType = 242
 	OptionTypeRoutePrefix OptionType = 243
 )
 
 func (t OptionType) String() string {
-	name := func() string {
-		switch t {
-		case OptionTypeClientID:
-			return "Client Identifier"
-		case OptionTypeServerID:
-			return "Server Identifier"
-		case OptionTypeIANA:
-			return "Identity Association for Non-temporary Addresses"
-		case OptionTypeIATA:
-			return "Identity Association for Temporary Addresses"
-		case OptionTypeIAAddress:
-			return "Identity Association Address"
-		case OptionTypeOptionRequest:
-			return "Option Request"
-		case OptionTypePreference:
-			return "Preference"
-		case OptionTypeElapsedTime:
-			return "Elapsed Time"
-		case OptionTypeRelayMessage:
-			return "Relay Message"
-		case OptionTypeAuthentication:
-			return "Authentication"
-		case OptionTypeServerUnicast:
-			return "Server Unicast"
-		case OptionTypeStatusCode:
-			return "Status Code"
-		case OptionTypeRapidCommit:
-			return "Rapid Commit"
-		case OptionTypeUserClass:
-			return "User Class"
-		case OptionTypeVendorClass:
-			return "Vendor Class"
-		case OptionTypeVendorOption:
-			return "Vendor-specific Information"
-		case OptionTypeInterfaceID:
-			return "Interface-ID"
-		case OptionTypeReconfigureMessage:
-			return "Reconfigure Message"
-		case OptionTypeReconfigureAccept:
-			return "Reconfigure Accept"
-		case OptionTypeDNSServer:
-			return "DNS Server"
-		case OptionTypeDNSSearchList:
-			return "DNS Search List"
-		case OptionTypeBootFileURL:
-			return "Boot File URL"
-		case OptionTypeBootFileParameters:
-			return "Boot File Parameters"
-		case OptionTypeNextHop:
-			return "Next Hop"
-		case OptionTypeRoutePrefix:
-			return "Route Prefix"
-		default:
-			return typeUnknown
-		}
+	return fmt.Sprintf("%s (%d)", t.name(), t)
+}
+
+// name returns the name of this type without its code
+func (t OptionType) name() string {
+	switch t {
+	case OptionTypeClientID:
+		return "Client Identifier"
+	case OptionTypeServerID:
+		return "Server Identifier"
+	case OptionTypeIANA:
+		return "Identity Association for Non-temporary Addresses"
+	case OptionTypeIATA:
+		return "Identity Association for Temporary Addresses"
+	case OptionTypeIAAddress:
+		return "Identity Association Address"
+	case OptionTypeOptionRequest:
+		return "Option Request"
+	case OptionTypePreference:
+		return "Preference"
+	case OptionTypeElapsedTime:
+		return "Elapsed Time"
+	case OptionTypeRelayMessage:
+		return "Relay Message"
+	case OptionTypeAuthentication:
+		return "Authentication"
+	case OptionTypeServerUnicast:
+		return "Server Unicast"
+	case OptionTypeStatusCode:
+		return "Status Code"
+	case OptionTypeRapidCommit:
+		return "Rapid Commit"
+	case OptionTypeUserClass:
+		return "User Class"
+	case OptionTypeVendorClass:
+		return "Vendor Class"
+	case OptionTypeVendorOption:
+		return "Vendor-specific Information"
+	case OptionTypeInterfaceID:
+		return "Interface-ID"
+	case OptionTypeReconfigureMessage:
+		return "Reconfigure Message"
+	case OptionTypeReconfigureAccept:
+		return "Reconfigure Accept"
+	case OptionTypeDNSServer:
+		return "DNS Server"
+	case OptionTypeDNSSearchList:
+		return "DNS Search List"
+	case OptionTypeIAPD:
+		return "Identity Association for Prefix Delegation"
+	case OptionTypeIAPrefix:
+		return "Identity Association Prefix"
+	case OptionTypeRemoteID:
+		return "Remote-ID"
+	case OptionTypeClientFQDN:
+		return "Client FQDN"
+	case OptionTypeLQQuery:
+		return "Leasequery Query"
+	case OptionTypeClientData:
+		return "Client Data"
+	case OptionTypeCLTTime:
+		return "Client Last Transaction Time"
+	case OptionTypeLQRelayData:
+		return "Leasequery Relay Data"
+	case OptionTypeLQClientLink:
+		return "Leasequery Client Link"
+	case OptionTypeRelayID:
+		return "Relay-ID"
+	case OptionTypeBootFileURL:
+		return "Boot File URL"
+	case OptionTypeBootFileParameters:
+		return "Boot File Parameters"
+	case OptionTypePDExclude:
+		return "Prefix Exclude"
+	case OptionTypeDHCPv4Message:
+		return "DHCPv4 Message"
+	case OptionTypeDHCP4oDHCP6Server:
+		return "DHCP4o6 Server Address"
+	case OptionTypeNextHop:
+		return "Next Hop"
+	case OptionTypeRoutePrefix:
+		return "Route Prefix"
+	default:
+		return typeUnknown
 	}
-	return fmt.Sprintf("%s (%d)", name(), t)
 }
 
 // Option -- interface to build various DHCPv6 options on
@@ -199,6 +277,126 @@ func (o Options) Len() uint16 {
 	return l
 }
 
+// Clone returns a deep copy of all Options in slice
+func (o Options) Clone() Options {
+	if o == nil {
+		return nil
+	}
+
+	c := make(Options, len(o))
+	for i, opt := range o {
+		c[i] = CloneOption(opt)
+	}
+
+	return c
+}
+
+// Get returns the first option in opts of type T, for instance *OptionIANA, and
+// true or the zero value of T and false if opts has no option of that type
+func Get[T Option](opts Options) (T, bool) {
+	for _, opt := range opts {
+		if o, ok := opt.(T); ok {
+			return o, true
+		}
+	}
+
+	var none T
+	return none, false
+}
+
+// All returns all options in opts of type T, for instance *OptionIANA
+func All[T Option](opts Options) []T {
+	var all []T
+	for _, opt := range opts {
+		if o, ok := opt.(T); ok {
+			all = append(all, o)
+		}
+	}
+
+	return all
+}
+
+// helper function returning opts with newopt replacing the first option of the
+// same type or appended when there is none
+func (o Options) set(newopt Option) Options {
+	for i, opt := range o {
+		if opt.Type() == newopt.Type() {
+			o[i] = newopt
+			return o
+		}
+	}
+
+	return append(o, newopt)
+}
+
+// helper function returning opts without the first or all options of type t
+func (o Options) remove(t OptionType, all bool) Options {
+	var list Options
+	for i, opt := range o {
+		if opt.Type() == t {
+			if !all {
+				return append(list, o[i+1:]...)
+			}
+			continue
+		}
+		list = append(list, opt)
+	}
+
+	return list
+}
+
+// EqualOptions returns true if a and b are the same option, meaning they are
+// of the same type and marshal to the same bytes. Options that can't be
+// marshalled are compared field by field instead, so an invalid option is still
+// equal to itself. A nil option is never equal to anything
+func EqualOptions(a, b Option) bool {
+	if a == nil || b == nil || a.Type() != b.Type() {
+		return false
+	}
+
+	ab, aerr := a.Marshal()
+	bb, berr := b.Marshal()
+	if aerr != nil || berr != nil {
+		return fieldsEqual(a, b)
+	}
+
+	return bytes.Equal(ab, bb)
+}
+
+// CloneOption returns a deep copy of o, sharing no memory with o. All options
+// of this package have a Clone method for this; options implemented elsewhere
+// without a Clone() Option method are returned as is
+func CloneOption(o Option) Option {
+	if c, ok := o.(interface{ Clone() Option }); ok {
+		return c.Clone()
+	}
+
+	return o
+}
+
+// helper function returning a copy of ip that doesn't share memory with ip
+func cloneIP(ip net.IP) net.IP {
+	if ip == nil {
+		return nil
+	}
+
+	return append(net.IP(nil), ip...)
+}
+
+// helper function returning a deep copy of ips
+func cloneIPs(ips []net.IP) []net.IP {
+	if ips == nil {
+		return nil
+	}
+
+	c := make([]net.IP, len(ips))
+	for i, ip := range ips {
+		c[i] = cloneIP(ip)
+	}
+
+	return c
+}
+
 // OptionClientID implements the Client Identifier option as described at
 // https://tools.ietf.org/html/rfc3315#section-22.2
 type OptionClientID struct {
@@ -238,6 +436,13 @@ func (o OptionClientID) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionClientID
+func (o OptionClientID) Clone() Option {
+	o.DUID = CloneDUID(o.DUID)
+
+	return &o
+}
+
 // OptionServerID implements the Server Identifier option as described at
 // https://tools.ietf.org/html/rfc3315#section-22.3
 type OptionServerID struct {
@@ -277,23 +482,11 @@ func (o OptionServerID) Marshal() ([]byte, error) {
 	return b, nil
 }
 
-// Equal returns true if given ServerID option is byte-wise identical or false
-// otherwise
-func (o OptionServerID) Equal(opt Option) bool {
-	if opt.Type() != OptionTypeServerID {
-		return false
-	}
-
-	optb, err := opt.Marshal()
-	if err != nil {
-		return false
-	}
-	myb, err := o.Marshal()
-	if err != nil {
-		return false
-	}
+// Clone returns a deep copy of this OptionServerID
+func (o OptionServerID) Clone() Option {
+	o.DUID = CloneDUID(o.DUID)
 
-	return bytes.Equal(optb, myb)
+	return &o
 }
 
 // OptionIANA implements the Identity Association for Non-temporary Addresses
@@ -353,6 +546,14 @@ func (o *OptionIANA) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionIANA
+func (o *OptionIANA) Clone() Option {
+	c := *o
+	c.options = o.options.Clone()
+
+	return &c
+}
+
 // OptionIAAddress implements the IA Address option as described at
 // https://tools.ietf.org/html/rfc3315#section-22.6
 type OptionIAAddress struct {
@@ -413,6 +614,14 @@ func (o OptionIAAddress) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionIAAddress
+func (o OptionIAAddress) Clone() Option {
+	o.options = o.options.Clone()
+	o.Address = cloneIP(o.Address)
+
+	return &o
+}
+
 // OptionOptionRequest implements the Option Request option as described at
 // https://tools.ietf.org/html/rfc3315#section-22.7
 type OptionOptionRequest struct {
@@ -452,6 +661,13 @@ func (o OptionOptionRequest) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionOptionRequest
+func (o OptionOptionRequest) Clone() Option {
+	o.Options = append([]OptionType(nil), o.Options...)
+
+	return &o
+}
+
 // HasOption returns Option if this IA_NA option has OptionType t as option or
 // nil otherwise
 func (o OptionOptionRequest) HasOption(t OptionType) bool {
@@ -515,16 +731,40 @@ func (o OptionElapsedTime) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionElapsedTime
+func (o OptionElapsedTime) Clone() Option {
+	return &o
+}
+
 type StatusCode uint16
 
-// Status codes as described at https://tools.ietf.org/html/rfc3315#section-24.4
+// Status codes as described at https://tools.ietf.org/html/rfc3315#section-24.4,
+// https://tools.ietf.org/html/rfc3633#section-13,
+// https://tools.ietf.org/html/rfc5007#section-5.4,
+// https://tools.ietf.org/html/rfc5460#section-5.3 and
+// https://tools.ietf.org/html/rfc7653
 const (
+	// RFC3315
 	StatusCodeSuccess StatusCode = iota
 	StatusCodeUnspecFail
 	StatusCodeNoAddrsAvail
 	StatusCodeNoBinding
 	StatusCodeNotOnLink
 	StatusCodeUseMulticast
+	// RFC3633
+	StatusCodeNoPrefixAvail
+	// RFC5007
+	StatusCodeUnknownQueryType
+	StatusCodeMalformedQuery
+	StatusCodeNotConfigured
+	StatusCodeNotAllowed
+	// RFC5460
+	StatusCodeQueryTerminated
+	// RFC7653
+	StatusCodeDataMissing
+	StatusCodeCatchUpComplete
+	StatusCodeNotSupported
+	StatusCodeTLSConnectionRefused
 )
 
 func (s StatusCode) String() string {
@@ -542,6 +782,26 @@ func (s StatusCode) String() string {
 			return "NotOnLink"
 		case StatusCodeUseMulticast:
 			return "UseMulticast"
+		case StatusCodeNoPrefixAvail:
+			return "NoPrefixAvail"
+		case StatusCodeUnknownQueryType:
+			return "UnknownQueryType"
+		case StatusCodeMalformedQuery:
+			return "MalformedQuery"
+		case StatusCodeNotConfigured:
+			return "NotConfigured"
+		case StatusCodeNotAllowed:
+			return "NotAllowed"
+		case StatusCodeQueryTerminated:
+			return "QueryTerminated"
+		case StatusCodeDataMissing:
+			return "DataMissing"
+		case StatusCodeCatchUpComplete:
+			return "CatchUpComplete"
+		case StatusCodeNotSupported:
+			return "NotSupported"
+		case StatusCodeTLSConnectionRefused:
+			return "TLSConnectionRefused"
 		default:
 			return typeUnknown
 		}
@@ -587,6 +847,11 @@ func (o OptionStatusCode) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionStatusCode
+func (o OptionStatusCode) Clone() Option {
+	return &o
+}
+
 // OptionRapidCommit implements the Rapid Commit option as described at
 // https://tools.ietf.org/html/rfc3315#section-22.14
 // this option acts basically as a flag for the message carrying it
@@ -618,6 +883,11 @@ func (o OptionRapidCommit) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionRapidCommit
+func (o OptionRapidCommit) Clone() Option {
+	return &o
+}
+
 // options that contain class data can use optionContainer for easy
 // encoding/decoding
 type classDataContainer struct {
@@ -704,6 +974,13 @@ func (o OptionUserClass) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionUserClass
+func (o OptionUserClass) Clone() Option {
+	o.ClassData = append([]string(nil), o.ClassData...)
+
+	return &o
+}
+
 // OptionVendorClass implements the Vendor Class option described in
 // https://tools.ietf.org/html/rfc3315#section-22.16
 type OptionVendorClass struct {
@@ -712,7 +989,7 @@ type OptionVendorClass struct {
 }
 
 func (o OptionVendorClass) String() string {
-	return fmt.Sprintf("vendor-class %s", strings.Join(o.ClassData, ", "))
+	return fmt.Sprintf("vendor-class enterprise number %s %s", EnterpriseNumber(o.EnterpriseNumber), strings.Join(o.ClassData, ", "))
 }
 
 // Len returns the length in bytes of OptionVendorClass's body
@@ -741,6 +1018,13 @@ func (o OptionVendorClass) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionVendorClass
+func (o OptionVendorClass) Clone() Option {
+	o.ClassData = append([]string(nil), o.ClassData...)
+
+	return &o
+}
+
 // OptionDNSServer implements the DNS Server option described in
 // https://tools.ietf.org/html/rfc3646#section-3
 type OptionDNSServer struct {
@@ -781,149 +1065,1067 @@ func (o OptionDNSServer) Marshal() ([]byte, error) {
 	return b, nil
 }
 
-// OptionBootFileURL implements the Boot File URL option described in
-// https://tools.ietf.org/html/rfc5970#section-3.1
-type OptionBootFileURL struct {
-	URL string
+// Clone returns a deep copy of this OptionDNSServer
+func (o OptionDNSServer) Clone() Option {
+	o.Servers = cloneIPs(o.Servers)
+
+	return &o
 }
 
-func (o OptionBootFileURL) String() string {
-	return fmt.Sprintf("boot-file-url %s", o.URL)
+// OptionDNSSearchList implements the Domain Search List option described in
+// https://tools.ietf.org/html/rfc3646#section-4
+type OptionDNSSearchList struct {
+	DomainNames []string
 }
 
-// Len returns the length in bytes of OptionBootFileURL's body
-func (o OptionBootFileURL) Len() uint16 {
-	return uint16(len(o.URL))
+func (o OptionDNSSearchList) String() string {
+	return fmt.Sprintf("DNS-search-list %s", strings.Join(o.DomainNames, ","))
 }
 
-// Type returns OptionTypeBootFileURL
-func (o OptionBootFileURL) Type() OptionType {
-	return OptionTypeBootFileURL
+// Len returns the length in bytes of OptionDNSSearchList's body
+func (o OptionDNSSearchList) Len() uint16 {
+	b, _ := o.encodeDomainNames()
+	return uint16(len(b))
 }
 
-// Marshal returns byte slice representing this OptionBootFileURL
-func (o OptionBootFileURL) Marshal() ([]byte, error) {
-	// prepare byte slice of appropriate length
+// Type returns OptionTypeDNSSearchList
+func (o OptionDNSSearchList) Type() OptionType {
+	return OptionTypeDNSSearchList
+}
+
+// Marshal returns byte slice representing this OptionDNSSearchList
+func (o OptionDNSSearchList) Marshal() ([]byte, error) {
+	names, err := o.encodeDomainNames()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) > math.MaxUint16 {
+		return nil, errOptionTooLong
+	}
+
+	// prepare byte slice for type and length
+	// domain names will be appended later
 	b := make([]byte, 4)
 	// set type
-	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeBootFileURL))
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeDNSSearchList))
 	// set length
-	binary.BigEndian.PutUint16(b[2:4], o.Len())
-	// append string
-	b = append(b, []byte(o.URL)...)
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(names)))
+	// append domain names
+	b = append(b, names...)
 
 	return b, nil
 }
 
-// OptionBootFileParameters implements the Boot File URL option described in
-// https://tools.ietf.org/html/rfc5970#section-3.2
-type OptionBootFileParameters struct {
-	Parameters []string
+// Clone returns a deep copy of this OptionDNSSearchList
+func (o OptionDNSSearchList) Clone() Option {
+	o.DomainNames = append([]string(nil), o.DomainNames...)
+
+	return &o
 }
 
-func (o OptionBootFileParameters) String() string {
-	output := "boot-file-params"
-	for _, p := range o.Parameters {
-		output += fmt.Sprintf(" %s", p)
+// every domain name in the list is fully qualified, so the trailing dot is
+// left out of DomainNames and implied when encoding
+func (o OptionDNSSearchList) encodeDomainNames() ([]byte, error) {
+	b := make([]byte, 0)
+	for _, name := range o.DomainNames {
+		var err error
+		b, err = appendDomainName(b, strings.TrimSuffix(name, ".")+".")
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return output
+	return b, nil
 }
 
-// Len returns the length in bytes of OptionBootFileParameters's body
-func (o OptionBootFileParameters) Len() uint16 {
-	pl := 0
-	for _, p := range o.Parameters {
-		pl += len(p) + 2 // 2 additional bytes per parameter for parameter length
+func (o *OptionDNSSearchList) decodeDomainNames(data []byte) error {
+	names := make([]string, 0)
+	for len(data) > 0 {
+		name, n, err := decodeDomainName(data)
+		if err != nil {
+			return err
+		}
+		// a partial name is only allowed in the Client FQDN option
+		if !strings.HasSuffix(name, ".") {
+			return errInvalidDomainName
+		}
+
+		names = append(names, strings.TrimSuffix(name, "."))
+		data = data[n:]
 	}
 
-	return uint16(pl)
+	o.DomainNames = names
+	return nil
 }
 
-// Type returns OptionTypeBootFileParameters
-func (o OptionBootFileParameters) Type() OptionType {
-	return OptionTypeBootFileParameters
-}
+// helper function to append given domain name to b in the DNS wire format
+// described at https://tools.ietf.org/html/rfc1035#section-3.1. A name with a
+// trailing dot ends with the root label, a name without one is encoded as the
+// partial name described at https://tools.ietf.org/html/rfc4704#section-4.1
+func appendDomainName(b []byte, name string) ([]byte, error) {
+	if name == "" {
+		return b, nil
+	}
 
-// Marshal returns byte slice representing this OptionBootFileParameters
-func (o OptionBootFileParameters) Marshal() ([]byte, error) {
-	// prepare byte slice of appropriate length
-	b := make([]byte, 4)
-	// set type
-	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeBootFileParameters))
-	// set length
-	binary.BigEndian.PutUint16(b[2:4], o.Len())
-	for _, p := range o.Parameters {
-		pl := make([]byte, 2)
-		binary.BigEndian.PutUint16(pl[0:2], uint16(len(p)))
-		// append parameter length
-		b = append(b, pl...)
-		// append parameter
-		b = append(b, []byte(p)...)
+	start := len(b)
+	// the root domain only consists of the root label
+	if name != "." {
+		for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+			if len(label) == 0 || len(label) > maxDomainLabelLen {
+				return nil, errInvalidDomainName
+			}
+			b = append(b, uint8(len(label)))
+			b = append(b, label...)
+		}
+	}
+	if strings.HasSuffix(name, ".") {
+		b = append(b, 0)
+	}
+	if len(b)-start > maxDomainNameLen {
+		return nil, errInvalidDomainName
 	}
 
 	return b, nil
 }
 
-// helper function to decode the parameters
-func (o *OptionBootFileParameters) decodeParameters(data []byte) error {
-	params := []string{}
-	for {
-		if len(data) < 2 {
-			// param data too short
-			break
+// helper function to decode the domain name in DNS wire format at the start of
+// data and return it along with the amount of bytes it takes. A partial name,
+// running up to the end of data without root label, is returned without
+// trailing dot
+func decodeDomainName(data []byte) (string, int, error) {
+	labels := make([]string, 0)
+	n := 0
+	for n < len(data) {
+		l := int(data[n])
+		if l == 0 {
+			n++
+			if n > maxDomainNameLen {
+				return "", 0, errInvalidDomainName
+			}
+			return strings.Join(labels, ".") + ".", n, nil
 		}
-
-		pl := binary.BigEndian.Uint16(data[0:2])
-		if uint16(len(data)) < 2+pl {
-			// param body too short
-			break
+		// DHCPv6 doesn't allow compression, so pointers are invalid as well
+		if l > maxDomainLabelLen || n+1+l > len(data) {
+			return "", 0, errInvalidDomainName
 		}
 
-		params = append(params, string(data[2:2+pl]))
-		data = data[2+pl:]
+		labels = append(labels, string(data[n+1:n+1+l]))
+		n += 1 + l
+	}
+	if n > maxDomainNameLen {
+		return "", 0, errInvalidDomainName
 	}
 
-	o.Parameters = params
-	return nil
+	return strings.Join(labels, "."), n, nil
 }
 
-type ArchitectureType uint16
+// OptionIAPD implements the Identity Association for Prefix Delegation option
+// as described at https://tools.ietf.org/html/rfc3633#section-9
+type OptionIAPD struct {
+	optionContainer
+	IAID uint32
+	T1   time.Duration // delay before Renew
+	T2   time.Duration // delay before Rebind
+}
 
-// Architecture types as described at https://tools.ietf.org/html/rfc4578#section-2.1
-const (
-	ArchitectureTypeIntelx86PC ArchitectureType = iota
-	ArchitectureTypeNECPC98
-	ArchitectureTypeEFIItanium
-	ArchitectureTypeDECAlpha
-	ArchitectureTypeArcx86
-	ArchitectureTypeIntelLeanClient
-	ArchitectureTypeEFIIA32
-	ArchitectureTypeEFIBC
-	ArchitectureTypeEFIXscale
-	ArchitectureTypeEFIx8664
-)
+func (o OptionIAPD) String() string {
+	output := fmt.Sprintf("IA_PD IAID:%d T1:%s T2:%s", o.IAID, o.T1, o.T2)
+	if len(o.options) > 0 {
+		output += fmt.Sprintf(" %s", o.options)
+	}
+	return output
+}
 
-func (s ArchitectureType) String() string {
-	name := func() string {
-		switch s {
-		case ArchitectureTypeIntelx86PC:
-			return "Intel x86PC"
-		case ArchitectureTypeNECPC98:
-			return "NEC/PC98"
-		case ArchitectureTypeEFIItanium:
-			return "EFI Itanium"
-		case ArchitectureTypeDECAlpha:
-			return "DEC Alpha"
-		case ArchitectureTypeArcx86:
-			return "Arc x86"
-		case ArchitectureTypeIntelLeanClient:
-			return "Intel Lean Client"
-		case ArchitectureTypeEFIIA32:
-			return "EFI IA32"
-		case ArchitectureTypeEFIBC:
-			return "EFI BC"
-		case ArchitectureTypeEFIXscale:
+// Len returns the length in bytes of OptionIAPD's body
+func (o OptionIAPD) Len() uint16 {
+	// iaid (4 bytes)
+	// t1 (4 bytes)
+	// t2 (4 bytes)
+	// any additional options' length
+	return 12 + o.options.Len()
+}
+
+// Type returns OptionTypeIAPD
+func (o OptionIAPD) Type() OptionType {
+	return OptionTypeIAPD
+}
+
+// Marshal returns byte slice representing this OptionIAPD
+func (o OptionIAPD) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	// any options will be appended later
+	b := make([]byte, 16)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeIAPD))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// set IAID
+	binary.BigEndian.PutUint32(b[4:8], o.IAID)
+	// set T1
+	binary.BigEndian.PutUint32(b[8:12], uint32(o.T1.Seconds()))
+	// set T2
+	binary.BigEndian.PutUint32(b[12:16], uint32(o.T2.Seconds()))
+	// append any options
+	if len(o.options) > 0 {
+		optMarshal, err := o.options.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, optMarshal...)
+	}
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionIAPD
+func (o OptionIAPD) Clone() Option {
+	o.options = o.options.Clone()
+
+	return &o
+}
+
+// OptionIAPrefix implements the IA Prefix option as described at
+// https://tools.ietf.org/html/rfc3633#section-10
+type OptionIAPrefix struct {
+	optionContainer
+	PreferredLifetime time.Duration
+	ValidLifetime     time.Duration
+	PrefixLength      uint8
+	Prefix            net.IP
+}
+
+func (o OptionIAPrefix) String() string {
+	output := fmt.Sprintf("IA_PREFIX %s/%d pltime:%s vltime:%s", o.Prefix, o.PrefixLength, o.PreferredLifetime, o.ValidLifetime)
+	if len(o.options) > 0 {
+		output += fmt.Sprintf(" %s", o.options)
+	}
+
+	return output
+}
+
+// Len returns the length in bytes of OptionIAPrefix's body
+func (o OptionIAPrefix) Len() uint16 {
+	// preferred lifetime (4 bytes)
+	// valid lifetime (4 bytes)
+	// prefix length (1 byte)
+	// prefix (16 bytes)
+	// any additional options' length
+	return 25 + o.options.Len()
+}
+
+// Type returns OptionTypeIAPrefix
+func (o OptionIAPrefix) Type() OptionType {
+	return OptionTypeIAPrefix
+}
+
+// NetPrefix returns the prefix of this OptionIAPrefix as netip.Prefix or
+// error if either the prefix or its length is invalid
+func (o OptionIAPrefix) NetPrefix() (netip.Prefix, error) {
+	addr, err := ipv6Addr(o.Prefix)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if o.PrefixLength > 128 {
+		return netip.Prefix{}, errInvalidPrefixLength
+	}
+
+	return netip.PrefixFrom(addr, int(o.PrefixLength)), nil
+}
+
+// ExcludedPrefix returns the prefix excluded from this OptionIAPrefix by a
+// nested OptionPDExclude. If there is no such option, an invalid
+// netip.Prefix is returned
+func (o OptionIAPrefix) ExcludedPrefix() (netip.Prefix, error) {
+	opt := o.HasOption(OptionTypePDExclude)
+	if opt == nil {
+		return netip.Prefix{}, nil
+	}
+
+	delegated, err := o.NetPrefix()
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return opt.(*OptionPDExclude).ExcludedPrefix(delegated)
+}
+
+// SetExcludedPrefix sets an OptionPDExclude excluding given prefix from this
+// OptionIAPrefix, replacing any existing one
+func (o *OptionIAPrefix) SetExcludedPrefix(excluded netip.Prefix) error {
+	delegated, err := o.NetPrefix()
+	if err != nil {
+		return err
+	}
+
+	opt, err := NewOptionPDExclude(delegated, excluded)
+	if err != nil {
+		return err
+	}
+
+	o.SetOption(opt)
+	return nil
+}
+
+// Marshal returns byte slice representing this OptionIAPrefix
+func (o OptionIAPrefix) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	// prefix and optional options are appended later
+	b := make([]byte, 13)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeIAPrefix))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// set preferred time
+	binary.BigEndian.PutUint32(b[4:8], uint32(o.PreferredLifetime.Seconds()))
+	// set valid time
+	binary.BigEndian.PutUint32(b[8:12], uint32(o.ValidLifetime.Seconds()))
+	// set prefix length
+	if o.PrefixLength > 128 {
+		return nil, errInvalidPrefixLength
+	}
+	b[12] = o.PrefixLength
+	// set prefix
+	if _, err := ipv6Addr(o.Prefix); err != nil {
+		return nil, err
+	}
+	b = append(b, o.Prefix...)
+	// check any excluded prefix is within this prefix
+	if _, err := o.ExcludedPrefix(); err != nil {
+		return nil, err
+	}
+	// append any options
+	if len(o.options) > 0 {
+		optMarshal, err := o.options.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, optMarshal...)
+	}
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionIAPrefix
+func (o OptionIAPrefix) Clone() Option {
+	o.options = o.options.Clone()
+	o.Prefix = cloneIP(o.Prefix)
+
+	return &o
+}
+
+// OptionPDExclude implements the Prefix Exclude option as described at
+// https://tools.ietf.org/html/rfc6603#section-4.2
+// the excluded prefix is encoded relative to the delegated prefix of the
+// enclosing OptionIAPrefix, so the SubnetID only holds the bits of the
+// excluded prefix following the delegated prefix
+type OptionPDExclude struct {
+	PrefixLength uint8
+	SubnetID     []byte
+}
+
+// NewOptionPDExclude returns an OptionPDExclude excluding prefix excluded from
+// prefix delegated or error if excluded is not within delegated
+func NewOptionPDExclude(delegated, excluded netip.Prefix) (*OptionPDExclude, error) {
+	if !delegated.IsValid() || !excluded.IsValid() || !delegated.Addr().Is6() || !excluded.Addr().Is6() {
+		return nil, errInvalidIPv6Address
+	}
+	// the excluded prefix has to be longer than and within the delegated prefix
+	if excluded.Bits() <= delegated.Bits() || !delegated.Contains(excluded.Addr()) {
+		return nil, errInvalidPDExclude
+	}
+
+	// copy the bits following the delegated prefix length up to the excluded
+	// prefix length to the start of the subnet ID
+	addr := excluded.Addr().As16()
+	n := excluded.Bits() - delegated.Bits()
+	subnetID := make([]byte, (n-1)/8+1)
+	for i := 0; i < n; i++ {
+		pos := delegated.Bits() + i
+		if addr[pos/8]&(0x80>>(pos%8)) > 0 {
+			subnetID[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+
+	return &OptionPDExclude{
+		PrefixLength: uint8(excluded.Bits()),
+		SubnetID:     subnetID,
+	}, nil
+}
+
+// ExcludedPrefix returns the excluded prefix relative to given delegated
+// prefix or error if the option does not fit the delegated prefix
+func (o OptionPDExclude) ExcludedPrefix(delegated netip.Prefix) (netip.Prefix, error) {
+	if !delegated.IsValid() || !delegated.Addr().Is6() {
+		return netip.Prefix{}, errInvalidIPv6Address
+	}
+
+	n := int(o.PrefixLength) - delegated.Bits()
+	if o.PrefixLength > 128 || n <= 0 || len(o.SubnetID) != (n-1)/8+1 {
+		return netip.Prefix{}, errInvalidPDExclude
+	}
+
+	// copy the bits from the subnet ID right after the delegated prefix
+	addr := delegated.Masked().Addr().As16()
+	for i := 0; i < n; i++ {
+		if o.SubnetID[i/8]&(0x80>>(i%8)) > 0 {
+			pos := delegated.Bits() + i
+			addr[pos/8] |= 0x80 >> (pos % 8)
+		}
+	}
+
+	return netip.PrefixFrom(netip.AddrFrom16(addr), int(o.PrefixLength)), nil
+}
+
+func (o OptionPDExclude) String() string {
+	return fmt.Sprintf("pd-exclude /%d subnet-id %x", o.PrefixLength, o.SubnetID)
+}
+
+// Len returns the length in bytes of OptionPDExclude's body
+func (o OptionPDExclude) Len() uint16 {
+	return uint16(1 + len(o.SubnetID))
+}
+
+// Type returns OptionTypePDExclude
+func (o OptionPDExclude) Type() OptionType {
+	return OptionTypePDExclude
+}
+
+// Marshal returns byte slice representing this OptionPDExclude
+func (o OptionPDExclude) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	// subnet ID is appended later
+	b := make([]byte, 5)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypePDExclude))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// set prefix length
+	b[4] = o.PrefixLength
+	// append subnet ID
+	if len(o.SubnetID) == 0 {
+		return nil, errInvalidPDExclude
+	}
+	b = append(b, o.SubnetID...)
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionPDExclude
+func (o OptionPDExclude) Clone() Option {
+	o.SubnetID = append([]byte(nil), o.SubnetID...)
+
+	return &o
+}
+
+// OptionRemoteID implements the Relay Agent Remote-ID option as described at
+// https://tools.ietf.org/html/rfc4649#section-3
+type OptionRemoteID struct {
+	EnterpriseNumber uint32
+	RemoteID         []byte
+}
+
+func (o OptionRemoteID) String() string {
+	return fmt.Sprintf("remote-ID enterprise number %s (id: %x)", EnterpriseNumber(o.EnterpriseNumber), o.RemoteID)
+}
+
+// Len returns the length in bytes of OptionRemoteID's body
+func (o OptionRemoteID) Len() uint16 {
+	return uint16(4 + len(o.RemoteID))
+}
+
+// Type returns OptionTypeRemoteID
+func (o OptionRemoteID) Type() OptionType {
+	return OptionTypeRemoteID
+}
+
+// Marshal returns byte slice representing this OptionRemoteID
+func (o OptionRemoteID) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	// remote-id will be appended later
+	b := make([]byte, 8)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeRemoteID))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// set enterprise number
+	binary.BigEndian.PutUint32(b[4:8], o.EnterpriseNumber)
+	// append remote-id
+	b = append(b, o.RemoteID...)
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionRemoteID
+func (o OptionRemoteID) Clone() Option {
+	o.RemoteID = append([]byte(nil), o.RemoteID...)
+
+	return &o
+}
+
+// Client FQDN flags as described at
+// https://tools.ietf.org/html/rfc4704#section-4.1
+const (
+	ClientFQDNFlagS uint8 = 1 << iota
+	ClientFQDNFlagO
+	ClientFQDNFlagN
+)
+
+// OptionClientFQDN implements the Client FQDN option as described at
+// https://tools.ietf.org/html/rfc4704#section-4. A DomainName with a trailing
+// dot is fully qualified, one without is a partial name
+type OptionClientFQDN struct {
+	Flags      uint8
+	DomainName string
+}
+
+func (o OptionClientFQDN) String() string {
+	return fmt.Sprintf("client-FQDN %s (flags: %#02x)", o.DomainName, o.Flags)
+}
+
+// Len returns the length in bytes of OptionClientFQDN's body
+func (o OptionClientFQDN) Len() uint16 {
+	name, _ := appendDomainName(nil, o.DomainName)
+	return uint16(1 + len(name))
+}
+
+// Type returns OptionTypeClientFQDN
+func (o OptionClientFQDN) Type() OptionType {
+	return OptionTypeClientFQDN
+}
+
+// Marshal returns byte slice representing this OptionClientFQDN
+func (o OptionClientFQDN) Marshal() ([]byte, error) {
+	// prepare byte slice for type, length and flags
+	// domain name will be appended later
+	b := make([]byte, 5)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeClientFQDN))
+	// set flags
+	b[4] = o.Flags
+	// append domain name
+	b, err := appendDomainName(b, o.DomainName)
+	if err != nil {
+		return nil, err
+	}
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(b)-4))
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionClientFQDN
+func (o OptionClientFQDN) Clone() Option {
+	return &o
+}
+
+func (o *OptionClientFQDN) decodeDomainName(data []byte) error {
+	if len(data) < 1 {
+		return errOptionTooShort
+	}
+
+	name, n, err := decodeDomainName(data[1:])
+	if err != nil {
+		return err
+	}
+	// a name is either fully qualified or partial, nothing may follow
+	if n != len(data)-1 {
+		return errInvalidDomainName
+	}
+
+	o.Flags = data[0]
+	o.DomainName = name
+	return nil
+}
+
+type QueryType uint8
+
+// Query types as described at https://tools.ietf.org/html/rfc5007#section-4.1.2.1
+// and https://tools.ietf.org/html/rfc5460#section-5.2
+const (
+	_ QueryType = iota
+	// RFC5007
+	QueryTypeByAddress
+	QueryTypeByClientID
+	// RFC5460
+	QueryTypeByRelayID
+	QueryTypeByLinkAddress
+	QueryTypeByRemoteID
+)
+
+func (q QueryType) String() string {
+	name := func() string {
+		switch q {
+		case QueryTypeByAddress:
+			return "By Address"
+		case QueryTypeByClientID:
+			return "By Client ID"
+		case QueryTypeByRelayID:
+			return "By Relay ID"
+		case QueryTypeByLinkAddress:
+			return "By Link Address"
+		case QueryTypeByRemoteID:
+			return "By Remote ID"
+		default:
+			return typeUnknown
+		}
+	}
+
+	return fmt.Sprintf("%s (%d)", name(), q)
+}
+
+// OptionLQQuery implements the Leasequery Query option as described at
+// https://tools.ietf.org/html/rfc5007#section-4.1.2.1
+type OptionLQQuery struct {
+	optionContainer
+	QueryType   QueryType
+	LinkAddress net.IP
+}
+
+func (o OptionLQQuery) String() string {
+	output := fmt.Sprintf("lq-query %s link-address %s", o.QueryType, o.LinkAddress)
+	if len(o.options) > 0 {
+		output += fmt.Sprintf(" %s", o.options)
+	}
+
+	return output
+}
+
+// Len returns the length in bytes of OptionLQQuery's body
+func (o OptionLQQuery) Len() uint16 {
+	// query type (1 byte)
+	// link address (16 bytes)
+	// any additional options' length
+	return 17 + o.options.Len()
+}
+
+// Type returns OptionTypeLQQuery
+func (o OptionLQQuery) Type() OptionType {
+	return OptionTypeLQQuery
+}
+
+// Marshal returns byte slice representing this OptionLQQuery
+func (o OptionLQQuery) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	// link address and optional options are appended later
+	b := make([]byte, 5)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeLQQuery))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// set query type
+	b[4] = uint8(o.QueryType)
+	// set link address
+	if len(o.LinkAddress) != net.IPv6len {
+		return nil, errInvalidIPv6Address
+	}
+	b = append(b, o.LinkAddress...)
+	// append any options
+	if len(o.options) > 0 {
+		optMarshal, err := o.options.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, optMarshal...)
+	}
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionLQQuery
+func (o OptionLQQuery) Clone() Option {
+	o.options = o.options.Clone()
+	o.LinkAddress = cloneIP(o.LinkAddress)
+
+	return &o
+}
+
+// OptionClientData implements the Client Data option as described at
+// https://tools.ietf.org/html/rfc5007#section-4.1.2.2
+// it merely acts as a container for the options describing a client's binding
+type OptionClientData struct {
+	optionContainer
+}
+
+func (o OptionClientData) String() string {
+	return fmt.Sprintf("client-data %s", o.options)
+}
+
+// Len returns the length in bytes of OptionClientData's body
+func (o OptionClientData) Len() uint16 {
+	return o.options.Len()
+}
+
+// Type returns OptionTypeClientData
+func (o OptionClientData) Type() OptionType {
+	return OptionTypeClientData
+}
+
+// Marshal returns byte slice representing this OptionClientData
+func (o OptionClientData) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	b := make([]byte, 4)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeClientData))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// append any options
+	if len(o.options) > 0 {
+		optMarshal, err := o.options.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, optMarshal...)
+	}
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionClientData
+func (o OptionClientData) Clone() Option {
+	o.options = o.options.Clone()
+
+	return &o
+}
+
+// OptionCLTTime implements the Client Last Transaction Time option as
+// described at https://tools.ietf.org/html/rfc5007#section-4.1.2.3
+type OptionCLTTime struct {
+	Time time.Duration // since last transaction with the client
+}
+
+func (o OptionCLTTime) String() string {
+	return fmt.Sprintf("clt-time %s", o.Time)
+}
+
+// Len returns the length in bytes of OptionCLTTime's body
+func (o OptionCLTTime) Len() uint16 {
+	return 4
+}
+
+// Type returns OptionTypeCLTTime
+func (o OptionCLTTime) Type() OptionType {
+	return OptionTypeCLTTime
+}
+
+// Marshal returns byte slice representing this OptionCLTTime
+func (o OptionCLTTime) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	b := make([]byte, 4+o.Len())
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeCLTTime))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// set time
+	binary.BigEndian.PutUint32(b[4:8], uint32(o.Time.Seconds()))
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionCLTTime
+func (o OptionCLTTime) Clone() Option {
+	return &o
+}
+
+// OptionLQRelayData implements the Leasequery Relay Data option as described
+// at https://tools.ietf.org/html/rfc5007#section-4.1.2.4
+// the relay message is kept as-is, since relay messages are not decoded by
+// this package
+type OptionLQRelayData struct {
+	PeerAddress  net.IP
+	RelayMessage []byte
+}
+
+func (o OptionLQRelayData) String() string {
+	return fmt.Sprintf("lq-relay-data peer-address %s relay-message %x", o.PeerAddress, o.RelayMessage)
+}
+
+// Len returns the length in bytes of OptionLQRelayData's body
+func (o OptionLQRelayData) Len() uint16 {
+	return uint16(16 + len(o.RelayMessage))
+}
+
+// Type returns OptionTypeLQRelayData
+func (o OptionLQRelayData) Type() OptionType {
+	return OptionTypeLQRelayData
+}
+
+// Marshal returns byte slice representing this OptionLQRelayData
+func (o OptionLQRelayData) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	// peer address and relay message are appended later
+	b := make([]byte, 4)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeLQRelayData))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// set peer address
+	if len(o.PeerAddress) != net.IPv6len {
+		return nil, errInvalidIPv6Address
+	}
+	b = append(b, o.PeerAddress...)
+	// append relay message
+	b = append(b, o.RelayMessage...)
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionLQRelayData
+func (o OptionLQRelayData) Clone() Option {
+	o.PeerAddress = cloneIP(o.PeerAddress)
+	o.RelayMessage = append([]byte(nil), o.RelayMessage...)
+
+	return &o
+}
+
+// OptionLQClientLink implements the Leasequery Client Link option as
+// described at https://tools.ietf.org/html/rfc5007#section-4.1.2.5
+type OptionLQClientLink struct {
+	LinkAddresses []net.IP
+}
+
+func (o OptionLQClientLink) String() string {
+	addrs := make([]string, len(o.LinkAddresses))
+	for i, addr := range o.LinkAddresses {
+		addrs[i] = addr.String()
+	}
+	return fmt.Sprintf("lq-client-link %s", strings.Join(addrs, ","))
+}
+
+// Len returns the length in bytes of OptionLQClientLink's body
+func (o OptionLQClientLink) Len() uint16 {
+	return uint16(len(o.LinkAddresses) * 16)
+}
+
+// Type returns OptionTypeLQClientLink
+func (o OptionLQClientLink) Type() OptionType {
+	return OptionTypeLQClientLink
+}
+
+// Marshal returns byte slice representing this OptionLQClientLink
+func (o OptionLQClientLink) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	b := make([]byte, 4+o.Len())
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeLQClientLink))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// append link addresses
+	for i, addr := range o.LinkAddresses {
+		if len(addr) != net.IPv6len {
+			return nil, errInvalidIPv6Address
+		}
+		copy(b[(i*16)+4:(i*16)+20], addr)
+	}
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionLQClientLink
+func (o OptionLQClientLink) Clone() Option {
+	o.LinkAddresses = cloneIPs(o.LinkAddresses)
+
+	return &o
+}
+
+// OptionRelayID implements the Relay Identifier option as described at
+// https://tools.ietf.org/html/rfc5460#section-5.4.1
+type OptionRelayID struct {
+	DUID DUID
+}
+
+func (o OptionRelayID) String() string {
+	return fmt.Sprintf("relay-ID %s", o.DUID)
+}
+
+// Len returns the length in bytes of OptionRelayID's body
+func (o OptionRelayID) Len() uint16 {
+	return o.DUID.Len()
+}
+
+// Type returns OptionTypeRelayID
+func (o OptionRelayID) Type() OptionType {
+	return OptionTypeRelayID
+}
+
+// Marshal returns byte slice representing this OptionRelayID
+func (o OptionRelayID) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	// DUID will be appended later
+	b := make([]byte, 4) // type (2 bytes), length (2 bytes)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeRelayID))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// append DUID bytes
+	duid, err := o.DUID.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal DUID: %s", err)
+	}
+	b = append(b, duid...)
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionRelayID
+func (o OptionRelayID) Clone() Option {
+	o.DUID = CloneDUID(o.DUID)
+
+	return &o
+}
+
+// OptionBootFileURL implements the Boot File URL option described in
+// https://tools.ietf.org/html/rfc5970#section-3.1
+type OptionBootFileURL struct {
+	URL string
+}
+
+func (o OptionBootFileURL) String() string {
+	return fmt.Sprintf("boot-file-url %s", o.URL)
+}
+
+// Len returns the length in bytes of OptionBootFileURL's body
+func (o OptionBootFileURL) Len() uint16 {
+	return uint16(len(o.URL))
+}
+
+// Type returns OptionTypeBootFileURL
+func (o OptionBootFileURL) Type() OptionType {
+	return OptionTypeBootFileURL
+}
+
+// Marshal returns byte slice representing this OptionBootFileURL
+func (o OptionBootFileURL) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	b := make([]byte, 4)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeBootFileURL))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// append string
+	b = append(b, []byte(o.URL)...)
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionBootFileURL
+func (o OptionBootFileURL) Clone() Option {
+	return &o
+}
+
+// OptionBootFileParameters implements the Boot File URL option described in
+// https://tools.ietf.org/html/rfc5970#section-3.2
+type OptionBootFileParameters struct {
+	Parameters []string
+}
+
+func (o OptionBootFileParameters) String() string {
+	output := "boot-file-params"
+	for _, p := range o.Parameters {
+		output += fmt.Sprintf(" %s", p)
+	}
+
+	return output
+}
+
+// Len returns the length in bytes of OptionBootFileParameters's body
+func (o OptionBootFileParameters) Len() uint16 {
+	pl := 0
+	for _, p := range o.Parameters {
+		pl += len(p) + 2 // 2 additional bytes per parameter for parameter length
+	}
+
+	return uint16(pl)
+}
+
+// Type returns OptionTypeBootFileParameters
+func (o OptionBootFileParameters) Type() OptionType {
+	return OptionTypeBootFileParameters
+}
+
+// Marshal returns byte slice representing this OptionBootFileParameters
+func (o OptionBootFileParameters) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	b := make([]byte, 4)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeBootFileParameters))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	for _, p := range o.Parameters {
+		pl := make([]byte, 2)
+		binary.BigEndian.PutUint16(pl[0:2], uint16(len(p)))
+		// append parameter length
+		b = append(b, pl...)
+		// append parameter
+		b = append(b, []byte(p)...)
+	}
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionBootFileParameters
+func (o OptionBootFileParameters) Clone() Option {
+	o.Parameters = append([]string(nil), o.Parameters...)
+
+	return &o
+}
+
+// helper function to decode the parameters
+func (o *OptionBootFileParameters) decodeParameters(data []byte) error {
+	params := []string{}
+	for {
+		if len(data) < 2 {
+			// param data too short
+			break
+		}
+
+		pl := binary.BigEndian.Uint16(data[0:2])
+		if uint16(len(data)) < 2+pl {
+			// param body too short
+			break
+		}
+
+		params = append(params, string(data[2:2+pl]))
+		data = data[2+pl:]
+	}
+
+	o.Parameters = params
+	return nil
+}
+
+type ArchitectureType uint16
+
+// Architecture types as described at https://tools.ietf.org/html/rfc4578#section-2.1
+const (
+	ArchitectureTypeIntelx86PC ArchitectureType = iota
+	ArchitectureTypeNECPC98
+	ArchitectureTypeEFIItanium
+	ArchitectureTypeDECAlpha
+	ArchitectureTypeArcx86
+	ArchitectureTypeIntelLeanClient
+	ArchitectureTypeEFIIA32
+	ArchitectureTypeEFIBC
+	ArchitectureTypeEFIXscale
+	ArchitectureTypeEFIx8664
+)
+
+func (s ArchitectureType) String() string {
+	name := func() string {
+		switch s {
+		case ArchitectureTypeIntelx86PC:
+			return "Intel x86PC"
+		case ArchitectureTypeNECPC98:
+			return "NEC/PC98"
+		case ArchitectureTypeEFIItanium:
+			return "EFI Itanium"
+		case ArchitectureTypeDECAlpha:
+			return "DEC Alpha"
+		case ArchitectureTypeArcx86:
+			return "Arc x86"
+		case ArchitectureTypeIntelLeanClient:
+			return "Intel Lean Client"
+		case ArchitectureTypeEFIIA32:
+			return "EFI IA32"
+		case ArchitectureTypeEFIBC:
+			return "EFI BC"
+		case ArchitectureTypeEFIXscale:
 			return "EFI Xscale"
 		case ArchitectureTypeEFIx8664:
 			return "EFI x86-64"
@@ -974,6 +2176,13 @@ func (o OptionClientSystemArchitectureType) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionClientSystemArchitectureType
+func (o OptionClientSystemArchitectureType) Clone() Option {
+	o.Types = append([]ArchitectureType(nil), o.Types...)
+
+	return &o
+}
+
 type InterfaceType uint8
 
 // Interface types as described at https://tools.ietf.org/html/rfc4578#section-2.2
@@ -1037,6 +2246,125 @@ func (o OptionClientNetworkInterfaceIdentifier) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionClientNetworkInterfaceIdentifier
+func (o OptionClientNetworkInterfaceIdentifier) Clone() Option {
+	return &o
+}
+
+// OptionDHCPv4Message implements the DHCPv4 Message option as described at
+// https://tools.ietf.org/html/rfc7341#section-7.1
+// the DHCPv4 message is kept as-is, use DHCPv4 to decode it
+type OptionDHCPv4Message struct {
+	Message []byte
+}
+
+// NewOptionDHCPv4Message returns an OptionDHCPv4Message carrying given
+// DHCPv4Message or error if it could not be marshalled
+func NewOptionDHCPv4Message(m *DHCPv4Message) (*OptionDHCPv4Message, error) {
+	b, err := m.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OptionDHCPv4Message{Message: b}, nil
+}
+
+// DHCPv4 decodes the DHCPv4 message carried by this OptionDHCPv4Message
+func (o OptionDHCPv4Message) DHCPv4() (*DHCPv4Message, error) {
+	return DecodeDHCPv4Message(o.Message)
+}
+
+func (o OptionDHCPv4Message) String() string {
+	if m, err := o.DHCPv4(); err == nil {
+		return fmt.Sprintf("dhcpv4-message %s", m)
+	}
+
+	return fmt.Sprintf("dhcpv4-message %x", o.Message)
+}
+
+// Len returns the length in bytes of OptionDHCPv4Message's body
+func (o OptionDHCPv4Message) Len() uint16 {
+	return uint16(len(o.Message))
+}
+
+// Type returns OptionTypeDHCPv4Message
+func (o OptionDHCPv4Message) Type() OptionType {
+	return OptionTypeDHCPv4Message
+}
+
+// Marshal returns byte slice representing this OptionDHCPv4Message
+func (o OptionDHCPv4Message) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	b := make([]byte, 4)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeDHCPv4Message))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// append DHCPv4 message
+	b = append(b, o.Message...)
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionDHCPv4Message
+func (o OptionDHCPv4Message) Clone() Option {
+	o.Message = append([]byte(nil), o.Message...)
+
+	return &o
+}
+
+// OptionDHCP4oDHCP6Server implements the DHCP4o6 Server Address option as
+// described at https://tools.ietf.org/html/rfc7341#section-7.2
+// an empty list of servers is valid and means the DHCPv4 messages should be
+// sent to the All_DHCP_Relay_Agents_and_Servers multicast address
+type OptionDHCP4oDHCP6Server struct {
+	Servers []net.IP
+}
+
+func (o OptionDHCP4oDHCP6Server) String() string {
+	servers := make([]string, len(o.Servers))
+	for i, server := range o.Servers {
+		servers[i] = server.String()
+	}
+	return fmt.Sprintf("dhcp4o6-server %s", strings.Join(servers, ","))
+}
+
+// Len returns the length in bytes of OptionDHCP4oDHCP6Server's body
+func (o OptionDHCP4oDHCP6Server) Len() uint16 {
+	return uint16(len(o.Servers) * 16)
+}
+
+// Type returns OptionTypeDHCP4oDHCP6Server
+func (o OptionDHCP4oDHCP6Server) Type() OptionType {
+	return OptionTypeDHCP4oDHCP6Server
+}
+
+// Marshal returns byte slice representing this OptionDHCP4oDHCP6Server
+func (o OptionDHCP4oDHCP6Server) Marshal() ([]byte, error) {
+	// prepare byte slice of appropriate length
+	b := make([]byte, 4+o.Len())
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(OptionTypeDHCP4oDHCP6Server))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// append servers
+	for i, server := range o.Servers {
+		if _, err := ipv6Addr(server); err != nil {
+			return nil, err
+		}
+		copy(b[(i*16)+4:(i*16)+20], server)
+	}
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionDHCP4oDHCP6Server
+func (o OptionDHCP4oDHCP6Server) Clone() Option {
+	o.Servers = cloneIPs(o.Servers)
+
+	return &o
+}
+
 // OptionNextHop implements the Next Hop option proposed in
 // https://tools.ietf.org/html/draft-ietf-mif-dhcpv6-route-option-05#section-5.1
 type OptionNextHop struct {
@@ -1044,6 +2372,25 @@ type OptionNextHop struct {
 	Address net.IP
 }
 
+// NewOptionNextHop returns an OptionNextHop for given IPv6 address or error
+// if the address is not a valid IPv6 address
+func NewOptionNextHop(addr netip.Addr) (*OptionNextHop, error) {
+	if !addr.Is6() || addr.Is4In6() {
+		return nil, errInvalidIPv6Address
+	}
+
+	a := addr.As16()
+	return &OptionNextHop{
+		Address: net.IP(a[:]),
+	}, nil
+}
+
+// Addr returns the address of this OptionNextHop as netip.Addr or error if
+// it is not a valid IPv6 address
+func (o OptionNextHop) Addr() (netip.Addr, error) {
+	return ipv6Addr(o.Address)
+}
+
 func (o OptionNextHop) String() string {
 	output := fmt.Sprintf("next-hop %s", o.Address)
 	if len(o.options) > 0 {
@@ -1072,6 +2419,9 @@ func (o OptionNextHop) Marshal() ([]byte, error) {
 	// set length
 	binary.BigEndian.PutUint16(b[2:4], o.Len())
 	// set address
+	if _, err := ipv6Addr(o.Address); err != nil {
+		return nil, err
+	}
 	b = append(b, o.Address...)
 	// append any options
 	if len(o.options) > 0 {
@@ -1084,6 +2434,14 @@ func (o OptionNextHop) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionNextHop
+func (o OptionNextHop) Clone() Option {
+	o.options = o.options.Clone()
+	o.Address = cloneIP(o.Address)
+
+	return &o
+}
+
 type RoutePreference uint8
 
 // Route preferences as described at https://tools.ietf.org/html/draft-ietf-mif-dhcpv6-route-option-05#section-5.2
@@ -1118,12 +2476,49 @@ type OptionRoutePrefix struct {
 	RouteLifetime uint32
 	PrefixLength  uint8
 	Preference    RoutePreference
-	Metric        uint8
 	Prefix        net.IP
 }
 
+// NewOptionRoutePrefix returns an OptionRoutePrefix for given IPv6 prefix or
+// error if the prefix is not a valid IPv6 prefix. Any host bits set in prefix
+// are cleared
+func NewOptionRoutePrefix(prefix netip.Prefix, lifetime uint32, preference RoutePreference) (*OptionRoutePrefix, error) {
+	if !prefix.IsValid() || !prefix.Addr().Is6() || prefix.Addr().Is4In6() {
+		return nil, errInvalidIPv6Address
+	}
+
+	switch preference {
+	case RoutePreferenceLow, RoutePreferenceMedium, RoutePreferenceHigh:
+	default:
+		return nil, errInvalidRoutePreference
+	}
+
+	a := prefix.Masked().Addr().As16()
+	return &OptionRoutePrefix{
+		RouteLifetime: lifetime,
+		PrefixLength:  uint8(prefix.Bits()),
+		Preference:    preference,
+		Prefix:        net.IP(a[:]),
+	}, nil
+}
+
+// NetPrefix returns the prefix of this OptionRoutePrefix as netip.Prefix or
+// error if either the prefix or its length is invalid
+func (o OptionRoutePrefix) NetPrefix() (netip.Prefix, error) {
+	addr, err := ipv6Addr(o.Prefix)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if o.PrefixLength > 128 {
+		return netip.Prefix{}, errInvalidPrefixLength
+	}
+
+	return netip.PrefixFrom(addr, int(o.PrefixLength)), nil
+}
+
 func (o OptionRoutePrefix) String() string {
-	output := fmt.Sprintf("route-prefix %s/%d", o.Prefix, o.PrefixLength)
+	output := fmt.Sprintf("route-prefix %s/%d lifetime:%s preference:%s", o.Prefix, o.PrefixLength,
+		time.Duration(o.RouteLifetime)*time.Second, o.Preference)
 	if len(o.options) > 0 {
 		output += fmt.Sprintf(" %s", o.options)
 	}
@@ -1152,16 +2547,25 @@ func (o OptionRoutePrefix) Marshal() ([]byte, error) {
 	// set router lifetime
 	binary.BigEndian.PutUint32(b[4:8], o.RouteLifetime)
 	// set prefix length
+	if o.PrefixLength > 128 {
+		return nil, errInvalidPrefixLength
+	}
 	b[8] = o.PrefixLength
 	// set router preference
 	// medium is 00, which is default
 	switch o.Preference {
+	case RoutePreferenceMedium:
 	case RoutePreferenceLow:
 		b[9] ^= 24 // 2^4 + 2^3
 	case RoutePreferenceHigh:
 		b[9] ^= 8 // 2^3
+	default:
+		return nil, errInvalidRoutePreference
 	}
 	// append prefix
+	if _, err := ipv6Addr(o.Prefix); err != nil {
+		return nil, err
+	}
 	b = append(b, o.Prefix...)
 	// append any options
 	if len(o.options) > 0 {
@@ -1175,9 +2579,91 @@ func (o OptionRoutePrefix) Marshal() ([]byte, error) {
 	return b, nil
 }
 
+// Clone returns a deep copy of this OptionRoutePrefix
+func (o OptionRoutePrefix) Clone() Option {
+	o.options = o.options.Clone()
+	o.Prefix = cloneIP(o.Prefix)
+
+	return &o
+}
+
+// OptionUnknown holds an option of a type this package doesn't decode, so
+// it is kept as-is when decoding and marshalling messages
+type OptionUnknown struct {
+	Code OptionType
+	Data []byte
+}
+
+func (o OptionUnknown) String() string {
+	return fmt.Sprintf("unknown-option %s %x", o.Code, o.Data)
+}
+
+// Len returns the length in bytes of OptionUnknown's body
+func (o OptionUnknown) Len() uint16 {
+	return uint16(len(o.Data))
+}
+
+// Type returns the option type of this OptionUnknown
+func (o OptionUnknown) Type() OptionType {
+	return o.Code
+}
+
+// Marshal returns byte slice representing this OptionUnknown
+func (o OptionUnknown) Marshal() ([]byte, error) {
+	if len(o.Data) > math.MaxUint16 {
+		return nil, errOptionTooLong
+	}
+
+	// prepare byte slice of appropriate length
+	b := make([]byte, 4)
+	// set type
+	binary.BigEndian.PutUint16(b[0:2], uint16(o.Code))
+	// set length
+	binary.BigEndian.PutUint16(b[2:4], o.Len())
+	// append data
+	b = append(b, o.Data...)
+
+	return b, nil
+}
+
+// Clone returns a deep copy of this OptionUnknown
+func (o OptionUnknown) Clone() Option {
+	o.Data = append([]byte(nil), o.Data...)
+
+	return &o
+}
+
+// helper function to check whether given net.IP is a 16 byte IPv6 address
+// that is not an IPv4-mapped address and return it as netip.Addr
+func ipv6Addr(ip net.IP) (netip.Addr, error) {
+	if len(ip) != net.IPv6len {
+		return netip.Addr{}, errInvalidIPv6Address
+	}
+
+	addr, _ := netip.AddrFromSlice(ip)
+	if addr.Is4In6() {
+		return netip.Addr{}, errInvalidIPv6Address
+	}
+
+	return addr, nil
+}
+
+// helper function to decode the DUID in an option, keeping a DUID-LLT or
+// DUID-LL with a mismatching link-layer address since it still identifies the
+// client
+func decodeOptionDUID(data []byte) (DUID, error) {
+	duid, err := DecodeDUID(data)
+	if errors.Is(err, ErrInvalidLinkLayerAddress) {
+		return duid, nil
+	}
+
+	return duid, err
+}
+
 // DecodeOptions takes DHCPv6 option bytes and tries to decode every handled
 // option, looking at its type and the given length, and returns a slice
-// containing all decoded structs
+// containing all decoded structs. Options of other types are returned as
+// OptionUnknown
 func DecodeOptions(data []byte) (Options, error) {
 	// empty container
 	list := Options{}
@@ -1201,14 +2687,14 @@ func DecodeOptions(data []byte) (Options, error) {
 		switch optionType {
 		case OptionTypeClientID:
 			currentOption = &OptionClientID{}
-			duid, err := DecodeDUID(data[4 : 4+optionLen])
+			duid, err := decodeOptionDUID(data[4 : 4+optionLen])
 			if err != nil {
 				return list, err
 			}
 			currentOption.(*OptionClientID).DUID = duid
 		case OptionTypeServerID:
 			currentOption = &OptionServerID{}
-			duid, err := DecodeDUID(data[4 : 4+optionLen])
+			duid, err := decodeOptionDUID(data[4 : 4+optionLen])
 			if err != nil {
 				return list, err
 			}
@@ -1287,6 +2773,141 @@ func DecodeOptions(data []byte) (Options, error) {
 			if optionLen > 4 {
 				currentOption.(*OptionVendorClass).decodeClassData(data[8 : 4+optionLen])
 			}
+		case OptionTypeDNSServer:
+			// keep a body that isn't a list of addresses as is
+			if optionLen%16 != 0 {
+				currentOption = &OptionUnknown{Code: optionType, Data: data[4 : 4+optionLen]}
+				break
+			}
+			currentOption = &OptionDNSServer{}
+			for i := uint16(0); i < optionLen; i += 16 {
+				currentOption.(*OptionDNSServer).Servers = append(currentOption.(*OptionDNSServer).Servers, data[4+i:20+i])
+			}
+		case OptionTypeDNSSearchList:
+			currentOption = &OptionDNSSearchList{}
+			// keep a body that doesn't hold valid domain names as is
+			if err := currentOption.(*OptionDNSSearchList).decodeDomainNames(data[4 : 4+optionLen]); err != nil {
+				currentOption = &OptionUnknown{Code: optionType, Data: data[4 : 4+optionLen]}
+			}
+		case OptionTypeIAPD:
+			if optionLen < 12 {
+				return list, errOptionTooShort
+			}
+			currentOption = &OptionIAPD{
+				IAID: binary.BigEndian.Uint32(data[4:8]),
+				T1:   time.Duration(binary.BigEndian.Uint32(data[8:12])) * time.Second,
+				T2:   time.Duration(binary.BigEndian.Uint32(data[12:16])) * time.Second,
+			}
+			if optionLen > 12 {
+				var err error
+				currentOption.(*OptionIAPD).options, err = DecodeOptions(data[16 : optionLen+4])
+				if err != nil {
+					return list, err
+				}
+			}
+		case OptionTypeIAPrefix:
+			if optionLen < 25 {
+				return list, errOptionTooShort
+			}
+			if data[12] > 128 {
+				return list, errInvalidPrefixLength
+			}
+			currentOption = &OptionIAPrefix{
+				PreferredLifetime: time.Duration(binary.BigEndian.Uint32(data[4:8])) * time.Second,
+				ValidLifetime:     time.Duration(binary.BigEndian.Uint32(data[8:12])) * time.Second,
+				PrefixLength:      data[12],
+				Prefix:            data[13:29],
+			}
+			if optionLen > 25 {
+				var err error
+				currentOption.(*OptionIAPrefix).options, err = DecodeOptions(data[29 : optionLen+4])
+				if err != nil {
+					return list, err
+				}
+				// an excluded prefix can only be checked knowing the delegated prefix
+				if _, err := currentOption.(*OptionIAPrefix).ExcludedPrefix(); err != nil {
+					return list, err
+				}
+			}
+		case OptionTypePDExclude:
+			if optionLen < 2 {
+				return list, errOptionTooShort
+			}
+			currentOption = &OptionPDExclude{
+				PrefixLength: data[4],
+				SubnetID:     data[5 : optionLen+4],
+			}
+		case OptionTypeRemoteID:
+			if optionLen < 4 {
+				return list, errOptionTooShort
+			}
+			currentOption = &OptionRemoteID{
+				EnterpriseNumber: binary.BigEndian.Uint32(data[4:8]),
+				RemoteID:         data[8 : optionLen+4],
+			}
+		case OptionTypeClientFQDN:
+			currentOption = &OptionClientFQDN{}
+			// keep a body that doesn't hold flags and a valid domain name as is
+			if err := currentOption.(*OptionClientFQDN).decodeDomainName(data[4 : 4+optionLen]); err != nil {
+				currentOption = &OptionUnknown{Code: optionType, Data: data[4 : 4+optionLen]}
+			}
+		case OptionTypeLQQuery:
+			if optionLen < 17 {
+				return list, errOptionTooShort
+			}
+			currentOption = &OptionLQQuery{
+				QueryType:   QueryType(data[4]),
+				LinkAddress: data[5:21],
+			}
+			if optionLen > 17 {
+				var err error
+				currentOption.(*OptionLQQuery).options, err = DecodeOptions(data[21 : optionLen+4])
+				if err != nil {
+					return list, err
+				}
+			}
+		case OptionTypeClientData:
+			currentOption = &OptionClientData{}
+			if optionLen > 0 {
+				var err error
+				currentOption.(*OptionClientData).options, err = DecodeOptions(data[4 : optionLen+4])
+				if err != nil {
+					return list, err
+				}
+			}
+		case OptionTypeCLTTime:
+			if optionLen < 4 {
+				return list, errOptionTooShort
+			}
+			if optionLen > 4 {
+				return list, errOptionTooLong
+			}
+			currentOption = &OptionCLTTime{
+				Time: time.Duration(binary.BigEndian.Uint32(data[4:8])) * time.Second,
+			}
+		case OptionTypeLQRelayData:
+			if optionLen < 16 {
+				return list, errOptionTooShort
+			}
+			currentOption = &OptionLQRelayData{
+				PeerAddress:  data[4:20],
+				RelayMessage: data[20 : optionLen+4],
+			}
+		case OptionTypeLQClientLink:
+			if optionLen%16 != 0 {
+				return list, errOptionTooShort
+			}
+			currentOption = &OptionLQClientLink{}
+			for i := uint16(0); i < optionLen; i += 16 {
+				currentOption.(*OptionLQClientLink).LinkAddresses = append(currentOption.(*OptionLQClientLink).LinkAddresses, data[4+i:20+i])
+			}
+		case OptionTypeRelayID:
+			currentOption = &OptionRelayID{}
+			duid, err := decodeOptionDUID(data[4 : 4+optionLen])
+			if err != nil {
+				return list, err
+			}
+			currentOption.(*OptionRelayID).DUID = duid
 		case OptionTypeBootFileURL:
 			currentOption = &OptionBootFileURL{}
 			if optionLen > 0 {
@@ -1315,6 +2936,18 @@ func DecodeOptions(data []byte) (Options, error) {
 				RevisionMajor: data[5],
 				RevisionMinor: data[6],
 			}
+		case OptionTypeDHCPv4Message:
+			currentOption = &OptionDHCPv4Message{
+				Message: data[4 : 4+optionLen],
+			}
+		case OptionTypeDHCP4oDHCP6Server:
+			if optionLen%16 != 0 {
+				return list, errOptionTooShort
+			}
+			currentOption = &OptionDHCP4oDHCP6Server{}
+			for i := uint16(0); i < optionLen; i += 16 {
+				currentOption.(*OptionDHCP4oDHCP6Server).Servers = append(currentOption.(*OptionDHCP4oDHCP6Server).Servers, data[4+i:20+i])
+			}
 		case OptionTypeNextHop:
 			if optionLen < 16 {
 				return list, errOptionTooShort
@@ -1334,6 +2967,9 @@ func DecodeOptions(data []byte) (Options, error) {
 			if optionLen < 22 {
 				return list, errOptionTooShort
 			}
+			if data[8] > 128 {
+				return list, errInvalidPrefixLength
+			}
 			currentOption = &OptionRoutePrefix{
 				PrefixLength: data[8],
 				Prefix:       data[10:26],
@@ -1354,7 +2990,10 @@ func DecodeOptions(data []byte) (Options, error) {
 			}
 
 		default:
-			fmt.Printf("unhandled option type: %s\n", optionType)
+			currentOption = &OptionUnknown{
+				Code: optionType,
+				Data: data[4 : 4+optionLen],
+			}
 		}
 
 		// append last decoded option to list
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"net"
+	"net/netip"
 	"reflect"
 	"strings"
 	"testing"
@@ -37,8 +38,21 @@ func TestOptionTypeString(t *testing.T) {
 		{OptionTypeReconfigureAccept, "Reconfigure Accept (20)"},
 		{OptionTypeDNSServer, "DNS Server (23)"},
 		{OptionTypeDNSSearchList, "DNS Search List (24)"},
+		{OptionTypeIAPD, "Identity Association for Prefix Delegation (25)"},
+		{OptionTypeIAPrefix, "Identity Association Prefix (26)"},
+		{OptionTypeRemoteID, "Remote-ID (37)"},
+		{OptionTypeClientFQDN, "Client FQDN (39)"},
+		{OptionTypeLQQuery, "Leasequery Query (44)"},
+		{OptionTypeClientData, "Client Data (45)"},
+		{OptionTypeCLTTime, "Client Last Transaction Time (46)"},
+		{OptionTypeLQRelayData, "Leasequery Relay Data (47)"},
+		{OptionTypeLQClientLink, "Leasequery Client Link (48)"},
+		{OptionTypeRelayID, "Relay-ID (53)"},
 		{OptionTypeBootFileURL, "Boot File URL (59)"},
 		{OptionTypeBootFileParameters, "Boot File Parameters (60)"},
+		{OptionTypePDExclude, "Prefix Exclude (67)"},
+		{OptionTypeDHCPv4Message, "DHCPv4 Message (87)"},
+		{OptionTypeDHCP4oDHCP6Server, "DHCP4o6 Server Address (88)"},
 		{OptionTypeNextHop, "Next Hop (242)"},
 		{OptionTypeRoutePrefix, "Route Prefix (243)"},
 	}
@@ -80,6 +94,85 @@ func TestAddOption(t *testing.T) {
 	}
 }
 
+func TestSetRemoveDelOption(t *testing.T) {
+	iana := &OptionIANA{}
+	iana.AddOption(&OptionStatusCode{Code: StatusCodeNoAddrsAvail})
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1")})
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::2")})
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::3")})
+
+	// SetOption should replace the existing status code
+	iana.SetOption(&OptionStatusCode{Code: StatusCodeSuccess})
+	if len(iana.Options()) != 4 {
+		t.Errorf("expected %d options, got %d", 4, len(iana.Options()))
+	}
+	if sc := iana.HasOption(OptionTypeStatusCode).(*OptionStatusCode); sc.Code != StatusCodeSuccess {
+		t.Errorf("expected status code %s, got %s", StatusCodeSuccess, sc.Code)
+	}
+
+	// RemoveOption should only remove the first address
+	iana.RemoveOption(OptionTypeIAAddress)
+	addrs := All[*OptionIAAddress](iana.Options())
+	if len(addrs) != 2 {
+		t.Fatalf("expected %d addresses, got %d", 2, len(addrs))
+	}
+	if !addrs[0].Address.Equal(net.ParseIP("2001:db8::2")) || !addrs[1].Address.Equal(net.ParseIP("2001:db8::3")) {
+		t.Errorf("unexpected addresses left: %s, %s", addrs[0].Address, addrs[1].Address)
+	}
+
+	// DelOption should remove all addresses
+	iana.DelOption(OptionTypeIAAddress)
+	if len(iana.Options()) != 1 {
+		t.Errorf("expected %d options, got %d", 1, len(iana.Options()))
+	}
+	if iana.HasOption(OptionTypeIAAddress) != nil {
+		t.Error("expected no addresses left")
+	}
+
+	// removing options that are not there is fine
+	iana.RemoveOption(OptionTypeRapidCommit)
+	iana.DelOption(OptionTypeRapidCommit)
+	if len(iana.Options()) != 1 {
+		t.Errorf("expected %d options, got %d", 1, len(iana.Options()))
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	opts := Options{
+		&OptionElapsedTime{},
+		&OptionIANA{IAID: 1},
+		&OptionIAPD{IAID: 2},
+		&OptionIANA{IAID: 3},
+	}
+
+	if iana, ok := Get[*OptionIANA](opts); !ok {
+		t.Error("expected to get IA_NA")
+	} else if iana.IAID != 1 {
+		t.Errorf("expected IAID %d, got %d", 1, iana.IAID)
+	}
+	if rc, ok := Get[*OptionRapidCommit](opts); ok || rc != nil {
+		t.Errorf("expected no rapid commit, got %v", rc)
+	}
+
+	ianas := All[*OptionIANA](opts)
+	if len(ianas) != 2 {
+		t.Fatalf("expected %d IA_NAs, got %d", 2, len(ianas))
+	}
+	if ianas[0].IAID != 1 || ianas[1].IAID != 3 {
+		t.Errorf("unexpected IAIDs %d, %d", ianas[0].IAID, ianas[1].IAID)
+	}
+	if rcs := All[*OptionRapidCommit](opts); len(rcs) != 0 {
+		t.Errorf("expected no rapid commits, got %d", len(rcs))
+	}
+
+	// nested options are accessible as well
+	nexthop := &OptionNextHop{}
+	nexthop.AddOption(&OptionRoutePrefix{PrefixLength: 64})
+	if prefix, ok := Get[*OptionRoutePrefix](nexthop.Options()); !ok || prefix.PrefixLength != 64 {
+		t.Errorf("expected route prefix in next hop, got %v", prefix)
+	}
+}
+
 // test DecodeOptions
 // each separate Option will be tested in their own test
 // but some edges cases in DecodeOptions will be tested here
@@ -106,8 +199,49 @@ func TestDecodeOptions(t *testing.T) {
 	fixtbyte = make([]byte, 4)
 	if list, err := DecodeOptions(fixtbyte); err != nil {
 		t.Errorf("unexpected error while trying to decode unhandled option type: %s", err.Error())
-	} else if len(list) != 0 {
-		t.Errorf("expected empty list, got %d entries", len(list))
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else if _, ok := list[0].(*OptionUnknown); !ok {
+		t.Errorf("expected unhandled option to decode to OptionUnknown, got %T", list[0])
+	}
+}
+
+// test OptionUnknown
+func TestOptionUnknown(t *testing.T) {
+	var opt *OptionUnknown
+
+	// option type 300 shouldn't be mistaken for option type 44
+	fixtbyte := []byte{1, 44, 0, 3, 1, 2, 3}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionUnknown)
+	}
+
+	if opt.Type() != 300 {
+		t.Errorf("expected type %d, got %d", 300, opt.Type())
+	}
+	if opt.Len() != 3 {
+		t.Errorf("expected length %d, got %d", 3, opt.Len())
+	}
+	if fixtstr := "unknown-option Unknown (300) 010203"; opt.String() != fixtstr {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// check if marshal matches
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionUnknown: %s", err)
+	} else if !bytes.Equal(mshByte, fixtbyte) {
+		t.Errorf("marshalled OptionUnknown didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// test for error on too much data
+	opt.Data = make([]byte, 65536)
+	if _, err := opt.Marshal(); err != errOptionTooLong {
+		t.Errorf("expected error %s, got %v", errOptionTooLong, err)
 	}
 }
 
@@ -142,7 +276,7 @@ func TestOptionClientID(t *testing.T) {
 	}
 
 	// test matching output for String()
-	fixtstr := "client-ID hwaddr/time type 1 time 500000000 aa:bb:cc:dd:ee:ff"
+	fixtstr := "client-ID hwaddr/time type 1 hwtype Ethernet (1) time 500000000 aa:bb:cc:dd:ee:ff"
 	if fixtstr != opt.String() {
 		t.Errorf("unexpected String() output: %s", opt.String())
 	}
@@ -158,7 +292,7 @@ func TestOptionClientID(t *testing.T) {
 	opt = &OptionClientID{
 		DUID: &DUIDLLT{
 			HardwareType: 1,
-			Time:         time.Unix(1446771200, 0),
+			Time:         time.Unix(1446684800, 0),
 		},
 	}
 	opt.DUID.(*DUIDLLT).LinkLayerAddress, _ = net.ParseMAC("aa:bb:cc:dd:ee:ff")
@@ -175,6 +309,18 @@ func TestOptionClientID(t *testing.T) {
 	} else if err != errDUIDTooShort {
 		t.Errorf("unexpected error: %s", err)
 	}
+
+	// check equality with same and different client ID
+	opt = &OptionClientID{DUID: &DUIDLL{HardwareType: 1, LinkLayerAddress: []byte{1, 2, 3, 4, 5, 6}}}
+	if !EqualOptions(opt, &OptionClientID{DUID: &DUIDLL{HardwareType: 1, LinkLayerAddress: []byte{1, 2, 3, 4, 5, 6}}}) {
+		t.Error("expected client ID to be equal")
+	}
+	if EqualOptions(opt, &OptionClientID{DUID: &DUIDLL{HardwareType: 1, LinkLayerAddress: []byte{1, 2, 3, 4, 5, 7}}}) {
+		t.Error("expected client ID not to be equal")
+	}
+	if EqualOptions(opt, &OptionServerID{DUID: opt.DUID}) {
+		t.Error("expected client ID not to equal server ID")
+	}
 }
 
 // test OptionServerID
@@ -208,7 +354,7 @@ func TestOptionServerID(t *testing.T) {
 	}
 
 	// test matching output for String()
-	fixtstr := "server-ID hwaddr/time type 1 time 500000000 aa:bb:cc:dd:ee:ff"
+	fixtstr := "server-ID hwaddr/time type 1 hwtype Ethernet (1) time 500000000 aa:bb:cc:dd:ee:ff"
 	if fixtstr != opt.String() {
 		t.Errorf("unexpected String() output: %s", opt.String())
 	}
@@ -224,7 +370,7 @@ func TestOptionServerID(t *testing.T) {
 	opt = &OptionServerID{
 		DUID: &DUIDLLT{
 			HardwareType: 1,
-			Time:         time.Unix(1446771200, 0),
+			Time:         time.Unix(1446684800, 0),
 		},
 	}
 	opt.DUID.(*DUIDLLT).LinkLayerAddress, _ = net.ParseMAC("aa:bb:cc:dd:ee:ff")
@@ -245,7 +391,7 @@ func TestOptionServerID(t *testing.T) {
 	// test matching 2 OptionServerID's to eachother
 
 	// should not be equal (different type)
-	if opt.Equal(OptionClientID{}) {
+	if EqualOptions(opt, OptionClientID{}) {
 		t.Error("ServerID should not be equal to ClientID")
 	}
 
@@ -253,7 +399,7 @@ func TestOptionServerID(t *testing.T) {
 	noteql := OptionServerID{
 		DUID: &DUIDLL{},
 	}
-	if opt.Equal(noteql) {
+	if EqualOptions(opt, noteql) {
 		t.Error("ServerID should not be equal to ServerID with different DUID")
 	}
 
@@ -261,11 +407,11 @@ func TestOptionServerID(t *testing.T) {
 	eql := &OptionServerID{
 		DUID: &DUIDLLT{
 			HardwareType: 1,
-			Time:         time.Unix(1446771200, 0),
+			Time:         time.Unix(1446684800, 0),
 		},
 	}
 	eql.DUID.(*DUIDLLT).LinkLayerAddress, _ = net.ParseMAC("aa:bb:cc:dd:ee:ff")
-	if !opt.Equal(eql) {
+	if !EqualOptions(opt, eql) {
 		t.Error("ServerID should be equal to ServerID with similar content")
 	}
 }
@@ -722,6 +868,16 @@ func TestStatusCodeString(t *testing.T) {
 		{StatusCodeNoBinding, "NoBinding (3)"},
 		{StatusCodeNotOnLink, "NotOnLink (4)"},
 		{StatusCodeUseMulticast, "UseMulticast (5)"},
+		{StatusCodeNoPrefixAvail, "NoPrefixAvail (6)"},
+		{StatusCodeUnknownQueryType, "UnknownQueryType (7)"},
+		{StatusCodeMalformedQuery, "MalformedQuery (8)"},
+		{StatusCodeNotConfigured, "NotConfigured (9)"},
+		{StatusCodeNotAllowed, "NotAllowed (10)"},
+		{StatusCodeQueryTerminated, "QueryTerminated (11)"},
+		{StatusCodeDataMissing, "DataMissing (12)"},
+		{StatusCodeCatchUpComplete, "CatchUpComplete (13)"},
+		{StatusCodeNotSupported, "NotSupported (14)"},
+		{StatusCodeTLSConnectionRefused, "TLSConnectionRefused (15)"},
 		{255, "Unknown (255)"},
 	}
 
@@ -875,7 +1031,7 @@ func TestOptionVendorClass(t *testing.T) {
 	}
 
 	// test matching output for String()
-	fixtstr := "vendor-class foobar, test"
+	fixtstr := "vendor-class enterprise number Unknown (42) foobar, test"
 	if fixtstr != opt.String() {
 		t.Errorf("unexpected String() output: %s", opt.String())
 	}
@@ -923,309 +1079,1174 @@ func TestOptionDNSServer(t *testing.T) {
 	} else if !bytes.Equal(mshByte, fixtbyte) {
 		t.Errorf("marshalled OptionDNSServer didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
 	}
+
+	// test decoding fixture to the same option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 || !EqualOptions(opt, list[0]) {
+		t.Errorf("expected fixture to decode to %s, got %s", opt, list)
+	}
+
+	// a body that isn't a list of addresses is kept as is
+	fixtbyte = []byte{0, 23, 0, 2, 1, 2}
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if unknown, ok := list[0].(*OptionUnknown); !ok || unknown.Code != OptionTypeDNSServer {
+		t.Errorf("expected OptionUnknown of type %s, got %T", OptionTypeDNSServer, list[0])
+	}
 }
 
-func TestOptionBootFileURL(t *testing.T) {
-	var opt *OptionBootFileURL
+func TestOptionDNSSearchList(t *testing.T) {
+	var opt *OptionDNSSearchList
 
-	fixtbyte := []byte{0, 59, 0, 29, 104, 116, 116, 112, 58, 47, 47, 101, 120, 97, 109, 112, 108, 101, 46, 111, 114, 103, 47, 112, 120, 101, 108, 105, 110, 117, 120, 46, 48}
+	fixtbyte := []byte{0, 24, 0, 19, 7, 101, 120, 97, 109, 112, 108, 101, 3, 99, 111, 109, 0, 3, 102, 111, 111, 0, 0}
 	// test decoding bytes to []Option
 	if list, err := DecodeOptions(fixtbyte); err != nil {
 		t.Errorf("could not decode fixture: %s", err)
 	} else if len(list) != 1 {
 		t.Errorf("expected exactly 1 option, got %d", len(list))
 	} else {
-		opt = list[0].(*OptionBootFileURL)
+		opt = list[0].(*OptionDNSSearchList)
 	}
 
 	// check contents of Option
-	if opt.Type() != OptionTypeBootFileURL {
+	if opt.Type() != OptionTypeDNSSearchList {
 		t.Errorf("unexpected type: %s", opt.Type())
 	}
+	fixtnames := []string{"example.com", "foo", ""}
+	if !reflect.DeepEqual(opt.DomainNames, fixtnames) {
+		t.Errorf("expected domain names %q, got %q", fixtnames, opt.DomainNames)
+	}
 
 	// check body length
-	fixtlen := uint16(29)
+	fixtlen := uint16(19)
 	if opt.Len() != fixtlen {
 		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
 	}
-	fixturl := "http://example.org/pxelinux.0"
-	if opt.URL != fixturl {
-		t.Errorf("expected url %s, got %s", fixturl, opt.URL)
-	}
 
 	// test matching output for String()
-	fixtstr := fmt.Sprintf("boot-file-url %s", fixturl)
+	fixtstr := "DNS-search-list example.com,foo,"
 	if fixtstr != opt.String() {
 		t.Errorf("unexpected String() output: %s", opt.String())
 	}
 
-	// test if marshalled bytes match fixture
+	// create same struct, with a trailing dot, and see if its marshal matches
+	// fixture
+	opt = &OptionDNSSearchList{DomainNames: []string{"example.com.", "foo", ""}}
 	if mshByte, err := opt.Marshal(); err != nil {
-		t.Errorf("error marshalling OptionBootFileURL: %s", err)
+		t.Errorf("error marshalling OptionDNSSearchList: %s", err)
 	} else if !bytes.Equal(fixtbyte, mshByte) {
-		t.Errorf("marshalled OptionBootFileURL didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+		t.Errorf("marshalled OptionDNSSearchList didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
 	}
 
-	// create same struct and see if its marshal matches fixture
-	opt = &OptionBootFileURL{
-		URL: fixturl,
+	// test for error on invalid names
+	for _, name := range []string{"foo..com", strings.Repeat("a", 64) + ".com", strings.Repeat("a.", 128) + "com"} {
+		opt = &OptionDNSSearchList{DomainNames: []string{name}}
+		if _, err := opt.Marshal(); err != errInvalidDomainName {
+			t.Errorf("expected invalid domain name error for %s, got %v", name, err)
+		}
 	}
-	if mshByte, err := opt.Marshal(); err != nil {
-		t.Errorf("error marshalling OptionBootFileURL: %s", err)
-	} else if !bytes.Equal(fixtbyte, mshByte) {
-		t.Errorf("marshalled OptionBootFileURL didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+
+	// partial names, compressed names and truncated labels are kept as is
+	for _, fixtbyte := range [][]byte{
+		{0, 24, 0, 4, 3, 102, 111, 111},
+		{0, 24, 0, 2, 192, 12},
+		{0, 24, 0, 3, 3, 102, 111},
+	} {
+		testDecodeUnknownOption(t, fixtbyte)
 	}
 }
 
-func TestOptionBootFileParameters(t *testing.T) {
-	var opt *OptionBootFileParameters
+// helper function to check that the option in data decodes to OptionUnknown of
+// its type and marshals to the same bytes
+func testDecodeUnknownOption(t *testing.T, data []byte) {
+	t.Helper()
 
-	fixtbyte := []byte{0, 60, 0, 18, 0, 3, 102, 111, 111, 0, 3, 98, 97, 114, 0, 6, 102, 111, 111, 98, 97, 114}
+	list, err := DecodeOptions(data)
+	if err != nil {
+		t.Errorf("unexpected error decoding %v: %s", data, err)
+		return
+	}
+	if unknown, ok := list[0].(*OptionUnknown); !ok || uint16(unknown.Code) != uint16(data[0])<<8|uint16(data[1]) {
+		t.Errorf("expected %v to decode to OptionUnknown of its type, got %s", data, list[0])
+	}
+	if mshByte, err := list.Marshal(); err != nil || !bytes.Equal(data, mshByte) {
+		t.Errorf("expected %v to marshal to the same bytes, got %v (%v)", data, mshByte, err)
+	}
+}
 
+func TestOptionIAPD(t *testing.T) {
+	var opt *OptionIAPD
+
+	fixtbyte := []byte{0, 25, 0, 47, 0, 0, 0, 1, 0, 0, 7, 8, 0, 0, 11, 184,
+		0, 26, 0, 31, 0, 0, 14, 16, 0, 0, 28, 32, 60, 32, 1, 13, 184, 0, 0, 0, 16, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 67, 0, 2, 64, 160}
 	// test decoding bytes to []Option
 	if list, err := DecodeOptions(fixtbyte); err != nil {
 		t.Errorf("could not decode fixture: %s", err)
 	} else if len(list) != 1 {
 		t.Errorf("expected exactly 1 option, got %d", len(list))
 	} else {
-		opt = list[0].(*OptionBootFileParameters)
+		opt = list[0].(*OptionIAPD)
 	}
 
 	// check contents of Option
-	if opt.Type() != OptionTypeBootFileParameters {
+	if opt.Type() != OptionTypeIAPD {
 		t.Errorf("unexpected type: %s", opt.Type())
 	}
+	if opt.IAID != 1 {
+		t.Errorf("expected IAID 1, got %d", opt.IAID)
+	}
+	if opt.T1 != 30*time.Minute || opt.T2 != 50*time.Minute {
+		t.Errorf("unexpected T1 %s or T2 %s", opt.T1, opt.T2)
+	}
 
 	// check body length
-	fixtlen := uint16(18)
+	fixtlen := uint16(47)
 	if opt.Len() != fixtlen {
 		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
 	}
-	fixtparam := []string{"foo", "bar", "foobar"}
-	if !reflect.DeepEqual(fixtparam, opt.Parameters) {
-		t.Errorf("expected params %s, got %s", fixtparam, opt.Parameters)
-	}
 
 	// test matching output for String()
-	fixtstr := "boot-file-params foo bar foobar"
+	fixtstr := "IA_PD IAID:1 T1:30m0s T2:50m0s [IA_PREFIX 2001:db8:0:10::/60 pltime:1h0m0s vltime:2h0m0s [pd-exclude /64 subnet-id a0]]"
 	if fixtstr != opt.String() {
 		t.Errorf("unexpected String() output: %s", opt.String())
 	}
 
 	// test if marshalled bytes match fixture
 	if mshByte, err := opt.Marshal(); err != nil {
-		t.Errorf("error marshalling OptionBootFileParameters: %s", err)
+		t.Errorf("error marshalling OptionIAPD: %s", err)
 	} else if !bytes.Equal(fixtbyte, mshByte) {
-		t.Errorf("marshalled OptionBootFileParameters didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+		t.Errorf("marshalled OptionIAPD didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
 	}
 
 	// create same struct and see if its marshal matches fixture
-	opt = &OptionBootFileParameters{
-		Parameters: fixtparam,
+	opt = &OptionIAPD{
+		IAID: 1,
+		T1:   30 * time.Minute,
+		T2:   50 * time.Minute,
+	}
+	prefix := &OptionIAPrefix{
+		PreferredLifetime: time.Hour,
+		ValidLifetime:     2 * time.Hour,
+		PrefixLength:      60,
+		Prefix:            net.ParseIP("2001:db8:0:10::"),
 	}
+	if err := prefix.SetExcludedPrefix(netip.MustParsePrefix("2001:db8:0:1a::/64")); err != nil {
+		t.Errorf("could not set excluded prefix: %s", err)
+	}
+	opt.AddOption(prefix)
 	if mshByte, err := opt.Marshal(); err != nil {
-		t.Errorf("error marshalling OptionBootFileParameters: %s", err)
+		t.Errorf("error marshalling OptionIAPD: %s", err)
 	} else if !bytes.Equal(fixtbyte, mshByte) {
-		t.Errorf("marshalled OptionBootFileParameters didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+		t.Errorf("marshalled OptionIAPD didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
 	}
 
-	// test parameter length checking in parsing parameters
-	// mess up the length of the first parameter so decoding breaks
-	// and we end up with an empty set of parameters
-	fixtbyte[5] = 17
-	opt.decodeParameters(fixtbyte[4:])
-	if len(opt.Parameters) != 0 {
-		t.Errorf("option should have 0 parameters but has %d", len(opt.Parameters))
+	// try to decode fixture with too short option length
+	fixtbyte[3] = 11
+	if _, err := DecodeOptions(fixtbyte[:15]); err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %v", err)
 	}
 }
 
-func TestOptionClientSystemArchitectureType(t *testing.T) {
-	var opt *OptionClientSystemArchitectureType
-
-	fixtbyte := []byte{0, 61, 0, 2, 0, 0}
+func TestOptionIAPrefix(t *testing.T) {
+	var opt *OptionIAPrefix
 
+	fixtbyte := []byte{0, 26, 0, 31, 0, 0, 14, 16, 0, 0, 28, 32, 60, 32, 1, 13, 184, 0, 0, 0, 16, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 67, 0, 2, 64, 160}
 	// test decoding bytes to []Option
 	if list, err := DecodeOptions(fixtbyte); err != nil {
 		t.Errorf("could not decode fixture: %s", err)
 	} else if len(list) != 1 {
 		t.Errorf("expected exactly 1 option, got %d", len(list))
 	} else {
-		opt = list[0].(*OptionClientSystemArchitectureType)
+		opt = list[0].(*OptionIAPrefix)
 	}
 
 	// check contents of Option
-	if opt.Type() != OptionTypeClientSystemArchitectureType {
+	if opt.Type() != OptionTypeIAPrefix {
 		t.Errorf("unexpected type: %s", opt.Type())
 	}
+	fixtprefix := netip.MustParsePrefix("2001:db8:0:10::/60")
+	if prefix, err := opt.NetPrefix(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if prefix != fixtprefix {
+		t.Errorf("expected prefix %s, got %s", fixtprefix, prefix)
+	}
+	fixtexcl := netip.MustParsePrefix("2001:db8:0:1a::/64")
+	if excl, err := opt.ExcludedPrefix(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if excl != fixtexcl {
+		t.Errorf("expected excluded prefix %s, got %s", fixtexcl, excl)
+	}
 
 	// check body length
-	fixtlen := uint16(2)
+	fixtlen := uint16(31)
 	if opt.Len() != fixtlen {
 		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
 	}
-	if len(opt.Types) != 1 {
-		t.Errorf("expected type length 1, got %d", len(opt.Types))
-	}
-	fixttype := ArchitectureTypeIntelx86PC
-	if opt.Types[0] != fixttype {
-		t.Errorf("expected type %s, got %s", fixttype, opt.Types[0])
+
+	// an IA prefix without exclude option has no excluded prefix
+	if list, err := DecodeOptions(append([]byte{0, 26, 0, 25}, fixtbyte[4:29]...)); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if excl, err := list[0].(*OptionIAPrefix).ExcludedPrefix(); err != nil || excl.IsValid() {
+		t.Errorf("expected no excluded prefix, got %s (%v)", excl, err)
 	}
 
-	// test matching output for String()
-	fixtstr := "client-system-architecture-type [Intel x86PC (0)]"
-	if fixtstr != opt.String() {
-		t.Errorf("unexpected String() output: %s", opt.String())
+	// an excluded prefix outside the delegated prefix cannot be set
+	if err := opt.SetExcludedPrefix(netip.MustParsePrefix("2001:db8:0:20::/64")); err != errInvalidPDExclude {
+		t.Errorf("expected invalid pd exclude error, got %v", err)
 	}
 
-	// test if marshalled bytes match fixture
-	if mshByte, err := opt.Marshal(); err != nil {
-		t.Errorf("error marshalling OptionClientSystemArchitectureType: %s", err)
-	} else if !bytes.Equal(fixtbyte, mshByte) {
-		t.Errorf("marshalled OptionClientSystemArchitectureType didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	// an exclude option not matching the delegated prefix length should not
+	// decode, nor marshal
+	fixtbyte[12] = 52
+	if _, err := DecodeOptions(fixtbyte); err != errInvalidPDExclude {
+		t.Errorf("expected invalid pd exclude error, got %v", err)
+	}
+	opt.PrefixLength = 52
+	if _, err := opt.Marshal(); err != errInvalidPDExclude {
+		t.Errorf("expected invalid pd exclude error, got %v", err)
 	}
 
-	// create same struct and see if its marshal matches fixture
-	opt = &OptionClientSystemArchitectureType{
-		Types: []ArchitectureType{fixttype},
+	// invalid prefix length
+	fixtbyte[12] = 129
+	if _, err := DecodeOptions(fixtbyte); err != errInvalidPrefixLength {
+		t.Errorf("expected invalid prefix length error, got %v", err)
 	}
-	if mshByte, err := opt.Marshal(); err != nil {
-		t.Errorf("error marshalling OptionClientSystemArchitectureType: %s", err)
-	} else if !bytes.Equal(fixtbyte, mshByte) {
-		t.Errorf("marshalled OptionClientSystemArchitectureType didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+
+	// try to decode fixture with too short option length
+	if _, err := DecodeOptions(append([]byte{0, 26, 0, 24}, fixtbyte[4:28]...)); err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %v", err)
 	}
 }
 
-func TestArchitectureTypeString(t *testing.T) {
+func TestOptionPDExclude(t *testing.T) {
 	tests := []struct {
-		in  ArchitectureType
-		out string
+		delegated string
+		excluded  string
+		subnetID  []byte
 	}{
-		{ArchitectureTypeIntelx86PC, "Intel x86PC (0)"},
-		{ArchitectureTypeNECPC98, "NEC/PC98 (1)"},
-		{ArchitectureTypeEFIItanium, "EFI Itanium (2)"},
-		{ArchitectureTypeDECAlpha, "DEC Alpha (3)"},
-		{ArchitectureTypeArcx86, "Arc x86 (4)"},
-		{ArchitectureTypeIntelLeanClient, "Intel Lean Client (5)"},
-		{ArchitectureTypeEFIIA32, "EFI IA32 (6)"},
-		{ArchitectureTypeEFIBC, "EFI BC (7)"},
-		{ArchitectureTypeEFIXscale, "EFI Xscale (8)"},
-		{ArchitectureTypeEFIx8664, "EFI x86-64 (9)"},
-		{255, "Unknown (255)"},
+		{"2001:db8:0:ff00::/56", "2001:db8:0:ff01::/64", []byte{0x01}},
+		{"2001:db8:1::/48", "2001:db8:1:8000::/49", []byte{0x80}},
+		{"2001:db8:0:10::/60", "2001:db8:0:1a::/64", []byte{0xa0}},
+		{"2001:db8::/32", "2001:db8:abcd:ef00::/60", []byte{0xab, 0xcd, 0xef, 0x00}},
+		{"2001:db8::/64", "2001:db8::1/128", []byte{0, 0, 0, 0, 0, 0, 0, 1}},
 	}
 
 	for _, test := range tests {
-		if strings.Compare(test.in.String(), test.out) != 0 {
-			t.Errorf("expected %s but got %s", test.out, test.in.String())
+		delegated := netip.MustParsePrefix(test.delegated)
+		excluded := netip.MustParsePrefix(test.excluded)
+		opt, err := NewOptionPDExclude(delegated, excluded)
+		if err != nil {
+			t.Errorf("could not create OptionPDExclude for %s: %s", test.excluded, err)
+			continue
+		}
+		if !bytes.Equal(opt.SubnetID, test.subnetID) {
+			t.Errorf("expected subnet ID %x for %s, got %x", test.subnetID, test.excluded, opt.SubnetID)
+		}
+
+		// marshal and decode option again
+		mshByte, err := opt.Marshal()
+		if err != nil {
+			t.Errorf("error marshalling OptionPDExclude: %s", err)
+			continue
+		}
+		list, err := DecodeOptions(mshByte)
+		if err != nil {
+			t.Errorf("could not decode OptionPDExclude: %s", err)
+			continue
+		}
+		if excl, err := list[0].(*OptionPDExclude).ExcludedPrefix(delegated); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		} else if excl != excluded {
+			t.Errorf("expected excluded prefix %s, got %s", excluded, excl)
 		}
 	}
-}
 
-func TestOptionClientNetworkInterfaceIdentifier(t *testing.T) {
-	var opt *OptionClientNetworkInterfaceIdentifier
+	// excluded prefix should be within and longer than delegated prefix
+	delegated := netip.MustParsePrefix("2001:db8:0:ff00::/56")
+	for _, excluded := range []string{"2001:db8:1:ff01::/64", "2001:db8::/48", "2001:db8:0:ff00::/56"} {
+		if _, err := NewOptionPDExclude(delegated, netip.MustParsePrefix(excluded)); err != errInvalidPDExclude {
+			t.Errorf("expected invalid pd exclude error for %s, got %v", excluded, err)
+		}
+	}
 
-	fixtbyte := []byte{0, 62, 0, 3, 1, 2, 1}
+	// the subnet ID length should match the prefix lengths
+	opt := &OptionPDExclude{PrefixLength: 64, SubnetID: []byte{1, 0}}
+	if _, err := opt.ExcludedPrefix(delegated); err != errInvalidPDExclude {
+		t.Errorf("expected invalid pd exclude error, got %v", err)
+	}
+
+	// test matching output for String()
+	fixtstr := "pd-exclude /64 subnet-id 0100"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// try to decode option with too short option length
+	if _, err := DecodeOptions([]byte{0, 67, 0, 1, 64}); err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %v", err)
+	}
+}
 
+func TestOptionRemoteID(t *testing.T) {
+	var opt *OptionRemoteID
+
+	fixtbyte := []byte{0, 37, 0, 10, 0, 0, 13, 233, 101, 116, 104, 48, 47, 49}
 	// test decoding bytes to []Option
 	if list, err := DecodeOptions(fixtbyte); err != nil {
 		t.Errorf("could not decode fixture: %s", err)
 	} else if len(list) != 1 {
 		t.Errorf("expected exactly 1 option, got %d", len(list))
 	} else {
-		opt = list[0].(*OptionClientNetworkInterfaceIdentifier)
+		opt = list[0].(*OptionRemoteID)
 	}
 
 	// check contents of Option
-	if opt.Type() != OptionTypeClientNetworkInterfaceIdentifier {
+	if opt.Type() != OptionTypeRemoteID {
 		t.Errorf("unexpected type: %s", opt.Type())
 	}
+	fixtenum := uint32(3561)
+	if opt.EnterpriseNumber != fixtenum {
+		t.Errorf("expected enterprise number %d, got %d", fixtenum, opt.EnterpriseNumber)
+	}
+	fixtid := []byte("eth0/1")
+	if !bytes.Equal(opt.RemoteID, fixtid) {
+		t.Errorf("expected remote-id %v, got %v", fixtid, opt.RemoteID)
+	}
 
 	// check body length
-	fixtlen := uint16(3)
+	fixtlen := uint16(10)
 	if opt.Len() != fixtlen {
 		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
 	}
-	fixttype := InterfaceTypeUNDI
-	if opt.InterfaceType != fixttype {
-		t.Errorf("expected type %d, got %d", fixttype, opt.InterfaceType)
-	}
-	fixtrevmaj := uint8(2)
-	if opt.RevisionMajor != fixtrevmaj {
-		t.Errorf("expected major revision %d, got %d", fixtrevmaj, opt.RevisionMajor)
-	}
-	fixtrevmin := uint8(1)
-	if opt.RevisionMinor != fixtrevmin {
-		t.Errorf("expected minor revision %d, got %d", fixtrevmin, opt.RevisionMinor)
-	}
 
 	// test matching output for String()
-	fixtstr := "client-network-interface-identifier Universal Network Device Interface (UNDI) (1): 2.1"
+	fixtstr := "remote-ID enterprise number Broadband Forum (3561) (id: 657468302f31)"
 	if fixtstr != opt.String() {
 		t.Errorf("unexpected String() output: %s", opt.String())
 	}
 
-	// test if marshalled bytes match fixture
-	if mshByte, err := opt.Marshal(); err != nil {
-		t.Errorf("error marshalling OptionClientNetworkInterfaceIdentifier: %s", err)
-	} else if !bytes.Equal(fixtbyte, mshByte) {
-		t.Errorf("marshalled OptionClientNetworkInterfaceIdentifier didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
-	}
-
 	// create same struct and see if its marshal matches fixture
-	opt = &OptionClientNetworkInterfaceIdentifier{
-		InterfaceType: fixttype,
-		RevisionMajor: fixtrevmaj,
-		RevisionMinor: fixtrevmin,
+	opt = &OptionRemoteID{
+		EnterpriseNumber: fixtenum,
+		RemoteID:         fixtid,
 	}
 	if mshByte, err := opt.Marshal(); err != nil {
-		t.Errorf("error marshalling OptionClientNetworkInterfaceIdentifier: %s", err)
+		t.Errorf("error marshalling OptionRemoteID: %s", err)
 	} else if !bytes.Equal(fixtbyte, mshByte) {
-		t.Errorf("marshalled OptionClientNetworkInterfaceIdentifier didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+		t.Errorf("marshalled OptionRemoteID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
 	}
 
 	// try to decode fixture with too short option length
-	fixtbyte[3] = 2
-	// test decoding bytes to []Option
-	if _, err := DecodeOptions(fixtbyte); err == nil {
-		t.Error("expected error while decoding too short option")
-	} else {
-		fixterr := errOptionTooShort
-		if err != fixterr {
-			t.Errorf("expected option too short error, got %s", err)
-		}
-	}
-}
-
-func TestInterfaceTypeString(t *testing.T) {
-	tests := []struct {
-		in  InterfaceType
-		out string
-	}{
-		{InterfaceTypeUNDI, "Universal Network Device Interface (UNDI) (1)"},
-		{255, "Unknown (255)"},
-	}
-
-	for _, test := range tests {
-		if strings.Compare(test.in.String(), test.out) != 0 {
-			t.Errorf("expected %s but got %s", test.out, test.in.String())
-		}
+	if _, err := DecodeOptions([]byte{0, 37, 0, 3, 0, 0, 13}); err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %v", err)
 	}
 }
 
-func TestOptionNextHop(t *testing.T) {
-	var opt *OptionNextHop
+func TestOptionClientFQDN(t *testing.T) {
+	var opt *OptionClientFQDN
 
-	fixtbyte := []byte{0, 242, 0, 16, 253, 212, 71, 50, 21, 217, 234, 106, 0, 0, 0, 0, 0, 0, 16, 0}
+	fixtbyte := []byte{0, 39, 0, 19, 1, 4, 104, 111, 115, 116, 7, 101, 120, 97, 109, 112, 108, 101, 3, 99, 111, 109, 0}
 	// test decoding bytes to []Option
 	if list, err := DecodeOptions(fixtbyte); err != nil {
 		t.Errorf("could not decode fixture: %s", err)
 	} else if len(list) != 1 {
 		t.Errorf("expected exactly 1 option, got %d", len(list))
 	} else {
-		opt = list[0].(*OptionNextHop)
+		opt = list[0].(*OptionClientFQDN)
 	}
 
 	// check contents of Option
-	if opt.Type() != OptionTypeNextHop {
+	if opt.Type() != OptionTypeClientFQDN {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+	if opt.Flags != ClientFQDNFlagS {
+		t.Errorf("expected flags %d, got %d", ClientFQDNFlagS, opt.Flags)
+	}
+	fixtname := "host.example.com."
+	if opt.DomainName != fixtname {
+		t.Errorf("expected domain name %s, got %s", fixtname, opt.DomainName)
+	}
+
+	// check body length
+	fixtlen := uint16(19)
+	if opt.Len() != fixtlen {
+		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
+	}
+
+	// test matching output for String()
+	fixtstr := "client-FQDN host.example.com. (flags: 0x01)"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionClientFQDN{
+		Flags:      ClientFQDNFlagS,
+		DomainName: fixtname,
+	}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionClientFQDN: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionClientFQDN didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// partial and empty names round trip as well
+	for _, fixtbyte := range [][]byte{
+		{0, 39, 0, 6, 0, 4, 104, 111, 115, 116},
+		{0, 39, 0, 1, 4},
+	} {
+		if list, err := DecodeOptions(fixtbyte); err != nil {
+			t.Errorf("could not decode %v: %s", fixtbyte, err)
+		} else if mshByte, err := list[0].Marshal(); err != nil {
+			t.Errorf("error marshalling %s: %s", list[0], err)
+		} else if !bytes.Equal(fixtbyte, mshByte) {
+			t.Errorf("marshalled OptionClientFQDN didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+		}
+	}
+
+	// bodies without flags or with data following the root label are kept as
+	// is
+	testDecodeUnknownOption(t, []byte{0, 39, 0, 0})
+	testDecodeUnknownOption(t, []byte{0, 39, 0, 3, 0, 0, 1})
+}
+
+func TestOptionRelayID(t *testing.T) {
+	var opt *OptionRelayID
+
+	fixtbyte := []byte{0, 53, 0, 10, 0, 3, 0, 1, 170, 187, 204, 221, 238, 255}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionRelayID)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeRelayID {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+	if opt.DUID.Type() != DUIDTypeLL {
+		t.Errorf("unexpected DUID type: %s", opt.DUID.Type())
+	}
+
+	// test matching output for String()
+	fixtstr := "relay-ID hwaddr type 3 hwtype Ethernet (1) aa:bb:cc:dd:ee:ff"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionRelayID{
+		DUID: &DUIDLL{HardwareType: 1},
+	}
+	opt.DUID.(*DUIDLL).LinkLayerAddress, _ = net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionRelayID: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionRelayID didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// try to decode a OptionRelayID with too few bytes for DUID
+	if _, err := DecodeOptions([]byte{0, 53, 0, 1, 0}); err != errDUIDTooShort {
+		t.Errorf("expected DUID too short error, got %v", err)
+	}
+}
+
+func TestOptionLQQuery(t *testing.T) {
+	var opt *OptionLQQuery
+
+	fixtbyte := []byte{0, 44, 0, 45, 1, 254, 128, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1,
+		0, 5, 0, 24, 32, 1, 13, 184, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionLQQuery)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeLQQuery {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+	if opt.QueryType != QueryTypeByAddress {
+		t.Errorf("expected query type %s, got %s", QueryTypeByAddress, opt.QueryType)
+	}
+	fixtlinkaddr := net.ParseIP("fe80::1")
+	if !opt.LinkAddress.Equal(fixtlinkaddr) {
+		t.Errorf("expected link address %s, got %s", fixtlinkaddr, opt.LinkAddress)
+	}
+	if opt.HasOption(OptionTypeIAAddress) == nil {
+		t.Error("expected IA address option")
+	}
+
+	// check body length
+	fixtlen := uint16(45)
+	if opt.Len() != fixtlen {
+		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
+	}
+
+	// test matching output for String()
+	fixtstr := "lq-query By Address (1) link-address fe80::1 [IA_ADDR 2001:db8::1 pltime:0s vltime:0s]"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// test if marshalled bytes match fixture
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionLQQuery: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionLQQuery didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionLQQuery{
+		QueryType:   QueryTypeByAddress,
+		LinkAddress: fixtlinkaddr,
+	}
+	opt.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1")})
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionLQQuery: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionLQQuery didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// marshalling without a valid link address should fail
+	opt.LinkAddress = nil
+	if _, err := opt.Marshal(); err != errInvalidIPv6Address {
+		t.Errorf("expected invalid address error, got %v", err)
+	}
+
+	// try to decode fixture with too short option length
+	fixtbyte[3] = 16
+	if _, err := DecodeOptions(fixtbyte); err == nil {
+		t.Error("expected error while decoding too short option")
+	} else if err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %s", err)
+	}
+}
+
+func TestQueryTypeString(t *testing.T) {
+	tests := []struct {
+		in  QueryType
+		out string
+	}{
+		{QueryTypeByAddress, "By Address (1)"},
+		{QueryTypeByClientID, "By Client ID (2)"},
+		{QueryTypeByRelayID, "By Relay ID (3)"},
+		{QueryTypeByLinkAddress, "By Link Address (4)"},
+		{QueryTypeByRemoteID, "By Remote ID (5)"},
+		{255, "Unknown (255)"},
+	}
+
+	for _, test := range tests {
+		if strings.Compare(test.in.String(), test.out) != 0 {
+			t.Errorf("expected %s but got %s", test.out, test.in.String())
+		}
+	}
+}
+
+func TestOptionClientData(t *testing.T) {
+	var opt *OptionClientData
+
+	fixtbyte := []byte{0, 45, 0, 22, 0, 1, 0, 10, 0, 3, 0, 1, 170, 187, 204, 221, 238, 255,
+		0, 46, 0, 4, 0, 0, 14, 16}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionClientData)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeClientData {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+	if opt.HasOption(OptionTypeClientID) == nil {
+		t.Error("expected client ID option")
+	}
+	if clt := opt.HasOption(OptionTypeCLTTime); clt == nil {
+		t.Error("expected client last transaction time option")
+	} else if clt.(*OptionCLTTime).Time != time.Hour {
+		t.Errorf("expected clt time %s, got %s", time.Hour, clt.(*OptionCLTTime).Time)
+	}
+
+	// check body length
+	fixtlen := uint16(22)
+	if opt.Len() != fixtlen {
+		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
+	}
+
+	// test matching output for String()
+	fixtstr := "client-data [client-ID hwaddr type 3 hwtype Ethernet (1) aa:bb:cc:dd:ee:ff clt-time 1h0m0s]"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// test if marshalled bytes match fixture
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionClientData: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionClientData didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// try to decode fixture with nested option exceeding the client data
+	fixtbyte[3] = 21
+	if _, err := DecodeOptions(fixtbyte[:25]); err == nil {
+		t.Error("expected error while decoding too short option")
+	} else if err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %s", err)
+	}
+}
+
+func TestOptionCLTTime(t *testing.T) {
+	var opt *OptionCLTTime
+
+	fixtbyte := []byte{0, 46, 0, 4, 0, 1, 81, 128}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionCLTTime)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeCLTTime {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+	fixttime := 24 * time.Hour
+	if opt.Time != fixttime {
+		t.Errorf("expected time %s, got %s", fixttime, opt.Time)
+	}
+
+	// test matching output for String()
+	fixtstr := "clt-time 24h0m0s"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionCLTTime{Time: fixttime}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionCLTTime: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionCLTTime didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// try to decode fixture with wrong option length
+	if _, err := DecodeOptions([]byte{0, 46, 0, 3, 0, 0, 0}); err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %v", err)
+	}
+	if _, err := DecodeOptions([]byte{0, 46, 0, 5, 0, 0, 0, 0, 0}); err != errOptionTooLong {
+		t.Errorf("expected option too long error, got %v", err)
+	}
+}
+
+func TestOptionLQRelayData(t *testing.T) {
+	var opt *OptionLQRelayData
+
+	fixtbyte := []byte{0, 47, 0, 20, 254, 128, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 12, 0, 1, 2}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionLQRelayData)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeLQRelayData {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+	fixtpeer := net.ParseIP("fe80::2")
+	if !opt.PeerAddress.Equal(fixtpeer) {
+		t.Errorf("expected peer address %s, got %s", fixtpeer, opt.PeerAddress)
+	}
+	fixtmsg := []byte{12, 0, 1, 2}
+	if !bytes.Equal(opt.RelayMessage, fixtmsg) {
+		t.Errorf("expected relay message %v, got %v", fixtmsg, opt.RelayMessage)
+	}
+
+	// test matching output for String()
+	fixtstr := "lq-relay-data peer-address fe80::2 relay-message 0c000102"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionLQRelayData{
+		PeerAddress:  fixtpeer,
+		RelayMessage: fixtmsg,
+	}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionLQRelayData: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionLQRelayData didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// try to decode fixture with too short option length
+	fixtbyte[3] = 15
+	if _, err := DecodeOptions(fixtbyte); err == nil {
+		t.Error("expected error while decoding too short option")
+	} else if err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %s", err)
+	}
+}
+
+func TestOptionLQClientLink(t *testing.T) {
+	var opt *OptionLQClientLink
+
+	fixtbyte := []byte{0, 48, 0, 32, 32, 1, 13, 184, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 32, 1, 13, 184, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionLQClientLink)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeLQClientLink {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+	if len(opt.LinkAddresses) != 2 {
+		t.Errorf("expected 2 link addresses, got %d", len(opt.LinkAddresses))
+	}
+
+	// test matching output for String()
+	fixtstr := "lq-client-link 2001:db8:1::,2001:db8:2::"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionLQClientLink{
+		LinkAddresses: []net.IP{net.ParseIP("2001:db8:1::"), net.ParseIP("2001:db8:2::")},
+	}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionLQClientLink: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionLQClientLink didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// try to decode fixture with an option length not a multiple of 16
+	fixtbyte[3] = 31
+	if _, err := DecodeOptions(fixtbyte); err == nil {
+		t.Error("expected error while decoding too short option")
+	} else if err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %s", err)
+	}
+}
+
+func TestOptionBootFileURL(t *testing.T) {
+	var opt *OptionBootFileURL
+
+	fixtbyte := []byte{0, 59, 0, 29, 104, 116, 116, 112, 58, 47, 47, 101, 120, 97, 109, 112, 108, 101, 46, 111, 114, 103, 47, 112, 120, 101, 108, 105, 110, 117, 120, 46, 48}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionBootFileURL)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeBootFileURL {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+
+	// check body length
+	fixtlen := uint16(29)
+	if opt.Len() != fixtlen {
+		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
+	}
+	fixturl := "http://example.org/pxelinux.0"
+	if opt.URL != fixturl {
+		t.Errorf("expected url %s, got %s", fixturl, opt.URL)
+	}
+
+	// test matching output for String()
+	fixtstr := fmt.Sprintf("boot-file-url %s", fixturl)
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// test if marshalled bytes match fixture
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionBootFileURL: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionBootFileURL didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionBootFileURL{
+		URL: fixturl,
+	}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionBootFileURL: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionBootFileURL didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+}
+
+func TestOptionBootFileParameters(t *testing.T) {
+	var opt *OptionBootFileParameters
+
+	fixtbyte := []byte{0, 60, 0, 18, 0, 3, 102, 111, 111, 0, 3, 98, 97, 114, 0, 6, 102, 111, 111, 98, 97, 114}
+
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionBootFileParameters)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeBootFileParameters {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+
+	// check body length
+	fixtlen := uint16(18)
+	if opt.Len() != fixtlen {
+		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
+	}
+	fixtparam := []string{"foo", "bar", "foobar"}
+	if !reflect.DeepEqual(fixtparam, opt.Parameters) {
+		t.Errorf("expected params %s, got %s", fixtparam, opt.Parameters)
+	}
+
+	// test matching output for String()
+	fixtstr := "boot-file-params foo bar foobar"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// test if marshalled bytes match fixture
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionBootFileParameters: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionBootFileParameters didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionBootFileParameters{
+		Parameters: fixtparam,
+	}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionBootFileParameters: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionBootFileParameters didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// test parameter length checking in parsing parameters
+	// mess up the length of the first parameter so decoding breaks
+	// and we end up with an empty set of parameters
+	fixtbyte[5] = 17
+	opt.decodeParameters(fixtbyte[4:])
+	if len(opt.Parameters) != 0 {
+		t.Errorf("option should have 0 parameters but has %d", len(opt.Parameters))
+	}
+}
+
+func TestOptionClientSystemArchitectureType(t *testing.T) {
+	var opt *OptionClientSystemArchitectureType
+
+	fixtbyte := []byte{0, 61, 0, 2, 0, 0}
+
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionClientSystemArchitectureType)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeClientSystemArchitectureType {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+
+	// check body length
+	fixtlen := uint16(2)
+	if opt.Len() != fixtlen {
+		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
+	}
+	if len(opt.Types) != 1 {
+		t.Errorf("expected type length 1, got %d", len(opt.Types))
+	}
+	fixttype := ArchitectureTypeIntelx86PC
+	if opt.Types[0] != fixttype {
+		t.Errorf("expected type %s, got %s", fixttype, opt.Types[0])
+	}
+
+	// test matching output for String()
+	fixtstr := "client-system-architecture-type [Intel x86PC (0)]"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// test if marshalled bytes match fixture
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionClientSystemArchitectureType: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionClientSystemArchitectureType didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionClientSystemArchitectureType{
+		Types: []ArchitectureType{fixttype},
+	}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionClientSystemArchitectureType: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionClientSystemArchitectureType didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+}
+
+func TestArchitectureTypeString(t *testing.T) {
+	tests := []struct {
+		in  ArchitectureType
+		out string
+	}{
+		{ArchitectureTypeIntelx86PC, "Intel x86PC (0)"},
+		{ArchitectureTypeNECPC98, "NEC/PC98 (1)"},
+		{ArchitectureTypeEFIItanium, "EFI Itanium (2)"},
+		{ArchitectureTypeDECAlpha, "DEC Alpha (3)"},
+		{ArchitectureTypeArcx86, "Arc x86 (4)"},
+		{ArchitectureTypeIntelLeanClient, "Intel Lean Client (5)"},
+		{ArchitectureTypeEFIIA32, "EFI IA32 (6)"},
+		{ArchitectureTypeEFIBC, "EFI BC (7)"},
+		{ArchitectureTypeEFIXscale, "EFI Xscale (8)"},
+		{ArchitectureTypeEFIx8664, "EFI x86-64 (9)"},
+		{255, "Unknown (255)"},
+	}
+
+	for _, test := range tests {
+		if strings.Compare(test.in.String(), test.out) != 0 {
+			t.Errorf("expected %s but got %s", test.out, test.in.String())
+		}
+	}
+}
+
+func TestOptionClientNetworkInterfaceIdentifier(t *testing.T) {
+	var opt *OptionClientNetworkInterfaceIdentifier
+
+	fixtbyte := []byte{0, 62, 0, 3, 1, 2, 1}
+
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionClientNetworkInterfaceIdentifier)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeClientNetworkInterfaceIdentifier {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+
+	// check body length
+	fixtlen := uint16(3)
+	if opt.Len() != fixtlen {
+		t.Errorf("expected length %d, got %d", fixtlen, opt.Len())
+	}
+	fixttype := InterfaceTypeUNDI
+	if opt.InterfaceType != fixttype {
+		t.Errorf("expected type %d, got %d", fixttype, opt.InterfaceType)
+	}
+	fixtrevmaj := uint8(2)
+	if opt.RevisionMajor != fixtrevmaj {
+		t.Errorf("expected major revision %d, got %d", fixtrevmaj, opt.RevisionMajor)
+	}
+	fixtrevmin := uint8(1)
+	if opt.RevisionMinor != fixtrevmin {
+		t.Errorf("expected minor revision %d, got %d", fixtrevmin, opt.RevisionMinor)
+	}
+
+	// test matching output for String()
+	fixtstr := "client-network-interface-identifier Universal Network Device Interface (UNDI) (1): 2.1"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// test if marshalled bytes match fixture
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionClientNetworkInterfaceIdentifier: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionClientNetworkInterfaceIdentifier didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionClientNetworkInterfaceIdentifier{
+		InterfaceType: fixttype,
+		RevisionMajor: fixtrevmaj,
+		RevisionMinor: fixtrevmin,
+	}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionClientNetworkInterfaceIdentifier: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionClientNetworkInterfaceIdentifier didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// try to decode fixture with too short option length
+	fixtbyte[3] = 2
+	// test decoding bytes to []Option
+	if _, err := DecodeOptions(fixtbyte); err == nil {
+		t.Error("expected error while decoding too short option")
+	} else {
+		fixterr := errOptionTooShort
+		if err != fixterr {
+			t.Errorf("expected option too short error, got %s", err)
+		}
+	}
+}
+
+func TestInterfaceTypeString(t *testing.T) {
+	tests := []struct {
+		in  InterfaceType
+		out string
+	}{
+		{InterfaceTypeUNDI, "Universal Network Device Interface (UNDI) (1)"},
+		{255, "Unknown (255)"},
+	}
+
+	for _, test := range tests {
+		if strings.Compare(test.in.String(), test.out) != 0 {
+			t.Errorf("expected %s but got %s", test.out, test.in.String())
+		}
+	}
+}
+
+func TestOptionDHCPv4Message(t *testing.T) {
+	var opt *OptionDHCPv4Message
+
+	v4 := &DHCPv4Message{Op: 1, HardwareType: 1, Xid: 42, Options: []DHCPv4Option{{Code: 53, Data: []byte{1}}}}
+	v4byte, _ := v4.Marshal()
+	fixtbyte := append([]byte{0, 87, 0, uint8(len(v4byte))}, v4byte...)
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionDHCPv4Message)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeDHCPv4Message {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+	if dec, err := opt.DHCPv4(); err != nil {
+		t.Errorf("could not decode DHCPv4 message: %s", err)
+	} else if dec.Xid != 42 {
+		t.Errorf("expected xid 42, got %d", dec.Xid)
+	}
+
+	// test matching output for String()
+	fixtstr := "dhcpv4-message DHCPv4 op 1 xid 0x0000002a chaddr  ciaddr 0.0.0.0 yiaddr 0.0.0.0"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// create same option and see if its marshal matches fixture
+	if opt, err := NewOptionDHCPv4Message(v4); err != nil {
+		t.Errorf("could not create OptionDHCPv4Message: %s", err)
+	} else if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionDHCPv4Message: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionDHCPv4Message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// an opaque message that isn't a valid DHCPv4 message is kept as-is
+	opt = &OptionDHCPv4Message{Message: []byte{1, 2, 3}}
+	fixtstr = "dhcpv4-message 010203"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+}
+
+func TestOptionDHCP4oDHCP6Server(t *testing.T) {
+	var opt *OptionDHCP4oDHCP6Server
+
+	fixtbyte := []byte{0, 88, 0, 16, 32, 1, 13, 184, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionDHCP4oDHCP6Server)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeDHCP4oDHCP6Server {
+		t.Errorf("unexpected type: %s", opt.Type())
+	}
+
+	// test matching output for String()
+	fixtstr := "dhcp4o6-server 2001:db8::1"
+	if fixtstr != opt.String() {
+		t.Errorf("unexpected String() output: %s", opt.String())
+	}
+
+	// create same struct and see if its marshal matches fixture
+	opt = &OptionDHCP4oDHCP6Server{
+		Servers: []net.IP{net.ParseIP("2001:db8::1")},
+	}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionDHCP4oDHCP6Server: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionDHCP4oDHCP6Server didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	// an empty server list is valid
+	if list, err := DecodeOptions([]byte{0, 88, 0, 0}); err != nil {
+		t.Errorf("could not decode empty option: %s", err)
+	} else if len(list[0].(*OptionDHCP4oDHCP6Server).Servers) != 0 {
+		t.Error("expected no servers")
+	}
+
+	// try to decode fixture with an option length not a multiple of 16
+	fixtbyte[3] = 15
+	if _, err := DecodeOptions(fixtbyte); err != errOptionTooShort {
+		t.Errorf("expected option too short error, got %v", err)
+	}
+}
+
+func TestOptionNextHop(t *testing.T) {
+	var opt *OptionNextHop
+
+	fixtbyte := []byte{0, 242, 0, 16, 253, 212, 71, 50, 21, 217, 234, 106, 0, 0, 0, 0, 0, 0, 16, 0}
+	// test decoding bytes to []Option
+	if list, err := DecodeOptions(fixtbyte); err != nil {
+		t.Errorf("could not decode fixture: %s", err)
+	} else if len(list) != 1 {
+		t.Errorf("expected exactly 1 option, got %d", len(list))
+	} else {
+		opt = list[0].(*OptionNextHop)
+	}
+
+	// check contents of Option
+	if opt.Type() != OptionTypeNextHop {
 		t.Errorf("unexpected type: %s", opt.Type())
 	}
 	fixtaddr := net.ParseIP("fdd4:4732:15d9:ea6a::1000")
@@ -1265,14 +2286,13 @@ func TestOptionNextHop(t *testing.T) {
 	// add route prefix option
 	opt.SetOption(&OptionRoutePrefix{
 		RouteLifetime: 3600,
-		Metric:        10,
 		Preference:    RoutePreferenceHigh,
 		Prefix:        net.ParseIP("fdd4:4732:15d9:ea6a::"),
 		PrefixLength:  64,
 	})
 
 	// test matching output for String()
-	fixtstr = "next-hop fdd4:4732:15d9:ea6a::1000 [route-prefix fdd4:4732:15d9:ea6a::/64]"
+	fixtstr = "next-hop fdd4:4732:15d9:ea6a::1000 [route-prefix fdd4:4732:15d9:ea6a::/64 lifetime:1h0m0s preference:High (1)]"
 	if fixtstr != opt.String() {
 		t.Errorf("unexpected String() output: %s", opt.String())
 	}
@@ -1350,7 +2370,7 @@ func TestOptionRoutePrefix(t *testing.T) {
 	}
 
 	// test matching output for String()
-	fixtstr := fmt.Sprintf("route-prefix %s/%d [status-code Success (0): foobar]", fixtprefix, fixtpl)
+	fixtstr := fmt.Sprintf("route-prefix %s/%d lifetime:1h0m0s preference:Low (3) [status-code Success (0): foobar]", fixtprefix, fixtpl)
 	if fixtstr != opt.String() {
 		t.Errorf("unexpected String() output: %s", opt.String())
 	}
@@ -1367,7 +2387,6 @@ func TestOptionRoutePrefix(t *testing.T) {
 		RouteLifetime: fixtlt,
 		PrefixLength:  fixtpl,
 		Preference:    fixtpref,
-		Metric:        10,
 		Prefix:        fixtprefix,
 	}
 	opt.AddOption(&OptionStatusCode{
@@ -1430,3 +2449,202 @@ func TestRoutePreferenceString(t *testing.T) {
 		}
 	}
 }
+
+func TestNewOptionNextHop(t *testing.T) {
+	opt, err := NewOptionNextHop(netip.MustParseAddr("fdd4:4732:15d9:ea6a::1000"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fixtbyte := []byte{0, 242, 0, 16, 253, 212, 71, 50, 21, 217, 234, 106, 0, 0, 0, 0, 0, 0, 16, 0}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionNextHop: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionNextHop didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	if addr, err := opt.Addr(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if addr != netip.MustParseAddr("fdd4:4732:15d9:ea6a::1000") {
+		t.Errorf("unexpected address %s", addr)
+	}
+
+	// IPv4 and IPv4-mapped addresses are not valid next hops
+	for _, addr := range []string{"192.0.2.1", "::ffff:192.0.2.1"} {
+		if _, err := NewOptionNextHop(netip.MustParseAddr(addr)); err != errInvalidIPv6Address {
+			t.Errorf("expected invalid address error for %s, got %v", addr, err)
+		}
+	}
+
+	// marshalling an address of the wrong length should fail
+	for _, addr := range []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.1").To4(), nil} {
+		opt = &OptionNextHop{Address: addr}
+		if _, err := opt.Marshal(); err != errInvalidIPv6Address {
+			t.Errorf("expected invalid address error for %v, got %v", addr, err)
+		}
+	}
+}
+
+func TestNewOptionRoutePrefix(t *testing.T) {
+	// host bits should be cleared
+	opt, err := NewOptionRoutePrefix(netip.MustParsePrefix("fdd4:4732:15d9:ea6a::1/64"), 3600, RoutePreferenceLow)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fixtbyte := []byte{0, 243, 0, 22, 0, 0, 14, 16, 64, 24, 253, 212, 71, 50, 21, 217, 234, 106, 0, 0, 0, 0, 0, 0, 0, 0}
+	if mshByte, err := opt.Marshal(); err != nil {
+		t.Errorf("error marshalling OptionRoutePrefix: %s", err)
+	} else if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("marshalled OptionRoutePrefix didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+	}
+
+	fixtprefix := netip.MustParsePrefix("fdd4:4732:15d9:ea6a::/64")
+	if prefix, err := opt.NetPrefix(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if prefix != fixtprefix {
+		t.Errorf("expected prefix %s, got %s", fixtprefix, prefix)
+	}
+
+	// invalid prefixes and preferences
+	if _, err := NewOptionRoutePrefix(netip.MustParsePrefix("192.0.2.0/24"), 0, RoutePreferenceMedium); err != errInvalidIPv6Address {
+		t.Errorf("expected invalid address error, got %v", err)
+	}
+	if _, err := NewOptionRoutePrefix(netip.Prefix{}, 0, RoutePreferenceMedium); err != errInvalidIPv6Address {
+		t.Errorf("expected invalid address error, got %v", err)
+	}
+	if _, err := NewOptionRoutePrefix(fixtprefix, 0, 2); err != errInvalidRoutePreference {
+		t.Errorf("expected invalid route preference error, got %v", err)
+	}
+
+	// marshalling invalid fields should fail
+	opt.PrefixLength = 129
+	if _, err := opt.Marshal(); err != errInvalidPrefixLength {
+		t.Errorf("expected invalid prefix length error, got %v", err)
+	}
+	if _, err := opt.NetPrefix(); err != errInvalidPrefixLength {
+		t.Errorf("expected invalid prefix length error, got %v", err)
+	}
+	opt.PrefixLength = 64
+	opt.Prefix = net.ParseIP("192.0.2.0")
+	if _, err := opt.Marshal(); err != errInvalidIPv6Address {
+		t.Errorf("expected invalid address error, got %v", err)
+	}
+
+	// decoding an invalid prefix length should fail
+	fixtbyte[8] = 129
+	if _, err := DecodeOptions(fixtbyte); err != errInvalidPrefixLength {
+		t.Errorf("expected invalid prefix length error, got %v", err)
+	}
+}
+
+func TestOptionCloneEqual(t *testing.T) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	iana := &OptionIANA{IAID: 1}
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1")})
+	iapd := &OptionIAPD{IAID: 2}
+	iapd.AddOption(&OptionIAPrefix{Prefix: net.ParseIP("2001:db8::"), PrefixLength: 48})
+	nexthop := &OptionNextHop{Address: net.ParseIP("fe80::1")}
+	nexthop.AddOption(&OptionRoutePrefix{Prefix: net.ParseIP("2001:db8::"), PrefixLength: 48})
+
+	opts := Options{
+		&OptionClientID{DUID: &DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac}},
+		&OptionServerID{DUID: &DUIDEN{EnterpriseNumber: 32473, ID: []byte{1, 2, 3}}},
+		iana,
+		&OptionOptionRequest{Options: []OptionType{OptionTypeDNSServer}},
+		&OptionElapsedTime{ElapsedTime: time.Second},
+		&OptionStatusCode{Code: StatusCodeNoBinding, Message: "no binding"},
+		&OptionRapidCommit{},
+		&OptionUserClass{classDataContainer{ClassData: []string{"foo"}}},
+		&OptionVendorClass{classDataContainer{ClassData: []string{"bar"}}, 32473},
+		&OptionDNSServer{Servers: []net.IP{net.ParseIP("2001:db8::53")}},
+		&OptionDNSSearchList{DomainNames: []string{"example.com"}},
+		iapd,
+		&OptionPDExclude{PrefixLength: 64, SubnetID: []byte{1}},
+		&OptionRemoteID{EnterpriseNumber: 32473, RemoteID: []byte{1, 2, 3}},
+		&OptionClientFQDN{Flags: ClientFQDNFlagS, DomainName: "host.example.com."},
+		&OptionLQQuery{QueryType: QueryTypeByAddress, LinkAddress: net.ParseIP("2001:db8::1")},
+		&OptionClientData{},
+		&OptionCLTTime{Time: time.Minute},
+		&OptionLQRelayData{PeerAddress: net.ParseIP("2001:db8::1"), RelayMessage: []byte{1, 2, 3}},
+		&OptionLQClientLink{LinkAddresses: []net.IP{net.ParseIP("2001:db8::1")}},
+		&OptionRelayID{DUID: &DUIDOpaque{Data: []byte{0, 99, 1}}},
+		&OptionBootFileURL{URL: "tftp://[2001:db8::1]/boot"},
+		&OptionBootFileParameters{Parameters: []string{"foo"}},
+		&OptionClientSystemArchitectureType{Types: []ArchitectureType{ArchitectureTypeEFIx8664}},
+		&OptionClientNetworkInterfaceIdentifier{InterfaceType: InterfaceTypeUNDI, RevisionMajor: 2, RevisionMinor: 1},
+		&OptionDHCPv4Message{Message: []byte{1, 2, 3}},
+		&OptionDHCP4oDHCP6Server{Servers: []net.IP{net.ParseIP("2001:db8::1")}},
+		nexthop,
+		&OptionUnknown{Code: 300, Data: []byte{1, 2, 3}},
+	}
+
+	for _, opt := range opts {
+		clone := CloneOption(opt)
+		if reflect.TypeOf(clone) != reflect.TypeOf(opt) {
+			t.Errorf("expected clone of type %T, got %T", opt, clone)
+		}
+		if !EqualOptions(opt, clone) || !EqualOptions(clone, opt) {
+			t.Errorf("expected clone of %s to be equal", opt.Type())
+		}
+		if !reflect.DeepEqual(opt, clone) {
+			t.Errorf("expected clone of %s to be deep equal", opt.Type())
+		}
+		if EqualOptions(opt, nil) {
+			t.Errorf("expected %s not to be equal to nil", opt.Type())
+		}
+		if EqualOptions(opt, &OptionElapsedTime{}) && opt.Type() != OptionTypeElapsedTime {
+			t.Errorf("expected %s not to be equal to other option type", opt.Type())
+		}
+	}
+
+	// clones should not share memory with the original
+	ianaClone := iana.Clone().(*OptionIANA)
+	ianaClone.HasOption(OptionTypeIAAddress).(*OptionIAAddress).Address[15] = 2
+	ianaClone.AddOption(&OptionRapidCommit{})
+	if addr := iana.HasOption(OptionTypeIAAddress).(*OptionIAAddress).Address; !addr.Equal(net.ParseIP("2001:db8::1")) {
+		t.Errorf("modifying clone changed original address to %s", addr)
+	}
+	if len(iana.Options()) != 1 {
+		t.Errorf("modifying clone changed original options")
+	}
+	if EqualOptions(iana, ianaClone) {
+		t.Error("expected modified clone not to be equal")
+	}
+
+	clientID := opts[0].(*OptionClientID)
+	clientIDClone := clientID.Clone().(*OptionClientID)
+	clientIDClone.DUID.(*DUIDLL).LinkLayerAddress[0] = 0
+	if clientID.DUID.(*DUIDLL).LinkLayerAddress[0] != 170 {
+		t.Error("modifying clone changed original DUID")
+	}
+
+	// invalid options are still equal to themselves and their clones
+	invalid := &OptionRoutePrefix{Prefix: net.ParseIP("2001:db8::"), PrefixLength: 32, Preference: 2}
+	if !EqualOptions(invalid, invalid) || !EqualOptions(invalid, CloneOption(invalid)) {
+		t.Errorf("expected %s to equal itself", invalid)
+	}
+	if EqualOptions(invalid, &OptionRoutePrefix{Prefix: net.ParseIP("2001:db8::"), PrefixLength: 48, Preference: 2}) {
+		t.Errorf("expected %s not to equal option with other prefix length", invalid)
+	}
+
+	// options implemented elsewhere don't need Clone or Equal methods
+	var foreign Option = fixtForeignOption{1, 2, 3}
+	if c := CloneOption(foreign); !EqualOptions(foreign, c) {
+		t.Errorf("expected clone of foreign option to be equal, got %s", c)
+	}
+	if !EqualOptions(foreign, &OptionUnknown{Code: 300, Data: []byte{1, 2, 3}}) {
+		t.Error("expected foreign option to equal unknown option with same bytes")
+	}
+}
+
+// fixtForeignOption implements Option the way an option defined outside this
+// package would, without Clone method
+type fixtForeignOption []byte
+
+func (o fixtForeignOption) String() string   { return "foreign option" }
+func (o fixtForeignOption) Len() uint16      { return uint16(len(o)) }
+func (o fixtForeignOption) Type() OptionType { return 300 }
+func (o fixtForeignOption) Marshal() ([]byte, error) {
+	return append([]byte{1, 44, 0, byte(len(o))}, o...), nil
+}
@@ -0,0 +1,669 @@
+package dhcpv6
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/netip"
+	"time"
+)
+
+var (
+	errPcapInvalidMagic     = errors.New("not a pcap or pcapng file")
+	errPcapInvalidBlock     = errors.New("invalid pcapng block")
+	errPcapUnknownInterface = errors.New("packet for unknown pcapng interface")
+	errPcapPacketTooLong    = errors.New("captured packet too long")
+	errPcapInvalidAddress   = errors.New("packet addresses are not valid IPv6 addresses")
+
+	// AllDHCPRelayAgentsAndServers is the link-scoped multicast address
+	// clients send their messages to, as described at
+	// https://tools.ietf.org/html/rfc8415#section-7.1
+	AllDHCPRelayAgentsAndServers = netip.MustParseAddr("ff02::1:2")
+	// AllDHCPServers is the site-scoped multicast address relay agents can
+	// send their messages to
+	AllDHCPServers = netip.MustParseAddr("ff05::1:3")
+)
+
+// magic numbers of pcap files as described at
+// https://datatracker.ietf.org/doc/draft-ietf-opsawg-pcap/ and of pcapng
+// blocks as described at https://datatracker.ietf.org/doc/draft-ietf-opsawg-pcapng/
+const (
+	pcapMagicMicroseconds uint32 = 0xa1b2c3d4
+	pcapMagicNanoseconds  uint32 = 0xa1b23c4d
+
+	pcapngBlockSectionHeader        uint32 = 0x0a0d0d0a
+	pcapngBlockInterface            uint32 = 0x00000001
+	pcapngBlockSimplePacket         uint32 = 0x00000003
+	pcapngBlockEnhancedPacket       uint32 = 0x00000006
+	pcapngByteOrderMagic            uint32 = 0x1a2b3c4d
+	pcapngOptionEnd                 uint16 = 0
+	pcapngOptionTimestampResolution uint16 = 9
+
+	// LINKTYPE_ETHERNET, as described at
+	// https://www.tcpdump.org/linktypes.html
+	pcapLinkTypeEthernet uint16 = 1
+
+	// packets larger than this are not expected in any capture
+	pcapMaxPacketLen = 256 * 1024
+
+	// hop limit of the IPv6 header written by PcapWriter
+	pcapHopLimit = 64
+)
+
+// ethertypes, IPv6 next header values and UDP ports involved in DHCPv6 traffic
+const (
+	etherTypeIPv6    uint16 = 0x86dd
+	etherTypeVLAN    uint16 = 0x8100
+	etherTypeQinQ    uint16 = 0x88a8
+	etherTypeQinQOld uint16 = 0x9100
+
+	ipv6HeaderLen        = 40
+	ipv6NextHopByHop     = 0
+	ipv6NextRouting      = 43
+	ipv6NextFragment     = 44
+	ipv6NextDestinations = 60
+	ipv6NextUDP          = 17
+
+	udpHeaderLen   = 8
+	etherHeaderLen = 14
+	// ClientPort and ServerPort are the UDP ports DHCPv6 clients and servers
+	// (and relay agents) listen on, as described at
+	// https://tools.ietf.org/html/rfc8415#section-7.2
+	ClientPort uint16 = 546
+	ServerPort uint16 = 547
+)
+
+// Packet is a DHCPv6 message as it was captured, along with the time it was
+// captured and the addresses it was sent from and to
+type Packet struct {
+	Timestamp time.Time
+	Src       netip.AddrPort
+	Dst       netip.AddrPort
+	// Data is the UDP payload carrying the message. When writing a Packet it
+	// is only used if Message is nil, allowing to write malformed messages
+	Data    []byte
+	Message *Message
+}
+
+// pcapInterface describes an interface of a pcapng section packets are
+// captured on
+type pcapInterface struct {
+	linkType uint16
+	// timestamp units per second
+	resolution uint64
+}
+
+// PcapReader reads DHCPv6 messages from pcap or pcapng capture files. Only
+// UDP traffic from or to the DHCPv6 ports carried in IPv6 over Ethernet,
+// optionally VLAN tagged, is considered, all other packets are skipped
+type PcapReader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	ng    bool
+	// link type and timestamp resolution of pcap files
+	iface pcapInterface
+	// interfaces of the current section of pcapng files
+	ifaces []pcapInterface
+}
+
+// NewPcapReader returns a PcapReader reading from r, after reading the file
+// header to find out what kind of capture file r is
+func NewPcapReader(r io.Reader) (*PcapReader, error) {
+	p := &PcapReader{r: r}
+
+	h := make([]byte, 4)
+	if _, err := io.ReadFull(r, h); err != nil {
+		return nil, err
+	}
+
+	// the magic of a pcapng section header block reads the same in both byte
+	// orders, the byte order follows from the block itself
+	if binary.BigEndian.Uint32(h) == pcapngBlockSectionHeader {
+		p.ng = true
+		if err := p.readSectionHeader(); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	switch {
+	case binary.BigEndian.Uint32(h) == pcapMagicMicroseconds || binary.BigEndian.Uint32(h) == pcapMagicNanoseconds:
+		p.order = binary.BigEndian
+	case binary.LittleEndian.Uint32(h) == pcapMagicMicroseconds || binary.LittleEndian.Uint32(h) == pcapMagicNanoseconds:
+		p.order = binary.LittleEndian
+	default:
+		return nil, errPcapInvalidMagic
+	}
+
+	// read the remainder of the file header: version, reserved fields, snap
+	// length and link type
+	b := make([]byte, 20)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	p.iface.linkType = uint16(p.order.Uint32(b[16:20]))
+	p.iface.resolution = 1e6
+	if p.order.Uint32(h) == pcapMagicNanoseconds {
+		p.iface.resolution = 1e9
+	}
+
+	return p, nil
+}
+
+// ReadPacket reads packets from the capture file until it finds one carrying
+// a DHCPv6 message and returns it. It returns io.EOF when the capture file
+// ends cleanly in between packets. When a DHCPv6 packet is found but its
+// payload could not be decoded, the returned Packet has no Message and error
+// describes why; reading can continue with the next packet
+func (p *PcapReader) ReadPacket() (*Packet, error) {
+	for {
+		var (
+			ts    time.Time
+			iface pcapInterface
+			data  []byte
+			err   error
+		)
+		if p.ng {
+			ts, iface, data, err = p.readBlock()
+		} else {
+			ts, iface, data, err = p.readRecord()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if data == nil || iface.linkType != pcapLinkTypeEthernet {
+			continue
+		}
+
+		src, dst, payload, ok := decodeFrame(data)
+		if !ok {
+			continue
+		}
+
+		pkt := &Packet{
+			Timestamp: ts,
+			Src:       src,
+			Dst:       dst,
+			Data:      payload,
+		}
+		if pkt.Message, err = DecodeMessage(payload); err != nil {
+			return pkt, fmt.Errorf("could not decode message: %s", err)
+		}
+
+		return pkt, nil
+	}
+}
+
+// helper function to read the next packet record of a pcap file
+func (p *PcapReader) readRecord() (time.Time, pcapInterface, []byte, error) {
+	h := make([]byte, 16)
+	if _, err := io.ReadFull(p.r, h); err != nil {
+		return time.Time{}, p.iface, nil, err
+	}
+
+	capLen := p.order.Uint32(h[8:12])
+	if capLen > pcapMaxPacketLen {
+		return time.Time{}, p.iface, nil, errPcapPacketTooLong
+	}
+	data := make([]byte, capLen)
+	if _, err := io.ReadFull(p.r, data); err != nil {
+		return time.Time{}, p.iface, nil, unexpectedEOF(err)
+	}
+
+	// packets not captured in full cannot hold a complete message
+	if capLen < p.order.Uint32(h[12:16]) {
+		data = nil
+	}
+
+	sec := uint64(p.order.Uint32(h[0:4]))
+	frac := uint64(p.order.Uint32(h[4:8]))
+
+	return pcapTime(sec*p.iface.resolution+frac, p.iface.resolution), p.iface, data, nil
+}
+
+// helper function to read the next block of a pcapng file and return the
+// packet in it, if any
+func (p *PcapReader) readBlock() (time.Time, pcapInterface, []byte, error) {
+	h := make([]byte, 4)
+	if _, err := io.ReadFull(p.r, h); err != nil {
+		return time.Time{}, pcapInterface{}, nil, err
+	}
+
+	// every section starts with a section header and might change byte order
+	// and interfaces
+	if binary.BigEndian.Uint32(h) == pcapngBlockSectionHeader {
+		return time.Time{}, pcapInterface{}, nil, p.readSectionHeader()
+	}
+
+	blockType := p.order.Uint32(h)
+	body, err := p.readBlockBody()
+	if err != nil {
+		return time.Time{}, pcapInterface{}, nil, err
+	}
+
+	switch blockType {
+	case pcapngBlockInterface:
+		if len(body) < 8 {
+			return time.Time{}, pcapInterface{}, nil, errPcapInvalidBlock
+		}
+		iface := pcapInterface{
+			linkType:   p.order.Uint16(body[0:2]),
+			resolution: pcapngResolution(p.order, body[8:]),
+		}
+		p.ifaces = append(p.ifaces, iface)
+
+	case pcapngBlockEnhancedPacket:
+		if len(body) < 20 {
+			return time.Time{}, pcapInterface{}, nil, errPcapInvalidBlock
+		}
+		id := p.order.Uint32(body[0:4])
+		if int(id) >= len(p.ifaces) {
+			return time.Time{}, pcapInterface{}, nil, errPcapUnknownInterface
+		}
+		iface := p.ifaces[id]
+		ts := uint64(p.order.Uint32(body[4:8]))<<32 | uint64(p.order.Uint32(body[8:12]))
+		capLen := p.order.Uint32(body[12:16])
+		if uint32(len(body)-20) < capLen {
+			return time.Time{}, pcapInterface{}, nil, errPcapInvalidBlock
+		}
+		var data []byte
+		if capLen >= p.order.Uint32(body[16:20]) {
+			data = body[20 : 20+capLen]
+		}
+		return pcapTime(ts, iface.resolution), iface, data, nil
+
+	case pcapngBlockSimplePacket:
+		// simple packet blocks have no timestamp and are always captured on
+		// the first interface
+		if len(body) < 4 || len(p.ifaces) == 0 {
+			return time.Time{}, pcapInterface{}, nil, errPcapInvalidBlock
+		}
+		origLen := p.order.Uint32(body[0:4])
+		if uint32(len(body)-4) < origLen {
+			// captured partially, so padding can't be told apart from data
+			return time.Time{}, p.ifaces[0], nil, nil
+		}
+		return time.Time{}, p.ifaces[0], body[4 : 4+origLen], nil
+	}
+
+	// other blocks, like name resolution and statistics, are skipped
+	return time.Time{}, pcapInterface{}, nil, nil
+}
+
+// helper function to read a section header block, after its block type was
+// read already
+func (p *PcapReader) readSectionHeader() error {
+	// block length and byte order magic tell the byte order of the section
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(p.r, b); err != nil {
+		return unexpectedEOF(err)
+	}
+	switch {
+	case binary.BigEndian.Uint32(b[4:8]) == pcapngByteOrderMagic:
+		p.order = binary.BigEndian
+	case binary.LittleEndian.Uint32(b[4:8]) == pcapngByteOrderMagic:
+		p.order = binary.LittleEndian
+	default:
+		return errPcapInvalidMagic
+	}
+
+	blockLen := p.order.Uint32(b[0:4])
+	if blockLen < 28 || blockLen%4 != 0 || blockLen > pcapMaxPacketLen {
+		return errPcapInvalidBlock
+	}
+	// skip version, section length and options up to the trailing length
+	if _, err := io.CopyN(io.Discard, p.r, int64(blockLen-12)); err != nil {
+		return unexpectedEOF(err)
+	}
+	p.ifaces = nil
+
+	return nil
+}
+
+// helper function to read the length, body and trailing length of a pcapng
+// block, after its block type was read already
+func (p *PcapReader) readBlockBody() ([]byte, error) {
+	h := make([]byte, 4)
+	if _, err := io.ReadFull(p.r, h); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	blockLen := p.order.Uint32(h)
+	if blockLen < 12 || blockLen%4 != 0 || blockLen > pcapMaxPacketLen {
+		return nil, errPcapInvalidBlock
+	}
+	b := make([]byte, blockLen-8)
+	if _, err := io.ReadFull(p.r, b); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if p.order.Uint32(b[len(b)-4:]) != blockLen {
+		return nil, errPcapInvalidBlock
+	}
+
+	return b[:len(b)-4], nil
+}
+
+// helper function returning the timestamp units per second of an interface
+// from the options of its interface description block. As described in the
+// pcapng draft, if_tsresol defaults to microseconds
+func pcapngResolution(order binary.ByteOrder, opts []byte) uint64 {
+	for len(opts) >= 4 {
+		code := order.Uint16(opts[0:2])
+		l := int(order.Uint16(opts[2:4]))
+		if code == pcapngOptionEnd || len(opts) < 4+l {
+			break
+		}
+
+		if code == pcapngOptionTimestampResolution && l == 1 {
+			v := opts[4]
+			// the most significant bit tells whether the resolution is a
+			// negative power of 2 or of 10
+			if v&0x80 != 0 {
+				if v&0x7f < 64 {
+					return 1 << (v & 0x7f)
+				}
+			} else if v <= 19 {
+				r := uint64(1)
+				for i := uint8(0); i < v; i++ {
+					r *= 10
+				}
+				return r
+			}
+		}
+
+		// option values are padded to 4 bytes
+		opts = opts[4+(l+3)&^3:]
+	}
+
+	return 1e6
+}
+
+// helper function converting a timestamp in given units per second to time
+func pcapTime(ts, resolution uint64) time.Time {
+	hi, lo := bits.Mul64(ts%resolution, 1e9)
+	nsec, _ := bits.Div64(hi, lo, resolution)
+
+	return time.Unix(int64(ts/resolution), int64(nsec)).UTC()
+}
+
+// helper function to decode an Ethernet frame and return the addresses and
+// payload of the DHCPv6 traffic it carries, if any
+func decodeFrame(b []byte) (netip.AddrPort, netip.AddrPort, []byte, bool) {
+	var src, dst netip.AddrPort
+	if len(b) < etherHeaderLen {
+		return src, dst, nil, false
+	}
+
+	// skip any VLAN tags
+	etherType := binary.BigEndian.Uint16(b[12:14])
+	b = b[etherHeaderLen:]
+	for etherType == etherTypeVLAN || etherType == etherTypeQinQ || etherType == etherTypeQinQOld {
+		if len(b) < 4 {
+			return src, dst, nil, false
+		}
+		etherType = binary.BigEndian.Uint16(b[2:4])
+		b = b[4:]
+	}
+	if etherType != etherTypeIPv6 || len(b) < ipv6HeaderLen || b[0]>>4 != 6 {
+		return src, dst, nil, false
+	}
+
+	payloadLen := int(binary.BigEndian.Uint16(b[4:6]))
+	next := b[6]
+	srcAddr := netip.AddrFrom16([16]byte(b[8:24]))
+	dstAddr := netip.AddrFrom16([16]byte(b[24:40]))
+	b = b[ipv6HeaderLen:]
+	if len(b) < payloadLen {
+		return src, dst, nil, false
+	}
+	b = b[:payloadLen]
+
+	// skip extension headers, fragmented packets are not reassembled
+	for next != ipv6NextUDP {
+		if len(b) < 8 {
+			return src, dst, nil, false
+		}
+		switch next {
+		case ipv6NextHopByHop, ipv6NextRouting, ipv6NextDestinations:
+			l := (int(b[1]) + 1) * 8
+			if len(b) < l {
+				return src, dst, nil, false
+			}
+			next = b[0]
+			b = b[l:]
+		case ipv6NextFragment:
+			// only unfragmented packets with a fragment header are complete
+			if binary.BigEndian.Uint16(b[2:4]) != 0 {
+				return src, dst, nil, false
+			}
+			next = b[0]
+			b = b[8:]
+		default:
+			return src, dst, nil, false
+		}
+	}
+
+	if len(b) < udpHeaderLen {
+		return src, dst, nil, false
+	}
+	srcPort := binary.BigEndian.Uint16(b[0:2])
+	dstPort := binary.BigEndian.Uint16(b[2:4])
+	udpLen := int(binary.BigEndian.Uint16(b[4:6]))
+	if udpLen < udpHeaderLen || udpLen > len(b) {
+		return src, dst, nil, false
+	}
+	if !isDHCPv6Port(srcPort) && !isDHCPv6Port(dstPort) {
+		return src, dst, nil, false
+	}
+
+	src = netip.AddrPortFrom(srcAddr, srcPort)
+	dst = netip.AddrPortFrom(dstAddr, dstPort)
+
+	return src, dst, b[udpHeaderLen:udpLen], true
+}
+
+// PcapWriter writes DHCPv6 messages to a pcap file, wrapping them in
+// synthetic Ethernet, IPv6 and UDP headers so the file can be inspected with
+// tools like Wireshark or read back with PcapReader
+type PcapWriter struct {
+	w io.Writer
+	// ClientAddr is the address WriteMessage sends client messages from and
+	// server messages to
+	ClientAddr netip.Addr
+	// ServerAddr is the address WriteMessage sends server messages from
+	ServerAddr netip.Addr
+	// ClientDst is the address WriteMessage sends client messages to, which
+	// is AllDHCPRelayAgentsAndServers by default. Set it to ServerAddr to
+	// write unicast client messages
+	ClientDst netip.Addr
+}
+
+// NewPcapWriter returns a PcapWriter writing to w, after writing the pcap file
+// header. Its addresses default to link-local addresses of the client and
+// server and client messages are sent to AllDHCPRelayAgentsAndServers
+func NewPcapWriter(w io.Writer) (*PcapWriter, error) {
+	// write a little endian file header with nanosecond timestamps
+	b := make([]byte, 24)
+	binary.LittleEndian.PutUint32(b[0:4], pcapMagicNanoseconds)
+	binary.LittleEndian.PutUint16(b[4:6], 2)
+	binary.LittleEndian.PutUint16(b[6:8], 4)
+	binary.LittleEndian.PutUint32(b[16:20], pcapMaxPacketLen)
+	binary.LittleEndian.PutUint32(b[20:24], uint32(pcapLinkTypeEthernet))
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+
+	return &PcapWriter{
+		w:          w,
+		ClientAddr: netip.MustParseAddr("fe80::a8bb:ccff:fedd:eeff"),
+		ServerAddr: netip.MustParseAddr("fe80::211:22ff:fe33:4455"),
+		ClientDst:  AllDHCPRelayAgentsAndServers,
+	}, nil
+}
+
+// WriteMessage writes given Message as captured at ts, sent between the
+// default addresses for its type as described at WritePacket
+func (p *PcapWriter) WriteMessage(m *Message, ts time.Time) error {
+	return p.WritePacket(&Packet{
+		Timestamp: ts,
+		Message:   m,
+	})
+}
+
+// WritePacket writes given Packet with its own timestamp. Its Message is
+// marshalled, or its Data is written as is if it has no Message. If the
+// Packet has no addresses, client messages are written as sent from ClientAddr
+// to ClientDst, relayed messages as sent from ClientAddr to ServerAddr and
+// server messages as sent from ServerAddr to ClientAddr
+func (p *PcapWriter) WritePacket(pkt *Packet) error {
+	payload := pkt.Data
+	if pkt.Message != nil {
+		var err error
+		if payload, err = pkt.Message.Marshal(); err != nil {
+			return fmt.Errorf("could not marshal message: %s", err)
+		}
+	}
+
+	src, dst := pkt.Src, pkt.Dst
+	if !src.IsValid() && !dst.IsValid() && len(payload) > 0 {
+		src, dst = p.addresses(MessageType(payload[0]))
+	}
+	frame, err := encodeFrame(src, dst, payload)
+	if err != nil {
+		return err
+	}
+
+	// prepend record header and write packet in one go
+	ts := pkt.Timestamp
+	b := make([]byte, 16, 16+len(frame))
+	binary.LittleEndian.PutUint32(b[0:4], uint32(ts.Unix()))
+	binary.LittleEndian.PutUint32(b[4:8], uint32(ts.Nanosecond()))
+	binary.LittleEndian.PutUint32(b[8:12], uint32(len(frame)))
+	binary.LittleEndian.PutUint32(b[12:16], uint32(len(frame)))
+	b = append(b, frame...)
+	_, err = p.w.Write(b)
+
+	return err
+}
+
+// helper function returning the addresses messages of type t are sent from
+// and to by default
+func (p *PcapWriter) addresses(t MessageType) (netip.AddrPort, netip.AddrPort) {
+	switch t {
+	case MessageTypeSolicit, MessageTypeRequest, MessageTypeConfirm,
+		MessageTypeRenew, MessageTypeRebind, MessageTypeRelease,
+		MessageTypeDecline, MessageTypeInformationRequest,
+		MessageTypeLeasequery, MessageTypeDHCPv4Query:
+		return netip.AddrPortFrom(p.ClientAddr, ClientPort), netip.AddrPortFrom(p.ClientDst, ServerPort)
+	case MessageTypeRelayForward:
+		return netip.AddrPortFrom(p.ClientAddr, ServerPort), netip.AddrPortFrom(p.ServerAddr, ServerPort)
+	case MessageTypeRelayReply:
+		return netip.AddrPortFrom(p.ServerAddr, ServerPort), netip.AddrPortFrom(p.ClientAddr, ServerPort)
+	default:
+		return netip.AddrPortFrom(p.ServerAddr, ServerPort), netip.AddrPortFrom(p.ClientAddr, ClientPort)
+	}
+}
+
+// helper function to wrap payload in Ethernet, IPv6 and UDP headers for given
+// addresses
+func encodeFrame(src, dst netip.AddrPort, payload []byte) ([]byte, error) {
+	if !src.Addr().Is6() || !dst.Addr().Is6() || src.Addr().Is4In6() || dst.Addr().Is4In6() {
+		return nil, errPcapInvalidAddress
+	}
+	udpLen := udpHeaderLen + len(payload)
+	if udpLen > 0xffff {
+		return nil, errMessageTooLong
+	}
+
+	b := make([]byte, etherHeaderLen+ipv6HeaderLen+udpLen)
+
+	// Ethernet header
+	dstMAC, srcMAC := macForAddr(dst.Addr()), macForAddr(src.Addr())
+	copy(b[0:6], dstMAC[:])
+	copy(b[6:12], srcMAC[:])
+	binary.BigEndian.PutUint16(b[12:14], etherTypeIPv6)
+
+	// IPv6 header
+	ip := b[etherHeaderLen:]
+	ip[0] = 0x60
+	binary.BigEndian.PutUint16(ip[4:6], uint16(udpLen))
+	ip[6] = ipv6NextUDP
+	ip[7] = pcapHopLimit
+	srcAddr, dstAddr := src.Addr().As16(), dst.Addr().As16()
+	copy(ip[8:24], srcAddr[:])
+	copy(ip[24:40], dstAddr[:])
+
+	// UDP header and payload
+	udp := ip[ipv6HeaderLen:]
+	binary.BigEndian.PutUint16(udp[0:2], src.Port())
+	binary.BigEndian.PutUint16(udp[2:4], dst.Port())
+	binary.BigEndian.PutUint16(udp[4:6], uint16(udpLen))
+	copy(udp[udpHeaderLen:], payload)
+	binary.BigEndian.PutUint16(udp[6:8], udpChecksum(srcAddr, dstAddr, udp))
+
+	return b, nil
+}
+
+// helper function to calculate the UDP checksum over the IPv6 pseudo header
+// and given UDP header and payload, as described at
+// https://tools.ietf.org/html/rfc8200#section-8.1
+func udpChecksum(src, dst [16]byte, udp []byte) uint16 {
+	var sum uint32
+	add := func(b []byte) {
+		for i := 0; i+1 < len(b); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(b[i : i+2]))
+		}
+		if len(b)%2 == 1 {
+			sum += uint32(b[len(b)-1]) << 8
+		}
+	}
+
+	add(src[:])
+	add(dst[:])
+	sum += uint32(len(udp))
+	sum += ipv6NextUDP
+	add(udp)
+	for sum > 0xffff {
+		sum = sum&0xffff + sum>>16
+	}
+
+	// a checksum of 0 means no checksum was calculated, which is not allowed
+	// for UDP over IPv6
+	if c := ^uint16(sum); c != 0 {
+		return c
+	}
+	return 0xffff
+}
+
+// helper function to derive a MAC address from an IPv6 address: multicast
+// addresses map to 33:33 and the last 4 bytes of the address, as described at
+// https://tools.ietf.org/html/rfc2464#section-7, addresses with an interface
+// identifier derived from a MAC address map back to it and other addresses
+// get a locally administered address ending with their last 4 bytes
+func macForAddr(addr netip.Addr) [6]byte {
+	a := addr.As16()
+	if addr.IsMulticast() {
+		return [6]byte{0x33, 0x33, a[12], a[13], a[14], a[15]}
+	}
+	if a[11] == 0xff && a[12] == 0xfe {
+		return [6]byte{a[8] ^ 0x02, a[9], a[10], a[13], a[14], a[15]}
+	}
+
+	return [6]byte{0x02, 0, a[12], a[13], a[14], a[15]}
+}
+
+// helper function returning true if port is a DHCPv6 port
+func isDHCPv6Port(port uint16) bool {
+	return port == ClientPort || port == ServerPort
+}
+
+// helper function to report files ending halfway a header or packet as such
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
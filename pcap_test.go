@@ -0,0 +1,346 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+var (
+	fixtPcapClient = netip.MustParseAddrPort("[fe80::a8bb:ccff:fedd:eeff]:546")
+	fixtPcapServer = netip.MustParseAddrPort("[ff02::1:2]:547")
+)
+
+// helper function to wrap payload in Ethernet, IPv6 and UDP headers, with
+// given VLAN tags and IPv6 extension headers. Checksums are left 0, since
+// readers don't verify them
+func testFrame(src, dst netip.AddrPort, payload []byte, vlans []uint16, ext []byte) []byte {
+	b := []byte{0x33, 0x33, 0, 1, 0, 2, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	for _, vlan := range vlans {
+		b = binary.BigEndian.AppendUint16(b, etherTypeVLAN)
+		b = binary.BigEndian.AppendUint16(b, vlan)
+	}
+	b = binary.BigEndian.AppendUint16(b, etherTypeIPv6)
+
+	next := uint8(ipv6NextUDP)
+	if len(ext) > 0 {
+		next = ext[0]
+		ext = ext[1:]
+	}
+	b = append(b, 0x60, 0, 0, 0)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(ext)+udpHeaderLen+len(payload)))
+	b = append(b, next, 64)
+	srcAddr, dstAddr := src.Addr().As16(), dst.Addr().As16()
+	b = append(b, srcAddr[:]...)
+	b = append(b, dstAddr[:]...)
+	b = append(b, ext...)
+
+	b = binary.BigEndian.AppendUint16(b, src.Port())
+	b = binary.BigEndian.AppendUint16(b, dst.Port())
+	b = binary.BigEndian.AppendUint16(b, uint16(udpHeaderLen+len(payload)))
+	b = append(b, 0, 0)
+
+	return append(b, payload...)
+}
+
+// helper function to build a pcap file in given byte order holding frames
+// captured at ts, the first one of them in the first second after ts
+func testPcap(order binary.AppendByteOrder, nano bool, ts time.Time, frames ...[]byte) []byte {
+	magic := pcapMagicMicroseconds
+	if nano {
+		magic = pcapMagicNanoseconds
+	}
+
+	var b []byte
+	b = order.AppendUint32(b, magic)
+	b = order.AppendUint16(b, 2)
+	b = order.AppendUint16(b, 4)
+	b = append(b, make([]byte, 8)...)
+	b = order.AppendUint32(b, 65535)
+	b = order.AppendUint32(b, uint32(pcapLinkTypeEthernet))
+	for i, frame := range frames {
+		t := ts.Add(time.Duration(i) * time.Second)
+		frac := uint32(t.Nanosecond() / 1000)
+		if nano {
+			frac = uint32(t.Nanosecond())
+		}
+		b = order.AppendUint32(b, uint32(t.Unix()))
+		b = order.AppendUint32(b, frac)
+		b = order.AppendUint32(b, uint32(len(frame)))
+		b = order.AppendUint32(b, uint32(len(frame)))
+		b = append(b, frame...)
+	}
+
+	return b
+}
+
+// helper function to append a pcapng block with given type and body to b
+func testPcapngBlock(b []byte, order binary.AppendByteOrder, blockType uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	b = order.AppendUint32(b, blockType)
+	b = order.AppendUint32(b, uint32(len(body)+12))
+	b = append(b, body...)
+	return order.AppendUint32(b, uint32(len(body)+12))
+}
+
+func TestPcapReader(t *testing.T) {
+	msg := &Message{MessageType: MessageTypeSolicit, Xid: 123456}
+	msg.AddOption(&OptionElapsedTime{})
+	fixtmsg, _ := msg.Marshal()
+	fixtts := time.Date(2024, 3, 1, 12, 0, 0, 123456000, time.UTC)
+
+	frames := [][]byte{
+		testFrame(fixtPcapClient, fixtPcapServer, fixtmsg, nil, nil),
+		// not DHCPv6, so should be skipped
+		testFrame(netip.MustParseAddrPort("[2001:db8::1]:53"), netip.MustParseAddrPort("[2001:db8::2]:53"), []byte{1, 2, 3}, nil, nil),
+		// VLAN tagged twice and with a hop-by-hop header
+		testFrame(fixtPcapServer, fixtPcapClient, fixtmsg, []uint16{10, 20}, []byte{ipv6NextHopByHop, ipv6NextUDP, 0, 5, 2, 0, 0, 1, 0}),
+	}
+
+	for _, test := range []struct {
+		order binary.AppendByteOrder
+		nano  bool
+	}{
+		{binary.LittleEndian, false},
+		{binary.BigEndian, true},
+	} {
+		p, err := NewPcapReader(bytes.NewReader(testPcap(test.order, test.nano, fixtts, frames...)))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		for i, fixtpkt := range []Packet{
+			{Timestamp: fixtts, Src: fixtPcapClient, Dst: fixtPcapServer},
+			{Timestamp: fixtts.Add(2 * time.Second), Src: fixtPcapServer, Dst: fixtPcapClient},
+		} {
+			pkt, err := p.ReadPacket()
+			if err != nil {
+				t.Fatalf("unexpected error reading packet %d: %s", i, err)
+			}
+			if !pkt.Timestamp.Equal(fixtpkt.Timestamp) {
+				t.Errorf("expected timestamp %s, got %s", fixtpkt.Timestamp, pkt.Timestamp)
+			}
+			if pkt.Src != fixtpkt.Src || pkt.Dst != fixtpkt.Dst {
+				t.Errorf("expected %s -> %s, got %s -> %s", fixtpkt.Src, fixtpkt.Dst, pkt.Src, pkt.Dst)
+			}
+			if !bytes.Equal(pkt.Data, fixtmsg) {
+				t.Errorf("payload didn't match fixture!\nfixture: %v\npayload: %v", fixtmsg, pkt.Data)
+			}
+			if !msg.Equal(pkt.Message) {
+				t.Errorf("expected message %v, got %v", msg, pkt.Message)
+			}
+		}
+
+		if _, err := p.ReadPacket(); err != io.EOF {
+			t.Errorf("expected EOF, got %v", err)
+		}
+	}
+}
+
+func TestPcapngReader(t *testing.T) {
+	msg := &Message{MessageType: MessageTypeReply, Xid: 123456}
+	msg.AddOption(&OptionRapidCommit{})
+	fixtmsg, _ := msg.Marshal()
+	frame := testFrame(fixtPcapServer, fixtPcapClient, fixtmsg, nil, nil)
+
+	var b []byte
+	for _, order := range []binary.AppendByteOrder{binary.BigEndian, binary.LittleEndian} {
+		// section header, without section length
+		shb := order.AppendUint32(nil, pcapngByteOrderMagic)
+		shb = order.AppendUint16(shb, 1)
+		shb = order.AppendUint16(shb, 0)
+		shb = append(shb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+		b = testPcapngBlock(b, order, pcapngBlockSectionHeader, shb)
+
+		// interface with nanosecond resolution
+		idb := order.AppendUint16(nil, pcapLinkTypeEthernet)
+		idb = append(idb, 0, 0)
+		idb = order.AppendUint32(idb, 65535)
+		idb = order.AppendUint16(idb, pcapngOptionTimestampResolution)
+		idb = order.AppendUint16(idb, 1)
+		idb = append(idb, 9, 0, 0, 0)
+		idb = order.AppendUint32(idb, 0)
+		b = testPcapngBlock(b, order, pcapngBlockInterface, idb)
+
+		// enhanced packet block with a 64 bit timestamp
+		ts := uint64(1709294400123456789)
+		epb := order.AppendUint32(nil, 0)
+		epb = order.AppendUint32(epb, uint32(ts>>32))
+		epb = order.AppendUint32(epb, uint32(ts))
+		epb = order.AppendUint32(epb, uint32(len(frame)))
+		epb = order.AppendUint32(epb, uint32(len(frame)))
+		epb = append(epb, frame...)
+		b = testPcapngBlock(b, order, pcapngBlockEnhancedPacket, epb)
+
+		// simple packet block, without timestamp
+		spb := order.AppendUint32(nil, uint32(len(frame)))
+		spb = append(spb, frame...)
+		b = testPcapngBlock(b, order, pcapngBlockSimplePacket, spb)
+	}
+
+	p, err := NewPcapReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fixtts := time.Unix(1709294400, 123456789)
+	for i := 0; i < 4; i++ {
+		pkt, err := p.ReadPacket()
+		if err != nil {
+			t.Fatalf("unexpected error reading packet %d: %s", i, err)
+		}
+		if i%2 == 0 && !pkt.Timestamp.Equal(fixtts) {
+			t.Errorf("expected timestamp %s, got %s", fixtts, pkt.Timestamp)
+		} else if i%2 == 1 && !pkt.Timestamp.IsZero() {
+			t.Errorf("expected zero timestamp, got %s", pkt.Timestamp)
+		}
+		if pkt.Src != fixtPcapServer || pkt.Dst != fixtPcapClient {
+			t.Errorf("expected %s -> %s, got %s -> %s", fixtPcapServer, fixtPcapClient, pkt.Src, pkt.Dst)
+		}
+		if !msg.Equal(pkt.Message) {
+			t.Errorf("expected message %v, got %v", msg, pkt.Message)
+		}
+	}
+	if _, err := p.ReadPacket(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestPcapReaderErrors(t *testing.T) {
+	// test for error on other files
+	if _, err := NewPcapReader(bytes.NewReader([]byte("not a capture file"))); err != errPcapInvalidMagic {
+		t.Errorf("expected error %s, got %v", errPcapInvalidMagic, err)
+	}
+
+	// test for error on file ending halfway a packet
+	frame := testFrame(fixtPcapClient, fixtPcapServer, []byte{1, 0, 0, 1}, nil, nil)
+	b := testPcap(binary.LittleEndian, false, time.Unix(0, 0), frame)
+	p, err := NewPcapReader(bytes.NewReader(b[:len(b)-1]))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := p.ReadPacket(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected error %s, got %v", io.ErrUnexpectedEOF, err)
+	}
+
+	// test for packet without message on payload that can't be decoded,
+	// after which reading continues
+	b = testPcap(binary.LittleEndian, false, time.Unix(0, 0),
+		testFrame(fixtPcapClient, fixtPcapServer, []byte{1, 0}, nil, nil), frame)
+	p, err = NewPcapReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pkt, err := p.ReadPacket(); err == nil {
+		t.Error("expected error decoding message")
+	} else if pkt == nil || pkt.Message != nil || !bytes.Equal(pkt.Data, []byte{1, 0}) {
+		t.Errorf("expected packet without message, got %v", pkt)
+	}
+	if pkt, err := p.ReadPacket(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if pkt.Message.MessageType != MessageTypeSolicit {
+		t.Errorf("expected message type %s, got %s", MessageTypeSolicit, pkt.Message.MessageType)
+	}
+}
+
+func TestPcapWriter(t *testing.T) {
+	clientID, serverID := testDUIDs()
+	solicit, err := NewSolicit(clientID, &OptionIANA{IAID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	advertise, err := NewAdvertise(solicit, serverID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	fixtts := time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)
+
+	var buf bytes.Buffer
+	w, err := NewPcapWriter(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := w.WriteMessage(solicit, fixtts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := w.WriteMessage(advertise, fixtts.Add(time.Second)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// malformed message as is
+	if err := w.WritePacket(&Packet{Src: fixtPcapClient, Dst: fixtPcapServer, Data: []byte{1, 0}}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// malformed message without addresses is sent between default addresses
+	if err := w.WritePacket(&Packet{Timestamp: fixtts, Data: []byte{7, 0}}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// test for error on IPv4 addresses
+	if err := w.WritePacket(&Packet{Src: netip.MustParseAddrPort("192.0.2.1:546"), Dst: fixtPcapServer}); err != errPcapInvalidAddress {
+		t.Errorf("expected error %s, got %v", errPcapInvalidAddress, err)
+	}
+
+	r, err := NewPcapReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for i, fixtpkt := range []Packet{
+		{Timestamp: fixtts, Src: netip.AddrPortFrom(w.ClientAddr, ClientPort), Dst: fixtPcapServer, Message: solicit},
+		{Timestamp: fixtts.Add(time.Second), Src: netip.AddrPortFrom(w.ServerAddr, ServerPort), Dst: netip.AddrPortFrom(w.ClientAddr, ClientPort), Message: advertise},
+	} {
+		pkt, err := r.ReadPacket()
+		if err != nil {
+			t.Fatalf("unexpected error reading packet %d: %s", i, err)
+		}
+		if !pkt.Timestamp.Equal(fixtpkt.Timestamp) {
+			t.Errorf("expected timestamp %s, got %s", fixtpkt.Timestamp, pkt.Timestamp)
+		}
+		if pkt.Src != fixtpkt.Src || pkt.Dst != fixtpkt.Dst {
+			t.Errorf("expected %s -> %s, got %s -> %s", fixtpkt.Src, fixtpkt.Dst, pkt.Src, pkt.Dst)
+		}
+		if !fixtpkt.Message.Equal(pkt.Message) {
+			t.Errorf("expected message %v, got %v", fixtpkt.Message, pkt.Message)
+		}
+	}
+	if pkt, err := r.ReadPacket(); err == nil {
+		t.Error("expected error decoding message")
+	} else if pkt == nil || !bytes.Equal(pkt.Data, []byte{1, 0}) {
+		t.Errorf("expected packet without message, got %v", pkt)
+	}
+	if pkt, err := r.ReadPacket(); err == nil {
+		t.Error("expected error decoding message")
+	} else if fixtsrc := netip.AddrPortFrom(w.ServerAddr, ServerPort); pkt == nil || pkt.Src != fixtsrc {
+		t.Errorf("expected packet from %s, got %v", fixtsrc, pkt)
+	}
+	if _, err := r.ReadPacket(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestEncodeFrame(t *testing.T) {
+	fixtbyte := []byte{
+		// Ethernet
+		0x33, 0x33, 0, 1, 0, 2, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x86, 0xdd,
+		// IPv6
+		0x60, 0, 0, 0, 0, 12, 17, 64,
+		0xfe, 0x80, 0, 0, 0, 0, 0, 0, 0xa8, 0xbb, 0xcc, 0xff, 0xfe, 0xdd, 0xee, 0xff,
+		0xff, 0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 2,
+		// UDP
+		2, 34, 2, 35, 0, 12, 0xb7, 0x2f,
+		// Solicit
+		1, 1, 226, 64,
+	}
+
+	mshByte, err := encodeFrame(fixtPcapClient, fixtPcapServer, []byte{1, 1, 226, 64})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(fixtbyte, mshByte) {
+		t.Errorf("encoded frame didn't match fixture!\nfixture: %v\nencoded: %v", fixtbyte, mshByte)
+	}
+}
@@ -0,0 +1,168 @@
+package dhcpv6
+
+import (
+	"errors"
+)
+
+var (
+	errNoRapidCommit    = errors.New("solicit does not request rapid commit")
+	errServerIDMismatch = errors.New("message is not meant for this server")
+)
+
+// NewAdvertise returns an Advertise message answering given Solicit message,
+// as described at https://tools.ietf.org/html/rfc8415#section-18.3.1. See
+// NewReply for the contents of the returned Message
+func NewAdvertise(solicit *Message, serverID DUID, options ...Option) (*Message, error) {
+	if solicit == nil || solicit.MessageType != MessageTypeSolicit {
+		return nil, errUnexpectedMessage
+	}
+
+	return newServerMessage(MessageTypeAdvertise, solicit, serverID, options)
+}
+
+// NewReply returns a Reply message answering given client message. The
+// returned Message carries the transaction-id and client identifier of msg,
+// the server identifier, those of given options the client requested in its
+// Option Request option and an empty IA_NA or IA_PD for every IA in msg. The
+// caller adds the assigned addresses and prefixes to these IAs and then calls
+// SetUnassignedIAStatus to tell the client about the IAs left empty.
+//
+// A Solicit message is only answered with a Reply when it carries the Rapid
+// Commit option, as described at
+// https://tools.ietf.org/html/rfc8415#section-18.3.1. Release and Decline
+// messages are answered with a Success status code and Confirm messages with a
+// Success status code the caller might replace by NotOnLink
+func NewReply(msg *Message, serverID DUID, options ...Option) (*Message, error) {
+	if msg == nil {
+		return nil, errUnexpectedMessage
+	}
+
+	switch msg.MessageType {
+	case MessageTypeSolicit:
+		if msg.HasOption(OptionTypeRapidCommit) == nil {
+			return nil, errNoRapidCommit
+		}
+	case MessageTypeRequest, MessageTypeConfirm, MessageTypeRenew,
+		MessageTypeRebind, MessageTypeRelease, MessageTypeDecline,
+		MessageTypeInformationRequest:
+	default:
+		return nil, errUnexpectedMessage
+	}
+
+	m, err := newServerMessage(MessageTypeReply, msg, serverID, options)
+	if err != nil {
+		return nil, err
+	}
+
+	switch msg.MessageType {
+	case MessageTypeSolicit:
+		m.AddOption(&OptionRapidCommit{})
+	case MessageTypeConfirm, MessageTypeRelease, MessageTypeDecline:
+		m.AddOption(&OptionStatusCode{Code: StatusCodeSuccess})
+	}
+
+	return m, nil
+}
+
+// helper function to create a server message of given type for client message
+// msg, checking whether msg is meant for this server at all
+func newServerMessage(t MessageType, msg *Message, serverID DUID, options Options) (*Message, error) {
+	if serverID == nil {
+		return nil, errNoServerID
+	}
+
+	// as described at https://tools.ietf.org/html/rfc8415#section-16 only some
+	// client messages are sent to a specific server
+	msgServerID, ok := msg.HasOption(OptionTypeServerID).(*OptionServerID)
+	switch msg.MessageType {
+	case MessageTypeRequest, MessageTypeRenew, MessageTypeRelease, MessageTypeDecline:
+		if !ok || !EqualDUIDs(msgServerID.DUID, serverID) {
+			return nil, errServerIDMismatch
+		}
+	case MessageTypeInformationRequest:
+		if ok && !EqualDUIDs(msgServerID.DUID, serverID) {
+			return nil, errServerIDMismatch
+		}
+	default:
+		if ok {
+			return nil, errServerIDMismatch
+		}
+	}
+
+	clientID, ok := msg.HasOption(OptionTypeClientID).(*OptionClientID)
+	if !ok && msg.MessageType != MessageTypeInformationRequest {
+		return nil, errNoClientID
+	}
+
+	m := &Message{
+		MessageType: t,
+		Xid:         msg.Xid,
+	}
+	if clientID != nil {
+		m.AddOption(&OptionClientID{DUID: clientID.DUID})
+	}
+	m.AddOption(&OptionServerID{DUID: serverID})
+
+	// echo the IAs of the client, unless it is giving them back or only
+	// checking whether its addresses are still on-link
+	switch msg.MessageType {
+	case MessageTypeConfirm, MessageTypeRelease, MessageTypeDecline, MessageTypeInformationRequest:
+	default:
+		for _, opt := range msg.Options {
+			switch o := opt.(type) {
+			case *OptionIANA:
+				m.AddOption(&OptionIANA{IAID: o.IAID})
+			case *OptionIAPD:
+				m.AddOption(&OptionIAPD{IAID: o.IAID})
+			}
+		}
+	}
+
+	// answer the option request of the client
+	if oro, ok := msg.HasOption(OptionTypeOptionRequest).(*OptionOptionRequest); ok {
+		for _, opt := range options {
+			if oro.HasOption(opt.Type()) {
+				m.AddOption(opt)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// SetUnassignedIAStatus adds a status code to every IA_NA and IA_PD in this
+// Advertise or Reply message that holds no addresses or prefixes, answering a
+// client message of given type. As described at
+// https://tools.ietf.org/html/rfc8415#section-18.3.4 and
+// https://tools.ietf.org/html/rfc8415#section-18.3.5 that is NoBinding for
+// Renew and Rebind messages, otherwise it is NoAddrsAvail for IA_NAs and
+// NoPrefixAvail for IA_PDs. IAs already holding a status code are left as is
+func (m *Message) SetUnassignedIAStatus(clientType MessageType) {
+	for _, opt := range m.Options {
+		var code StatusCode
+		var c *optionContainer
+		switch o := opt.(type) {
+		case *OptionIANA:
+			if o.HasOption(OptionTypeIAAddress) != nil {
+				continue
+			}
+			code, c = StatusCodeNoAddrsAvail, &o.optionContainer
+		case *OptionIAPD:
+			if o.HasOption(OptionTypeIAPrefix) != nil {
+				continue
+			}
+			code, c = StatusCodeNoPrefixAvail, &o.optionContainer
+		default:
+			continue
+		}
+		if c.HasOption(OptionTypeStatusCode) != nil {
+			continue
+		}
+
+		switch clientType {
+		case MessageTypeRenew, MessageTypeRebind:
+			code = StatusCodeNoBinding
+		}
+		c.AddOption(&OptionStatusCode{Code: code})
+	}
+}
@@ -0,0 +1,243 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// helper function returning the DUIDs of the client and server used in tests
+func testDUIDs() (DUID, DUID) {
+	fixtmac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	servermac, _ := net.ParseMAC("00:11:22:33:44:55")
+
+	return &DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: fixtmac},
+		&DUIDLL{HardwareType: HardwareTypeEthernet, LinkLayerAddress: servermac}
+}
+
+var (
+	fixtReplyServerID = []byte{0, 2, 0, 10, 0, 3, 0, 1, 0, 17, 34, 51, 68, 85}
+	fixtReplyDNS      = []byte{0, 23, 0, 16, 32, 1, 13, 184, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 83}
+	fixtReplySuccess  = []byte{0, 13, 0, 2, 0, 0}
+	fixtReplyIANA     = []byte{0, 3, 0, 12, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0}
+	fixtReplyIAPD     = []byte{0, 25, 0, 12, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0}
+)
+
+func TestNewAdvertise(t *testing.T) {
+	clientID, serverID := testDUIDs()
+	dns := &OptionDNSServer{Servers: []net.IP{net.ParseIP("2001:db8::53")}}
+	// not requested by the client, so should be left out
+	rapidCommit := &OptionRapidCommit{}
+
+	solicit, err := NewSolicit(clientID, &OptionIANA{IAID: 1}, &OptionIAPD{IAID: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	msg, err := NewAdvertise(solicit, serverID, dns, rapidCommit)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if msg.Xid != solicit.Xid {
+		t.Errorf("expected transaction-id %d, got %d", solicit.Xid, msg.Xid)
+	}
+	checkBuiltMessage(t, msg, MessageTypeAdvertise, fixtMessage(MessageTypeAdvertise,
+		fixtBuilderClientID, fixtReplyServerID,
+		fixtReplyIANA,
+		fixtReplyIAPD,
+		fixtReplyDNS))
+
+	// test for error on other messages than Solicit
+	request := &Message{MessageType: MessageTypeRequest}
+	if _, err := NewAdvertise(request, serverID); err != errUnexpectedMessage {
+		t.Errorf("expected error %s, got %v", errUnexpectedMessage, err)
+	}
+	if _, err := NewAdvertise(nil, serverID); err != errUnexpectedMessage {
+		t.Errorf("expected error %s, got %v", errUnexpectedMessage, err)
+	}
+
+	// test for error on Solicit with server identifier
+	solicit.AddOption(&OptionServerID{DUID: serverID})
+	if _, err := NewAdvertise(solicit, serverID); err != errServerIDMismatch {
+		t.Errorf("expected error %s, got %v", errServerIDMismatch, err)
+	}
+
+	// test for error on Solicit without client identifier
+	solicit.Options = nil
+	if _, err := NewAdvertise(solicit, serverID); err != errNoClientID {
+		t.Errorf("expected error %s, got %v", errNoClientID, err)
+	}
+}
+
+func TestNewReply(t *testing.T) {
+	clientID, serverID := testDUIDs()
+	dns := &OptionDNSServer{Servers: []net.IP{net.ParseIP("2001:db8::53")}}
+
+	// Solicit only results in Reply with Rapid Commit
+	solicit, err := NewSolicit(clientID, &OptionIANA{IAID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := NewReply(solicit, serverID, dns); err != errNoRapidCommit {
+		t.Errorf("expected error %s, got %v", errNoRapidCommit, err)
+	}
+	solicit.AddOption(&OptionRapidCommit{})
+	if msg, err := NewReply(solicit, serverID, dns); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else {
+		checkBuiltMessage(t, msg, MessageTypeReply, fixtMessage(MessageTypeReply,
+			fixtBuilderClientID, fixtReplyServerID,
+			fixtReplyIANA,
+			fixtReplyDNS, []byte{0, 14, 0, 0}))
+	}
+
+	// test replies to messages derived from an earlier Reply
+	reply, err := NewReply(solicit, serverID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	iana := reply.HasOption(OptionTypeIANA).(*OptionIANA)
+	iana.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1"), ValidLifetime: 900 * time.Second})
+
+	tests := []struct {
+		build    func(*Message) (*Message, error)
+		fixtbyte []byte
+	}{
+		{
+			NewRenew,
+			fixtMessage(MessageTypeReply, fixtBuilderClientID, fixtReplyServerID,
+				fixtReplyIANA, fixtReplyDNS),
+		},
+		{
+			NewRebind,
+			fixtMessage(MessageTypeReply, fixtBuilderClientID, fixtReplyServerID,
+				fixtReplyIANA, fixtReplyDNS),
+		},
+		{
+			NewRelease,
+			fixtMessage(MessageTypeReply, fixtBuilderClientID, fixtReplyServerID,
+				fixtReplySuccess),
+		},
+		{
+			NewConfirm,
+			fixtMessage(MessageTypeReply, fixtBuilderClientID, fixtReplyServerID,
+				fixtReplySuccess),
+		},
+	}
+	for _, test := range tests {
+		msg, err := test.build(reply)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		answer, err := NewReply(msg, serverID, dns)
+		if err != nil {
+			t.Errorf("unexpected error answering %s: %s", msg.MessageType, err)
+			continue
+		}
+		if answer.Xid != msg.Xid {
+			t.Errorf("expected transaction-id %d, got %d", msg.Xid, answer.Xid)
+		}
+		checkBuiltMessage(t, answer, MessageTypeReply, test.fixtbyte)
+	}
+
+	// test for error when message is meant for another server
+	_, otherID := testDUIDs()
+	otherID.(*DUIDLL).LinkLayerAddress = net.HardwareAddr{0, 17, 34, 51, 68, 86}
+	renew, err := NewRenew(reply)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := NewReply(renew, otherID); err != errServerIDMismatch {
+		t.Errorf("expected error %s, got %v", errServerIDMismatch, err)
+	}
+
+	// test for error on server messages
+	if _, err := NewReply(reply, serverID); err != errUnexpectedMessage {
+		t.Errorf("expected error %s, got %v", errUnexpectedMessage, err)
+	}
+	if _, err := NewReply(nil, serverID); err != errUnexpectedMessage {
+		t.Errorf("expected error %s, got %v", errUnexpectedMessage, err)
+	}
+
+	// test for error without server DUID
+	if _, err := NewReply(renew, nil); err != errNoServerID {
+		t.Errorf("expected error %s, got %v", errNoServerID, err)
+	}
+}
+
+func TestSetUnassignedIAStatus(t *testing.T) {
+	tests := []struct {
+		clientType MessageType
+		iana       StatusCode
+		iapd       StatusCode
+	}{
+		{MessageTypeSolicit, StatusCodeNoAddrsAvail, StatusCodeNoPrefixAvail},
+		{MessageTypeRequest, StatusCodeNoAddrsAvail, StatusCodeNoPrefixAvail},
+		{MessageTypeRenew, StatusCodeNoBinding, StatusCodeNoBinding},
+		{MessageTypeRebind, StatusCodeNoBinding, StatusCodeNoBinding},
+	}
+
+	for _, test := range tests {
+		assigned := &OptionIANA{IAID: 1}
+		assigned.AddOption(&OptionIAAddress{Address: net.ParseIP("2001:db8::1")})
+		failed := &OptionIANA{IAID: 4}
+		failed.AddOption(&OptionStatusCode{Code: StatusCodeUnspecFail})
+		msg := &Message{MessageType: MessageTypeReply}
+		for _, opt := range []Option{assigned, &OptionIANA{IAID: 2}, &OptionIAPD{IAID: 3}, failed} {
+			msg.AddOption(opt)
+		}
+		msg.SetUnassignedIAStatus(test.clientType)
+
+		for i, opt := range msg.Options {
+			var status Option
+			switch o := opt.(type) {
+			case *OptionIANA:
+				status = o.HasOption(OptionTypeStatusCode)
+			case *OptionIAPD:
+				status = o.HasOption(OptionTypeStatusCode)
+			}
+
+			var fixtcode StatusCode
+			switch i {
+			case 0:
+				if status != nil {
+					t.Errorf("expected no status code in IA with address answering %s, got %s", test.clientType, status)
+				}
+				continue
+			case 1:
+				fixtcode = test.iana
+			case 2:
+				fixtcode = test.iapd
+			case 3:
+				fixtcode = StatusCodeUnspecFail
+			}
+			if code, ok := status.(*OptionStatusCode); !ok || code.Code != fixtcode {
+				t.Errorf("expected status code %s in %s answering %s, got %v", fixtcode, opt, test.clientType, status)
+			}
+		}
+	}
+}
+
+func TestNewReplyInformationRequest(t *testing.T) {
+	_, serverID := testDUIDs()
+	dns := &OptionDNSServer{Servers: []net.IP{net.ParseIP("2001:db8::53")}}
+
+	// client identifier is optional in Information-request
+	inforeq, err := NewInformationRequest(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	msg, err := NewReply(inforeq, serverID, dns)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkBuiltMessage(t, msg, MessageTypeReply, fixtMessage(MessageTypeReply,
+		fixtReplyServerID, fixtReplyDNS))
+
+	// but when it is addressed to a server, it should be this one
+	_, otherID := testDUIDs()
+	otherID.(*DUIDLL).LinkLayerAddress = net.HardwareAddr{0, 17, 34, 51, 68, 86}
+	inforeq.AddOption(&OptionServerID{DUID: otherID})
+	if _, err := NewReply(inforeq, serverID, dns); err != errServerIDMismatch {
+		t.Errorf("expected error %s, got %v", errServerIDMismatch, err)
+	}
+}
@@ -0,0 +1,72 @@
+package dhcpv6
+
+import (
+	"net/netip"
+)
+
+// Route describes a single route as conveyed by the Next Hop and Route Prefix
+// options. A Route without NextHop describes an on-link prefix
+type Route struct {
+	Prefix     netip.Prefix
+	NextHop    netip.Addr
+	Lifetime   uint32
+	Preference RoutePreference
+}
+
+// OnLink returns true if this Route has no next hop, meaning the prefix is
+// directly reachable on the link
+func (r Route) OnLink() bool {
+	return !r.NextHop.IsValid()
+}
+
+// Routes flattens the tree of OptionNextHop and OptionRoutePrefix options in
+// given Options into a routing table. Route Prefix options at the top level
+// are considered on-link, Route Prefix options nested in a Next Hop option
+// are routed via that next hop. A Next Hop option without any Route Prefix
+// options does not result in a route
+func Routes(opts Options) ([]Route, error) {
+	routes := []Route{}
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case *OptionRoutePrefix:
+			r, err := routeFromPrefix(o, netip.Addr{})
+			if err != nil {
+				return nil, err
+			}
+			routes = append(routes, r)
+		case *OptionNextHop:
+			nh, err := o.Addr()
+			if err != nil {
+				return nil, err
+			}
+			for _, nopt := range o.options {
+				rp, ok := nopt.(*OptionRoutePrefix)
+				if !ok {
+					continue
+				}
+				r, err := routeFromPrefix(rp, nh)
+				if err != nil {
+					return nil, err
+				}
+				routes = append(routes, r)
+			}
+		}
+	}
+
+	return routes, nil
+}
+
+// helper function to convert an OptionRoutePrefix to a Route via next hop nh
+func routeFromPrefix(o *OptionRoutePrefix, nh netip.Addr) (Route, error) {
+	prefix, err := o.NetPrefix()
+	if err != nil {
+		return Route{}, err
+	}
+
+	return Route{
+		Prefix:     prefix.Masked(),
+		NextHop:    nh,
+		Lifetime:   o.RouteLifetime,
+		Preference: o.Preference,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package dhcpv6
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestRoutes(t *testing.T) {
+	onlink, err := NewOptionRoutePrefix(netip.MustParsePrefix("fdd4:4732:15d9:ea6a::/64"), 3600, RoutePreferenceMedium)
+	if err != nil {
+		t.Fatalf("could not create route prefix: %s", err)
+	}
+
+	nh, err := NewOptionNextHop(netip.MustParseAddr("fe80::1"))
+	if err != nil {
+		t.Fatalf("could not create next hop: %s", err)
+	}
+	def, _ := NewOptionRoutePrefix(netip.MustParsePrefix("::/0"), 1800, RoutePreferenceHigh)
+	nh.AddOption(def)
+	nh.AddOption(&OptionStatusCode{Code: StatusCodeSuccess})
+	other, _ := NewOptionRoutePrefix(netip.MustParsePrefix("2001:db8::/32"), 600, RoutePreferenceLow)
+	nh.AddOption(other)
+
+	// next hop without prefixes should not result in a route
+	empty, _ := NewOptionNextHop(netip.MustParseAddr("fe80::2"))
+
+	routes, err := Routes(Options{onlink, &OptionRapidCommit{}, nh, empty})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fixtroutes := []Route{
+		{Prefix: netip.MustParsePrefix("fdd4:4732:15d9:ea6a::/64"), Lifetime: 3600},
+		{Prefix: netip.MustParsePrefix("::/0"), NextHop: netip.MustParseAddr("fe80::1"), Lifetime: 1800, Preference: RoutePreferenceHigh},
+		{Prefix: netip.MustParsePrefix("2001:db8::/32"), NextHop: netip.MustParseAddr("fe80::1"), Lifetime: 600, Preference: RoutePreferenceLow},
+	}
+	if len(routes) != len(fixtroutes) {
+		t.Fatalf("expected %d routes, got %d", len(fixtroutes), len(routes))
+	}
+	for i, r := range routes {
+		if r != fixtroutes[i] {
+			t.Errorf("expected route %v, got %v", fixtroutes[i], r)
+		}
+	}
+
+	if !routes[0].OnLink() {
+		t.Error("expected first route to be on-link")
+	}
+	if routes[1].OnLink() {
+		t.Error("expected second route not to be on-link")
+	}
+
+	// routes with an invalid prefix should result in an error
+	if _, err := Routes(Options{&OptionRoutePrefix{Prefix: []byte{10, 0, 0, 0}}}); err == nil {
+		t.Error("expected error for invalid prefix")
+	} else if err != errInvalidIPv6Address {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
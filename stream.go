@@ -0,0 +1,77 @@
+package dhcpv6
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errMessageTooLong = errors.New("message too long")
+
+// StreamReader reads DHCPv6 messages from a stream oriented connection, such
+// as the TCP connections used for Bulk Leasequery. As described at
+// https://tools.ietf.org/html/rfc5460#section-5.1 every message on the stream
+// is preceded by a 2 byte message size
+type StreamReader struct {
+	r io.Reader
+}
+
+// NewStreamReader returns a StreamReader reading from r
+func NewStreamReader(r io.Reader) *StreamReader {
+	return &StreamReader{r: r}
+}
+
+// ReadMessage reads and decodes the next message from the stream. It returns
+// io.EOF when the stream ends cleanly in between messages and
+// io.ErrUnexpectedEOF when it ends halfway a message
+func (s *StreamReader) ReadMessage() (*Message, error) {
+	// read message size
+	h := make([]byte, 2)
+	if _, err := io.ReadFull(s.r, h); err != nil {
+		return nil, err
+	}
+
+	// read message itself
+	b := make([]byte, binary.BigEndian.Uint16(h))
+	if _, err := io.ReadFull(s.r, b); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return DecodeMessage(b)
+}
+
+// StreamWriter writes DHCPv6 messages to a stream oriented connection, each
+// message preceded by a 2 byte message size
+type StreamWriter struct {
+	w io.Writer
+}
+
+// NewStreamWriter returns a StreamWriter writing to w
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{w: w}
+}
+
+// WriteMessage marshals given Message and writes it to the stream
+func (s *StreamWriter) WriteMessage(m *Message) error {
+	mb, err := m.Marshal()
+	if err != nil {
+		return fmt.Errorf("could not marshal message: %s", err)
+	}
+
+	// the message size is limited to what fits in 2 bytes
+	if len(mb) > 0xffff {
+		return errMessageTooLong
+	}
+
+	// prepend message size and write message in one go
+	b := make([]byte, 2, 2+len(mb))
+	binary.BigEndian.PutUint16(b[0:2], uint16(len(mb)))
+	b = append(b, mb...)
+	_, err = s.w.Write(b)
+
+	return err
+}
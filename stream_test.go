@@ -0,0 +1,101 @@
+package dhcpv6
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("could not listen on loopback: %s", err)
+	}
+	defer ln.Close()
+
+	// messages as a server would send them in response to a bulk leasequery
+	fixtmsgs := []*Message{
+		{MessageType: MessageTypeLeasequeryReply, Xid: 123456},
+		{MessageType: MessageTypeLeasequeryData, Xid: 123456},
+		{MessageType: MessageTypeLeasequeryDone, Xid: 123456},
+	}
+	fixtmsgs[0].AddOption(&OptionClientData{})
+	fixtmsgs[1].AddOption(&OptionClientData{})
+	fixtmsgs[2].AddOption(&OptionStatusCode{Code: StatusCodeSuccess, Message: "done"})
+
+	errc := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			errc <- err
+			return
+		}
+		defer conn.Close()
+
+		w := NewStreamWriter(conn)
+		for _, msg := range fixtmsgs {
+			if err := w.WriteMessage(msg); err != nil {
+				errc <- err
+				return
+			}
+		}
+		errc <- nil
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("could not connect: %s", err)
+	}
+	defer conn.Close()
+
+	r := NewStreamReader(conn)
+	for _, fixtmsg := range fixtmsgs {
+		msg, err := r.ReadMessage()
+		if err != nil {
+			t.Fatalf("could not read message: %s", err)
+		}
+
+		fixtbyte, _ := fixtmsg.Marshal()
+		if mshByte, err := msg.Marshal(); err != nil {
+			t.Errorf("error marshalling message: %s", err)
+		} else if !bytes.Equal(fixtbyte, mshByte) {
+			t.Errorf("read message didn't match fixture!\nfixture: %v\nmarshal: %v", fixtbyte, mshByte)
+		}
+	}
+
+	if err := <-errc; err != nil {
+		t.Fatalf("error writing messages: %s", err)
+	}
+
+	// the stream should now end cleanly
+	if _, err := r.ReadMessage(); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestStreamReaderTruncated(t *testing.T) {
+	// message size says 8 bytes, but only 4 follow
+	r := NewStreamReader(bytes.NewReader([]byte{0, 8, 17, 1, 226, 64}))
+	if _, err := r.ReadMessage(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected unexpected EOF, got %v", err)
+	}
+
+	// message size itself is truncated
+	r = NewStreamReader(bytes.NewReader([]byte{0}))
+	if _, err := r.ReadMessage(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected unexpected EOF, got %v", err)
+	}
+}
+
+func TestStreamWriterTooLong(t *testing.T) {
+	msg := &Message{MessageType: MessageTypeLeasequeryData}
+	for i := 0; i < 64; i++ {
+		msg.AddOption(&OptionBootFileURL{URL: string(make([]byte, 1024))})
+	}
+
+	w := NewStreamWriter(io.Discard)
+	if err := w.WriteMessage(msg); err != errMessageTooLong {
+		t.Errorf("expected message too long error, got %v", err)
+	}
+}